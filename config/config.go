@@ -1,13 +1,34 @@
 package config
 
 import (
+	"context"
+	"net/http"
 	"time"
 
+	"github.com/shuaidd/wecom-core/pkg/breaker"
 	"github.com/shuaidd/wecom-core/pkg/cache"
 	"github.com/shuaidd/wecom-core/pkg/interceptor"
 	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/ratelimit"
+	"github.com/shuaidd/wecom-core/pkg/redact"
+	"github.com/shuaidd/wecom-core/pkg/retry"
 )
 
+// TokenFetcher 自定义 access_token 获取函数，返回 token 及其有效期（秒）
+// agentKey 为 context 中指定的应用名称或ID，未指定时为空
+type TokenFetcher func(ctx context.Context, agentKey string) (token string, expiresIn int, err error)
+
+// TokenSource 自定义 access_token 来源，用于接入独立部署的 token 中控服务等场景
+// 与 TokenFetcher 不同，SDK 不会缓存 TokenSource 返回的 token，缓存与刷新由实现方负责
+type TokenSource interface {
+	// GetTokenByAgent 获取应用的 access_token，agentKey 为 context 中指定的应用名称或ID，未指定时为空
+	GetTokenByAgent(ctx context.Context, agentKey string) (string, error)
+	// RefreshTokenIfStale 接口返回 token 失效（40014/42001）时调用，stale 为请求时使用的 token
+	// 实现方应在 stale 仍为当前 token 时刷新，已被刷新时直接返回
+	RefreshTokenIfStale(ctx context.Context, agentKey, stale string) error
+}
+
 // AgentConfig 应用配置
 type AgentConfig struct {
 	// AgentID 应用ID
@@ -21,8 +42,17 @@ type AgentConfig struct {
 
 	// AgentDesc 应用描述(可选)
 	AgentDesc string
+
+	// Token 回调配置的 Token(可选，用于接收消息与事件)
+	Token string
+
+	// EncodingAESKey 回调配置的 EncodingAESKey(可选，43位)
+	EncodingAESKey string
 }
 
+// EncodingAESKeyLength 回调 EncodingAESKey 的固定长度
+const EncodingAESKeyLength = 43
+
 // Config 企业微信SDK配置
 type Config struct {
 	// CorpID 企业ID
@@ -35,6 +65,12 @@ type Config struct {
 	// Agents 多应用配置，key为应用名称或ID
 	Agents map[string]*AgentConfig
 
+	// Token 回调配置的 Token（单应用模式或未单独配置回调的应用使用）
+	Token string
+
+	// EncodingAESKey 回调配置的 EncodingAESKey（单应用模式或未单独配置回调的应用使用）
+	EncodingAESKey string
+
 	// BaseURL API基础URL，默认为 https://qyapi.weixin.qq.com
 	BaseURL string
 
@@ -56,6 +92,43 @@ type Config struct {
 	// Cache Token缓存，默认为内存缓存
 	Cache cache.Cache
 
+	// Locker 跨进程刷新锁（可选），默认使用 Cache（如果 Cache 实现了 cache.Locker）
+	Locker cache.Locker
+
+	// BackgroundRefresh 是否启用后台 token 刷新，默认关闭（请求时按需刷新）
+	BackgroundRefresh bool
+
+	// RefreshInterval 后台刷新的检查间隔，默认为 1 分钟
+	RefreshInterval time.Duration
+
+	// RefreshAhead 后台刷新的提前时间，默认为 5 分钟
+	RefreshAhead time.Duration
+
+	// RateLimiter 客户端限流器（可选），默认不限流
+	RateLimiter *ratelimit.Limiter
+
+	// Transport HTTP 传输层（可选），默认为 http.DefaultTransport
+	// 多个客户端共享同一 Transport 时复用连接池
+	Transport http.RoundTripper
+
+	// RetryPolicy 自定义重试策略（可选），设置后 MaxRetries、InitialBackoff、MaxBackoff 不再生效
+	RetryPolicy retry.Policy
+
+	// CircuitBreaker 熔断器（可选），默认不熔断
+	CircuitBreaker *breaker.Breaker
+
+	// Observer 可观测性钩子（可选），用于接入指标与链路追踪
+	Observer observer.Observer
+
+	// Redactor debug 日志中请求与响应体的脱敏器（可选），默认脱敏凭证与手机号、邮箱等个人信息
+	Redactor *redact.Redactor
+
+	// TokenFetcher 自定义 access_token 获取函数（可选），设置后不需要配置 CorpSecret
+	TokenFetcher TokenFetcher
+
+	// TokenSource 自定义 access_token 来源（可选），设置后不需要配置 CorpSecret，也不会启动后台刷新
+	TokenSource TokenSource
+
 	// RequestInterceptors 请求拦截器列表
 	RequestInterceptors []interceptor.RequestInterceptor
 
@@ -97,19 +170,26 @@ func (c *Config) Validate() error {
 	}
 
 	// 检查是否配置了应用凭证（支持单应用和多应用两种模式）
-	if c.CorpSecret == "" && len(c.Agents) == 0 {
+	if c.CorpSecret == "" && len(c.Agents) == 0 && !c.hasCustomToken() {
 		return ErrMissingCorpSecret
 	}
 
 	// 如果配置了多个应用，验证每个应用的配置
 	if len(c.Agents) > 0 {
 		for key, agent := range c.Agents {
-			if agent.Secret == "" {
+			if agent.Secret == "" && !c.hasCustomToken() {
 				return &ErrInvalidAgentConfig{AgentKey: key, Reason: "secret is required"}
 			}
+			if agent.EncodingAESKey != "" && len(agent.EncodingAESKey) != EncodingAESKeyLength {
+				return &ErrInvalidAgentConfig{AgentKey: key, Reason: "encodingAESKey must be 43 characters"}
+			}
 		}
 	}
 
+	if c.EncodingAESKey != "" && len(c.EncodingAESKey) != EncodingAESKeyLength {
+		return ErrInvalidEncodingAESKey
+	}
+
 	if c.Timeout <= 0 {
 		return ErrInvalidTimeout
 	}
@@ -159,3 +239,19 @@ func (c *Config) GetDefaultAgent() *AgentConfig {
 
 	return nil
 }
+
+// GetCallbackConfig 获取应用的回调 Token 与 EncodingAESKey
+// agentKey 为空或应用未单独配置时，使用全局的 Token 与 EncodingAESKey
+func (c *Config) GetCallbackConfig(agentKey string) (token, encodingAESKey string) {
+	if agentKey != "" {
+		if agent := c.GetAgentByName(agentKey); agent != nil && agent.EncodingAESKey != "" {
+			return agent.Token, agent.EncodingAESKey
+		}
+	}
+	return c.Token, c.EncodingAESKey
+}
+
+// hasCustomToken 是否配置了自定义的 access_token 获取方式
+func (c *Config) hasCustomToken() bool {
+	return c.TokenFetcher != nil || c.TokenSource != nil
+}
@@ -1,11 +1,19 @@
 package config
 
 import (
+	"context"
+	"net/http"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/pkg/breaker"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/ratelimit"
+	"github.com/shuaidd/wecom-core/pkg/redact"
+	"github.com/shuaidd/wecom-core/pkg/retry"
 )
 
 func TestNew(t *testing.T) {
@@ -73,6 +81,28 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: ErrMissingCorpSecret,
 		},
+		{
+			name: "token fetcher without corp secret",
+			cfg: &Config{
+				CorpID:     "test_corp_id",
+				Timeout:    30 * time.Second,
+				MaxRetries: 3,
+				TokenFetcher: func(ctx context.Context, agentKey string) (string, int, error) {
+					return "token", 7200, nil
+				},
+			},
+			wantErr: nil,
+		},
+		{
+			name: "token source without corp secret",
+			cfg: &Config{
+				CorpID:      "test_corp_id",
+				Timeout:     30 * time.Second,
+				MaxRetries:  3,
+				TokenSource: &stubTokenSource{},
+			},
+			wantErr: nil,
+		},
 		{
 			name: "invalid timeout",
 			cfg: &Config{
@@ -93,6 +123,17 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: ErrInvalidMaxRetries,
 		},
+		{
+			name: "invalid encoding aes key",
+			cfg: &Config{
+				CorpID:         "test_corp_id",
+				CorpSecret:     "test_secret",
+				EncodingAESKey: "too_short",
+				Timeout:        30 * time.Second,
+				MaxRetries:     3,
+			},
+			wantErr: ErrInvalidEncodingAESKey,
+		},
 	}
 
 	for _, tt := range tests {
@@ -128,6 +169,15 @@ func TestWithTimeout(t *testing.T) {
 	assert.Equal(t, 60*time.Second, cfg.Timeout)
 }
 
+func TestWithTransport(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.Transport)
+
+	transport := &http.Transport{MaxIdleConnsPerHost: 50}
+	cfg = New(WithTransport(transport))
+	assert.Same(t, transport, cfg.Transport)
+}
+
 func TestWithRetry(t *testing.T) {
 	cfg := New(WithRetry(5))
 	assert.Equal(t, 5, cfg.MaxRetries)
@@ -138,3 +188,107 @@ func TestWithBackoff(t *testing.T) {
 	assert.Equal(t, 2*time.Second, cfg.InitialBackoff)
 	assert.Equal(t, 60*time.Second, cfg.MaxBackoff)
 }
+
+func TestWithLocker(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.Locker)
+
+	locker := &testLocker{}
+	cfg = New(WithLocker(locker))
+	assert.Equal(t, locker, cfg.Locker)
+}
+
+type testLocker struct{}
+
+func (l *testLocker) TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
+func (l *testLocker) Unlock(ctx context.Context, key, owner string) error {
+	return nil
+}
+
+func TestWithBackgroundRefresh(t *testing.T) {
+	cfg := New()
+	assert.False(t, cfg.BackgroundRefresh)
+
+	cfg = New(WithBackgroundRefresh(2*time.Minute, 10*time.Minute))
+	assert.True(t, cfg.BackgroundRefresh)
+	assert.Equal(t, 2*time.Minute, cfg.RefreshInterval)
+	assert.Equal(t, 10*time.Minute, cfg.RefreshAhead)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	limiter := ratelimit.New()
+	cfg := New(WithRateLimit(limiter))
+	assert.Same(t, limiter, cfg.RateLimiter)
+}
+
+func TestWithCircuitBreaker(t *testing.T) {
+	b := breaker.New()
+	cfg := New(WithCircuitBreaker(b))
+	assert.Same(t, b, cfg.CircuitBreaker)
+}
+
+func TestWithObserver(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.Observer)
+
+	o := observer.Multi()
+	cfg = New(WithObserver(o))
+	assert.Equal(t, o, cfg.Observer)
+}
+
+func TestWithRetryPolicy(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.RetryPolicy)
+
+	p := retry.NewExponential(1, time.Second, time.Second)
+	cfg = New(WithRetryPolicy(p))
+	assert.Equal(t, p, cfg.RetryPolicy)
+}
+
+func TestWithRedactor(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.Redactor)
+
+	r := redact.New(redact.WithUserID())
+	cfg = New(WithRedactor(r))
+	assert.Equal(t, r, cfg.Redactor)
+}
+
+// stubTokenSource 测试用的 TokenSource
+type stubTokenSource struct{}
+
+func (stubTokenSource) GetTokenByAgent(ctx context.Context, agentKey string) (string, error) {
+	return "token-" + agentKey, nil
+}
+
+func (stubTokenSource) RefreshTokenIfStale(ctx context.Context, agentKey, stale string) error {
+	return nil
+}
+
+func TestWithTokenSource(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.TokenSource)
+
+	cfg = New(WithTokenSource(stubTokenSource{}))
+	require.NotNil(t, cfg.TokenSource)
+	token, err := cfg.TokenSource.GetTokenByAgent(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "token-app", token)
+}
+
+func TestWithTokenFetcher(t *testing.T) {
+	cfg := New()
+	assert.Nil(t, cfg.TokenFetcher)
+
+	cfg = New(WithTokenFetcher(func(ctx context.Context, agentKey string) (string, int, error) {
+		return "token-" + agentKey, 7200, nil
+	}))
+	require.NotNil(t, cfg.TokenFetcher)
+	token, expiresIn, err := cfg.TokenFetcher(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, "token-app", token)
+	assert.Equal(t, 7200, expiresIn)
+}
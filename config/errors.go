@@ -17,6 +17,21 @@ var (
 
 	// ErrInvalidMaxRetries 无效的重试次数
 	ErrInvalidMaxRetries = errors.New("maxRetries must be greater than or equal to 0")
+
+	// ErrInvalidEncodingAESKey 无效的回调 EncodingAESKey
+	ErrInvalidEncodingAESKey = errors.New("encodingAESKey must be 43 characters")
+
+	// ErrMissingWebhookKey 缺少群机器人 webhook key
+	ErrMissingWebhookKey = errors.New("webhook key is required")
+
+	// ErrCorpNotFound 连接池中没有该企业的配置
+	ErrCorpNotFound = errors.New("corp is not configured")
+
+	// ErrPoolClosed 连接池已关闭
+	ErrPoolClosed = errors.New("client pool is closed")
+
+	// ErrClientClosed 客户端已关闭，不能再创建下级/下游企业客户端
+	ErrClientClosed = errors.New("client is closed")
 )
 
 // ErrInvalidAgentConfig 无效的应用配置
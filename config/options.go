@@ -2,11 +2,17 @@ package config
 
 import (
 	"fmt"
+	"net/http"
 	"time"
 
+	"github.com/shuaidd/wecom-core/pkg/breaker"
 	"github.com/shuaidd/wecom-core/pkg/cache"
 	"github.com/shuaidd/wecom-core/pkg/interceptor"
 	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/ratelimit"
+	"github.com/shuaidd/wecom-core/pkg/redact"
+	"github.com/shuaidd/wecom-core/pkg/retry"
 )
 
 // Option 配置选项函数
@@ -64,6 +70,25 @@ func WithAgents(agents ...*AgentConfig) Option {
 	}
 }
 
+// WithCallback 设置回调的 Token 与 EncodingAESKey（单应用模式或默认回调配置）
+func WithCallback(token, encodingAESKey string) Option {
+	return func(c *Config) {
+		c.Token = token
+		c.EncodingAESKey = encodingAESKey
+	}
+}
+
+// WithAgentCallback 设置指定应用的回调 Token 与 EncodingAESKey
+// 需在 WithAgent 或 WithAgents 之后使用，agentKey 为应用名称或ID
+func WithAgentCallback(agentKey, token, encodingAESKey string) Option {
+	return func(c *Config) {
+		if agent := c.GetAgentByName(agentKey); agent != nil {
+			agent.Token = token
+			agent.EncodingAESKey = encodingAESKey
+		}
+	}
+}
+
 // WithBaseURL 设置API基础URL
 func WithBaseURL(baseURL string) Option {
 	return func(c *Config) {
@@ -78,6 +103,14 @@ func WithTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithTransport 设置 HTTP 传输层，API 请求与 gettoken 请求共用
+// 可用于调整连接池大小、设置代理，或让多个客户端共享连接
+func WithTransport(transport http.RoundTripper) Option {
+	return func(c *Config) {
+		c.Transport = transport
+	}
+}
+
 // WithRetry 设置最大重试次数
 func WithRetry(maxRetries int) Option {
 	return func(c *Config) {
@@ -85,6 +118,26 @@ func WithRetry(maxRetries int) Option {
 	}
 }
 
+// WithRetryPolicy 设置自定义重试策略
+// 默认策略为 retry.Exponential：按错误分类判断是否重试（请求已发出的网络错误只重试幂等请求），使用带 full jitter 的指数退避
+//
+// 示例：
+//
+//	// 频率限制时不重试，其余沿用默认策略
+//	base := retry.NewExponential(3, time.Second, 30*time.Second)
+//	base.Classify = func(err error) retry.Class {
+//	    if c := retry.Classify(err); c != retry.ClassRateLimited {
+//	        return c
+//	    }
+//	    return retry.ClassPermanent
+//	}
+//	config.WithRetryPolicy(base)
+func WithRetryPolicy(p retry.Policy) Option {
+	return func(c *Config) {
+		c.RetryPolicy = p
+	}
+}
+
 // WithBackoff 设置退避时间
 func WithBackoff(initial, max time.Duration) Option {
 	return func(c *Config) {
@@ -107,6 +160,100 @@ func WithCache(cache cache.Cache) Option {
 	}
 }
 
+// WithLocker 设置跨进程刷新锁
+// 多实例共享缓存时使用，保证同一时刻只有一个实例刷新 token
+func WithLocker(locker cache.Locker) Option {
+	return func(c *Config) {
+		c.Locker = locker
+	}
+}
+
+// WithBackgroundRefresh 启用后台 token 刷新
+// 按 interval 检查所有应用的 token，剩余有效期不足 ahead（附加随机抖动）时主动刷新，
+// 需要调用 Client.Close 停止；interval、ahead 为 0 时使用默认值
+func WithBackgroundRefresh(interval, ahead time.Duration) Option {
+	return func(c *Config) {
+		c.BackgroundRefresh = true
+		c.RefreshInterval = interval
+		c.RefreshAhead = ahead
+	}
+}
+
+// WithRateLimit 设置客户端限流器
+// 同一个 Limiter 可在多个客户端之间共享，使批量任务共用同一份配额
+//
+// 示例：
+//
+//	config.WithRateLimit(ratelimit.New(
+//	    ratelimit.WithMode(ratelimit.ModeBlock),
+//	    ratelimit.WithRules(ratelimit.Rule{Name: "send", PerAgent: true, Paths: []string{"/cgi-bin/message/send"}, Limit: 600, Window: time.Minute}),
+//	))
+func WithRateLimit(limiter *ratelimit.Limiter) Option {
+	return func(c *Config) {
+		c.RateLimiter = limiter
+	}
+}
+
+// WithCircuitBreaker 设置熔断器
+// 某个接口连续返回系统繁忙（10001）或超时达到阈值后熔断，熔断期间的请求立即返回 ErrCircuitOpen，不再发送和重试
+//
+// 示例：
+//
+//	config.WithCircuitBreaker(breaker.New(
+//	    breaker.WithFailureThreshold(5),
+//	    breaker.WithOpenTimeout(30*time.Second),
+//	))
+func WithCircuitBreaker(b *breaker.Breaker) Option {
+	return func(c *Config) {
+		c.CircuitBreaker = b
+	}
+}
+
+// WithObserver 设置可观测性钩子
+// 每次请求尝试（包括重试）、token 获取、token 缓存读取与熔断器状态变化时调用，多个 Observer 可通过 observer.Multi 组合
+//
+// 示例：
+//
+//	config.WithObserver(observer.Multi(
+//	    observer.NewMetrics(promRecorder),
+//	    observer.NewTracing(otelTracer),
+//	))
+func WithObserver(o observer.Observer) Option {
+	return func(c *Config) {
+		c.Observer = o
+	}
+}
+
+// WithRedactor 设置 debug 日志中请求与响应体的脱敏器
+// URL 与错误中的 access_token、corpsecret 等凭证总是会被脱敏，不受此选项影响
+//
+// 示例：
+//
+//	// 额外脱敏 userid 等成员标识
+//	config.WithRedactor(redact.New(redact.WithUserID()))
+func WithRedactor(r *redact.Redactor) Option {
+	return func(c *Config) {
+		c.Redactor = r
+	}
+}
+
+// WithTokenFetcher 设置自定义 access_token 获取函数
+// 用于第三方应用（get_corp_token）、上下游（corpgroup/corp/gettoken）等不使用 corpid+secret 的场景，
+// 获取到的 token 同样会被缓存并在失效时自动刷新
+func WithTokenFetcher(fetcher TokenFetcher) Option {
+	return func(c *Config) {
+		c.TokenFetcher = fetcher
+	}
+}
+
+// WithTokenSource 设置自定义 access_token 来源
+// 用于从独立部署的 token 中控服务获取 token，设置后请求不再调用 gettoken，token 的缓存与刷新由 TokenSource 负责
+func WithTokenSource(source TokenSource) Option {
+	return func(c *Config) {
+		c.TokenSource = source
+	}
+}
+
 // WithDebug 设置debug模式
 func WithDebug(debug bool) Option {
 	return func(c *Config) {
@@ -0,0 +1,143 @@
+package wecom
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/internal/auth"
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/types/corpgroup"
+)
+
+// corpKey 下级/下游企业客户端的缓存键
+type corpKey struct {
+	corpID       string
+	agentID      int64
+	businessType int
+	agentKey     string
+}
+
+// ForCorp 返回以下级企业身份调用接口的客户端（企业互联/局校互联）
+// agentID 为下级企业中共享应用的 agentid，ctx 中通过 WithAgentName/WithAgentID 指定的上级应用用于获取下级企业的 access_token
+// 返回的客户端与上级客户端共享日志、缓存、重试、限流与拦截器配置，
+// access_token 通过 corpgroup/corp/gettoken 获取并自动缓存、失效时刷新
+// 同一企业与应用多次调用返回同一个客户端，上级客户端关闭后返回 config.ErrClientClosed
+//
+// 示例：
+//
+//	sub, err := client.ForCorp(ctx, "wwsubcorpid", 1000005)
+//	user, err := sub.Contact.GetUser(ctx, "zhangsan")
+func (c *Client) ForCorp(ctx context.Context, corpID string, agentID int64) (*Client, error) {
+	return c.forCorp(ctx, corpID, agentID, corpgroup.BusinessTypeCorpGroup)
+}
+
+// ForChainCorp 返回以下游企业身份调用接口的客户端（上下游企业）
+// 参数与行为同 ForCorp
+func (c *Client) ForChainCorp(ctx context.Context, corpID string, agentID int64) (*Client, error) {
+	return c.forCorp(ctx, corpID, agentID, corpgroup.BusinessTypeChain)
+}
+
+// forCorp 创建或返回已缓存的下级/下游企业客户端
+func (c *Client) forCorp(ctx context.Context, corpID string, agentID int64, businessType int) (*Client, error) {
+	if corpID == "" {
+		return nil, config.ErrMissingCorpID
+	}
+
+	key := corpKey{
+		corpID:       corpID,
+		agentID:      agentID,
+		businessType: businessType,
+		agentKey:     client.AgentKeyFromContext(ctx),
+	}
+
+	c.corpsMu.Lock()
+	if c.corpsClosed {
+		c.corpsMu.Unlock()
+		return nil, config.ErrClientClosed
+	}
+	if sub, ok := c.corps[key]; ok {
+		c.corpsMu.Unlock()
+		return sub, nil
+	}
+	c.corpsMu.Unlock()
+
+	// 在锁外获取 token 并创建客户端，避免阻塞其他企业
+	source := c.newCorpTokenSource(key)
+
+	// 预先获取一次 token，未授权等错误在此返回
+	if _, err := source.GetTokenByAgent(ctx, ""); err != nil {
+		return nil, err
+	}
+
+	parent := c.config
+	sub, err := New(func(cfg *config.Config) {
+		*cfg = *parent
+		cfg.CorpID = corpID
+		cfg.CorpSecret = ""
+		cfg.Agents = nil
+		cfg.Token = ""
+		cfg.EncodingAESKey = ""
+		cfg.TokenFetcher = nil
+		cfg.TokenSource = source
+		cfg.BackgroundRefresh = false
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.corpsMu.Lock()
+	existing, exists := c.corps[key]
+	switch {
+	case c.corpsClosed:
+		c.corpsMu.Unlock()
+		_ = sub.Close()
+		return nil, config.ErrClientClosed
+	case exists:
+		// 其他 goroutine 已创建
+		c.corpsMu.Unlock()
+		_ = sub.Close()
+		return existing, nil
+	}
+	if c.corps == nil {
+		c.corps = make(map[corpKey]*Client)
+	}
+	c.corps[key] = sub
+	c.corpsMu.Unlock()
+	return sub, nil
+}
+
+// newCorpTokenSource 创建通过上级客户端获取下级/下游企业 access_token 的 token 管理器
+// 缓存键与下级企业自建应用的 token 区分开，避免共享缓存时互相覆盖
+func (c *Client) newCorpTokenSource(key corpKey) *auth.TokenManager {
+	cacheID := fmt.Sprintf("corpgroup:%s:%s:%d:%d", c.config.CorpID, key.corpID, key.agentID, key.businessType)
+	if key.agentKey != "" {
+		cacheID += ":" + key.agentKey
+	}
+
+	tm := auth.NewTokenManager(key.corpID, "", c.config.BaseURL, c.config.Cache, c.config.Logger)
+	tm.SetCacheID(cacheID)
+	if c.config.Locker != nil {
+		tm.SetLocker(c.config.Locker)
+	}
+	if c.config.Observer != nil {
+		tm.SetObserver(c.config.Observer)
+	}
+	tm.SetTokenFetcher(func(ctx context.Context, _ string) (string, int, error) {
+		// 使用创建客户端时指定的上级应用
+		if key.agentKey != "" {
+			ctx = client.WithAgentName(ctx, key.agentKey)
+		}
+		businessType := key.businessType
+		resp, err := c.CorpGroup.GetToken(ctx, &corpgroup.GetTokenRequest{
+			CorpID:       key.corpID,
+			AgentID:      key.agentID,
+			BusinessType: &businessType,
+		})
+		if err != nil {
+			return "", 0, err
+		}
+		return resp.AccessToken, resp.ExpiresIn, nil
+	})
+	return tm
+}
@@ -0,0 +1,175 @@
+package wecom_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/pkg/breaker"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/ratelimit"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestForCorp_BuildsOutsideLock(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	entered := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv.Handle("/cgi-bin/corpgroup/corp/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		corpID := req["corpid"].(string)
+		// 下级企业 wwslow 获取 token 时阻塞
+		if corpID == "wwslow" {
+			entered <- struct{}{}
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": srv.IssueToken(corpID),
+			"expires_in":   7200,
+		})
+	})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := client.ForCorp(ctx, "wwslow", 1000005)
+		slow <- err
+	}()
+	<-entered
+
+	// 其他下级企业不受获取 token 缓慢的企业阻塞
+	sub, err := client.ForCorp(ctx, "wwsubcorp", 1000005)
+	require.NoError(t, err)
+	assert.NotNil(t, sub)
+
+	// 创建期间上级客户端关闭，新建的客户端被丢弃
+	require.NoError(t, client.Close())
+	close(release)
+	assert.ErrorIs(t, <-slow, config.ErrClientClosed)
+	_, err = client.ForCorp(ctx, "wwsubcorp", 1000005)
+	assert.ErrorIs(t, err, config.ErrClientClosed)
+}
+
+func TestForCorp(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Handle("/cgi-bin/corpgroup/corp/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": srv.IssueToken(req["corpid"].(string)),
+			"expires_in":   7200,
+		})
+	})
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	sub, err := client.ForChainCorp(ctx, "wwsubcorp", 1000005)
+	require.NoError(t, err)
+	srv.AssertBody(t, "/cgi-bin/corpgroup/corp/gettoken", `{"corpid": "wwsubcorp", "agentid": 1000005, "business_type": 1}`)
+
+	// 下级企业客户端使用 corpgroup/corp/gettoken 获取的 token
+	_, err = sub.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	srv.AssertAgent(t, "/cgi-bin/user/get", "wwsubcorp")
+	srv.AssertCalledTimes(t, "/cgi-bin/corpgroup/corp/gettoken", 1)
+
+	same, err := client.ForChainCorp(ctx, "wwsubcorp", 1000005)
+	require.NoError(t, err)
+	assert.Same(t, sub, same)
+
+	// token 失效后重新获取，上级企业的 token 同时失效，gettoken 先返回 42001 再刷新重试
+	srv.ExpireTokens()
+	_, err = sub.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/corpgroup/corp/gettoken", 3)
+	srv.AssertAgent(t, "/cgi-bin/user/get", "wwsubcorp")
+}
+
+// corpObserver 记录熔断器状态变化与获取 token 的企业
+type corpObserver struct {
+	*circuitObserver
+	fetched []string
+}
+
+func (o *corpObserver) TokenFetched(ctx context.Context, fetch *observer.TokenFetch) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fetched = append(o.fetched, fetch.CorpID)
+}
+
+func TestForCorp_CorpID(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Handle("/cgi-bin/corpgroup/corp/gettoken", func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token": srv.IssueToken(req["corpid"].(string)),
+			"expires_in":   7200,
+		})
+	})
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+	srv.StubError("/cgi-bin/department/list", 10001, "system busy")
+
+	limiter := ratelimit.New(
+		ratelimit.WithoutDefaultRules(),
+		ratelimit.WithMode(ratelimit.ModeFailFast),
+		ratelimit.WithRules(ratelimit.Rule{Name: "user-get", PerCorp: true, Paths: []string{"/cgi-bin/user/get"}, Limit: 1, Window: time.Hour}),
+	)
+	cb := breaker.New(breaker.WithFailureThreshold(1))
+	obs := &corpObserver{circuitObserver: &circuitObserver{}}
+	client, err := wecom.New(append(srv.Options(),
+		config.WithRateLimit(limiter),
+		config.WithCircuitBreaker(cb),
+		config.WithObserver(obs),
+	)...)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+
+	sub, err := client.ForCorp(ctx, "wwsubcorp", 1000005)
+	require.NoError(t, err)
+
+	// token 事件使用下级企业ID
+	assert.Contains(t, obs.fetched, "wwsubcorp")
+
+	// 限流按下级企业ID计数，不影响上级企业
+	_, err = sub.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	assert.False(t, limiter.Allow(ratelimit.Key{CorpID: "wwsubcorp", Path: "/cgi-bin/user/get"}))
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+
+	// 熔断按下级企业ID统计，状态变化由下级企业客户端通知
+	_, err = sub.Contact.ListDepartments(ctx, 0)
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	assert.Equal(t, breaker.StateOpen, cb.State(breaker.Key{CorpID: "wwsubcorp", Path: "/cgi-bin/department/list"}))
+	assert.Equal(t, breaker.StateClosed, cb.State(breaker.Key{CorpID: wecomtest.CorpID, Path: "/cgi-bin/department/list"}))
+	assert.Equal(t, []string{"wwsubcorp|/cgi-bin/department/list:closed->open"}, obs.changes)
+}
+
+func TestForCorp_Unauthorized(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubError("/cgi-bin/corpgroup/corp/gettoken", 40093, "invalid corpid")
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	_, err = client.ForCorp(context.Background(), "wwunknown", 1000005)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "40093")
+}
@@ -14,16 +14,25 @@ type cacheItem struct {
 	expireAt time.Time
 }
 
+// lockItem 锁租约
+type lockItem struct {
+	owner    string
+	expireAt time.Time
+}
+
 // MemoryCache 内存缓存实现
+// 同时实现了 cache.Locker，可作为多个 TokenManager 共享存储的参考实现
 type MemoryCache struct {
 	mu    sync.RWMutex
 	items map[string]*cacheItem
+	locks map[string]*lockItem
 }
 
 // NewMemoryCache 创建内存缓存
 func NewMemoryCache() cache.Cache {
 	return &MemoryCache{
 		items: make(map[string]*cacheItem),
+		locks: make(map[string]*lockItem),
 	}
 }
 
@@ -66,3 +75,31 @@ func (c *MemoryCache) Delete(ctx context.Context, key string) error {
 	delete(c.items, key)
 	return nil
 }
+
+// TryLock 尝试获取锁
+func (c *MemoryCache) TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if l, exists := c.locks[key]; exists && l.owner != owner && now.Before(l.expireAt) {
+		return false, nil
+	}
+
+	c.locks[key] = &lockItem{
+		owner:    owner,
+		expireAt: now.Add(ttl),
+	}
+	return true, nil
+}
+
+// Unlock 释放锁
+func (c *MemoryCache) Unlock(ctx context.Context, key, owner string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if l, exists := c.locks[key]; exists && l.owner == owner {
+		delete(c.locks, key)
+	}
+	return nil
+}
@@ -104,3 +104,39 @@ func TestMemoryCache_Concurrent(t *testing.T) {
 		<-done
 	}
 }
+
+func TestMemoryCache_Lock(t *testing.T) {
+	c := NewMemoryCache().(cache.Locker)
+	ctx := context.Background()
+
+	ok, err := c.TryLock(ctx, "lock", "a", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	// 其他持有者无法获取
+	ok, err = c.TryLock(ctx, "lock", "b", time.Hour)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 非持有者释放无效
+	require.NoError(t, c.Unlock(ctx, "lock", "b"))
+	ok, _ = c.TryLock(ctx, "lock", "b", time.Hour)
+	assert.False(t, ok)
+
+	require.NoError(t, c.Unlock(ctx, "lock", "a"))
+	ok, _ = c.TryLock(ctx, "lock", "b", time.Hour)
+	assert.True(t, ok)
+}
+
+func TestMemoryCache_LockExpired(t *testing.T) {
+	c := NewMemoryCache().(cache.Locker)
+	ctx := context.Background()
+
+	ok, _ := c.TryLock(ctx, "lock", "a", -time.Second)
+	require.True(t, ok)
+
+	// 租约过期后可被其他持有者获取
+	ok, err := c.TryLock(ctx, "lock", "b", time.Hour)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"math/rand/v2"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shuaidd/wecom-core/pkg/logger"
+)
+
+const (
+	// DefaultRefreshInterval 后台刷新器默认的检查间隔
+	DefaultRefreshInterval = 1 * time.Minute
+
+	// DefaultRefreshAhead 后台刷新器默认的提前刷新时间
+	// 缓存的过期时间已提前 TokenExpireOffset，此处在其基础上再提前，保证请求路径上不会遇到过期 token
+	DefaultRefreshAhead = 5 * time.Minute
+)
+
+// Refresher 后台 token 刷新器
+// 定期检查所有已注册应用的 token，在过期前主动刷新，避免 gettoken 出现在业务请求的延迟路径上。
+// 刷新失败只记录日志，请求时仍会按需刷新（懒加载）。
+type Refresher struct {
+	// tm Token管理器
+	tm *TokenManager
+	// interval 检查间隔
+	interval time.Duration
+	// ahead 剩余有效期小于该值时刷新
+	ahead time.Duration
+	// jitter 随机提前量上限，避免多实例、多应用同时刷新
+	jitter time.Duration
+
+	// stop 停止信号
+	stop chan struct{}
+	// done 后台协程退出信号
+	done chan struct{}
+	// startOnce 保证只启动一次
+	startOnce sync.Once
+	// stopOnce 保证只停止一次
+	stopOnce sync.Once
+}
+
+// NewRefresher 创建后台 token 刷新器
+// interval、ahead 为 0 时使用默认值，随机提前量为检查间隔
+func NewRefresher(tm *TokenManager, interval, ahead time.Duration) *Refresher {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	if ahead <= 0 {
+		ahead = DefaultRefreshAhead
+	}
+	return &Refresher{
+		tm:       tm,
+		interval: interval,
+		ahead:    ahead,
+		jitter:   interval,
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start 启动后台刷新，启动时立即检查一次
+func (r *Refresher) Start() {
+	r.startOnce.Do(func() {
+		go r.run()
+	})
+}
+
+// Stop 停止后台刷新并等待正在进行的刷新结束
+func (r *Refresher) Stop() {
+	r.stopOnce.Do(func() {
+		close(r.stop)
+	})
+	r.startOnce.Do(func() {
+		// 未启动时直接标记为已退出，之后也不会再启动
+		close(r.done)
+	})
+	<-r.done
+}
+
+// run 后台刷新循环
+func (r *Refresher) run() {
+	defer close(r.done)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.refreshAll(ctx)
+
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// refreshAll 检查并刷新所有应用的 token
+func (r *Refresher) refreshAll(ctx context.Context) {
+	for _, agentKey := range r.tm.agentKeys() {
+		if ctx.Err() != nil {
+			return
+		}
+		r.refreshAgent(ctx, agentKey)
+	}
+}
+
+// refreshAgent 在 token 即将过期时刷新
+func (r *Refresher) refreshAgent(ctx context.Context, agentKey string) {
+	token, expireAt, err := r.tm.cache.Get(ctx, r.tm.cacheKey(agentKey))
+	if err == nil {
+		ahead := r.ahead
+		if r.jitter > 0 {
+			ahead += rand.N(r.jitter)
+		}
+		if time.Until(expireAt) > ahead {
+			return
+		}
+	} else {
+		token = ""
+	}
+
+	r.tm.logger.Debug("Proactively refreshing token",
+		logger.F("agent_key", agentKey),
+		logger.F("expire_at", expireAt))
+
+	if err := r.tm.RefreshTokenIfStale(ctx, agentKey, token); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		r.tm.logger.Error("Background token refresh failed, falling back to lazy refresh",
+			logger.F("agent_key", agentKey),
+			logger.F("error", err))
+	}
+}
+
+// agentKeys 返回需要刷新的应用key（默认应用对应空字符串）
+func (tm *TokenManager) agentKeys() []string {
+	var keys []string
+	if tm.corpSecret != "" || (tm.fetcher != nil && len(tm.agents) == 0) {
+		keys = append(keys, "")
+	}
+	for key := range tm.agents {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
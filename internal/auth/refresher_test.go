@@ -0,0 +1,83 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/pkg/logger"
+)
+
+func TestRefresher_RefreshesBeforeExpiry(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	tm := NewTokenManager("corp", "secret", srv.URL, nil, logger.NewNoopLogger())
+	tm.RegisterAgent("app", 1000002, "app-secret")
+	ctx := context.Background()
+
+	// 默认应用的 token 即将过期，app 的 token 还很新
+	require.NoError(t, tm.cache.Set(ctx, tm.cacheKey(""), "old", time.Now().Add(time.Second)))
+	require.NoError(t, tm.cache.Set(ctx, tm.cacheKey("app"), "fresh", time.Now().Add(time.Hour)))
+
+	r := NewRefresher(tm, time.Hour, time.Minute)
+	r.jitter = 0
+	r.Start()
+	defer r.Stop()
+
+	assert.Eventually(t, func() bool {
+		token, _, err := tm.cache.Get(ctx, tm.cacheKey(""))
+		return err == nil && token == "token-1"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	token, err := tm.GetTokenByAgent(ctx, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "fresh", token)
+	assert.Equal(t, int32(1), srv.Calls())
+}
+
+func TestRefresher_FetchesMissingTokens(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	tm := NewTokenManager("corp", "", srv.URL, nil, logger.NewNoopLogger())
+	tm.RegisterAgent("a", 1, "secret-a")
+	tm.RegisterAgent("b", 2, "secret-b")
+
+	r := NewRefresher(tm, 0, 0)
+	r.Start()
+	defer r.Stop()
+
+	assert.Eventually(t, func() bool {
+		return srv.Calls() == 2
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func TestRefresher_FailureFallsBackToLazy(t *testing.T) {
+	// 指向不存在的地址，后台刷新失败只记录日志
+	tm := NewTokenManager("corp", "secret", "http://127.0.0.1:1", nil, logger.NewNoopLogger())
+	r := NewRefresher(tm, 10*time.Millisecond, 0)
+	r.Start()
+	time.Sleep(30 * time.Millisecond)
+	r.Stop()
+
+	srv := newFakeTokenServer(t)
+	tm.baseURL = srv.URL
+	token, err := tm.GetToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+}
+
+func TestRefresher_Stop(t *testing.T) {
+	tm := NewTokenManager("corp", "secret", "", nil, logger.NewNoopLogger())
+
+	// 未启动也可以停止，且可重复调用
+	r := NewRefresher(tm, 0, 0)
+	r.Stop()
+	r.Stop()
+	r.Start()
+
+	r = NewRefresher(tm, time.Hour, 0)
+	r.Start()
+	r.Stop()
+	r.Stop()
+}
@@ -0,0 +1,251 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/redact"
+)
+
+// TicketType JS-SDK ticket 类型
+type TicketType string
+
+const (
+	// TicketTypeJSAPI 企业的 jsapi_ticket，用于 wx.config
+	TicketTypeJSAPI TicketType = "jsapi"
+	// TicketTypeAgentConfig 应用的 jsapi_ticket，用于 wx.agentConfig
+	TicketTypeAgentConfig TicketType = "agent_config"
+)
+
+// TicketResponse ticket响应
+type TicketResponse struct {
+	ErrCode   int    `json:"errcode"`
+	ErrMsg    string `json:"errmsg"`
+	Ticket    string `json:"ticket"`
+	ExpiresIn int    `json:"expires_in"`
+}
+
+// TicketManager JS-SDK ticket 管理器
+// ticket 有效期为2小时且获取频率受限，与 access_token 一样需要全局缓存
+type TicketManager struct {
+	// tm Token管理器，提供缓存、刷新锁与应用配置
+	tm *TokenManager
+	// source access_token 来源，默认为 tm
+	source TokenSource
+}
+
+// NewTicketManager 创建 ticket 管理器
+func NewTicketManager(tm *TokenManager) *TicketManager {
+	return &TicketManager{tm: tm, source: tm}
+}
+
+// SetTokenSource 设置获取 access_token 的来源，用于接入自定义的 TokenSource
+func (m *TicketManager) SetTokenSource(ts TokenSource) {
+	m.source = ts
+}
+
+// GetJSAPITicket 获取企业的 jsapi_ticket
+// 文档: https://developer.work.weixin.qq.com/document/path/90506
+func (m *TicketManager) GetJSAPITicket(ctx context.Context, agentKey string) (string, error) {
+	return m.GetTicket(ctx, agentKey, TicketTypeJSAPI)
+}
+
+// GetAgentConfigTicket 获取应用的 jsapi_ticket
+// 文档: https://developer.work.weixin.qq.com/document/path/90506
+func (m *TicketManager) GetAgentConfigTicket(ctx context.Context, agentKey string) (string, error) {
+	return m.GetTicket(ctx, agentKey, TicketTypeAgentConfig)
+}
+
+// GetTicket 根据应用key与类型获取 ticket
+func (m *TicketManager) GetTicket(ctx context.Context, agentKey string, ticketType TicketType) (string, error) {
+	cacheAgent := m.normalizeAgentKey(agentKey)
+	cacheKey := m.cacheKey(cacheAgent, ticketType)
+
+	// 1. 从缓存获取
+	ticket, expireAt, err := m.tm.cache.Get(ctx, cacheKey)
+	if err == nil && time.Now().Before(expireAt) {
+		return ticket, nil
+	}
+
+	// 2. 加锁刷新（与 token 共用按应用划分的锁表）
+	lock := m.tm.getRefreshLock(m.lockKey(cacheAgent, ticketType))
+	lock.Lock()
+	defer lock.Unlock()
+
+	// 3. Double-check
+	ticket, expireAt, err = m.tm.cache.Get(ctx, cacheKey)
+	if err == nil && time.Now().Before(expireAt) {
+		return ticket, nil
+	}
+
+	// 4. 调用 API 获取 ticket
+	m.tm.logger.Info("Fetching new ticket from API",
+		logger.F("agent_key", agentKey),
+		logger.F("ticket_type", ticketType))
+	ticket, expiresIn, err := m.fetchTicket(ctx, agentKey, ticketType)
+	if err != nil {
+		m.tm.logger.Error("Failed to fetch ticket",
+			logger.F("agent_key", agentKey),
+			logger.F("ticket_type", ticketType),
+			logger.F("error", err))
+		return "", err
+	}
+
+	// 5. 缓存 ticket（提前 5 分钟过期）
+	expireAt = time.Now().Add(time.Duration(expiresIn-TokenExpireOffset) * time.Second)
+	if err := m.tm.cache.Set(ctx, cacheKey, ticket, expireAt); err != nil {
+		m.tm.logger.Warn("Failed to cache ticket",
+			logger.F("agent_key", agentKey),
+			logger.F("ticket_type", ticketType),
+			logger.F("error", err))
+	}
+
+	m.tm.logger.Info("Ticket refreshed successfully",
+		logger.F("agent_key", agentKey),
+		logger.F("ticket_type", ticketType),
+		logger.F("expire_at", expireAt))
+
+	return ticket, nil
+}
+
+// AgentID 获取应用ID，用于 wx.agentConfig
+// agentKey 为空时，仅在只配置了一个应用时返回该应用的ID
+func (m *TicketManager) AgentID(agentKey string) (int64, error) {
+	if agentKey == "" {
+		// 同一应用会同时以名称和ID注册，按应用ID去重
+		var agentID int64
+		for _, agent := range m.tm.agents {
+			if agentID != 0 && agent.AgentID != agentID {
+				return 0, errors.New("agent key is required when multiple agents are configured")
+			}
+			agentID = agent.AgentID
+		}
+		if agentID == 0 {
+			return 0, errors.New("no agent configured")
+		}
+		return agentID, nil
+	}
+	if agent, ok := m.tm.agents[agentKey]; ok && agent.AgentID > 0 {
+		return agent.AgentID, nil
+	}
+	return 0, fmt.Errorf("agent not found or agent id is empty: %s", agentKey)
+}
+
+// normalizeAgentKey 返回 ticket 缓存使用的应用标识
+// 应用同时以名称和ID注册，统一使用应用ID；未指定应用且未设置 corpSecret 时，只配置了一个应用则使用该应用，
+// 避免同一应用的 ticket 按不同的 key 重复获取与缓存
+func (m *TicketManager) normalizeAgentKey(agentKey string) string {
+	if agentKey == "" {
+		if m.tm.corpSecret != "" {
+			return ""
+		}
+		agentID, err := m.AgentID("")
+		if err != nil {
+			return ""
+		}
+		return strconv.FormatInt(agentID, 10)
+	}
+	if agent, ok := m.tm.agents[agentKey]; ok && agent.AgentID > 0 {
+		return strconv.FormatInt(agent.AgentID, 10)
+	}
+	return agentKey
+}
+
+// CorpID 获取企业ID
+func (m *TicketManager) CorpID() string {
+	return m.tm.CorpID()
+}
+
+// fetchTicket 从API获取 ticket，access_token 失效时刷新后重试一次
+func (m *TicketManager) fetchTicket(ctx context.Context, agentKey string, ticketType TicketType) (string, int, error) {
+	token, err := m.source.GetTokenByAgent(ctx, agentKey)
+	if err != nil {
+		return "", 0, err
+	}
+	ticket, expiresIn, err := m.fetchTicketFromAPI(ctx, token, ticketType)
+	if err != nil && wecomerrors.IsTokenExpired(err) {
+		if refreshErr := m.source.RefreshTokenIfStale(ctx, agentKey, token); refreshErr != nil {
+			return "", 0, refreshErr
+		}
+		if token, err = m.source.GetTokenByAgent(ctx, agentKey); err != nil {
+			return "", 0, err
+		}
+		ticket, expiresIn, err = m.fetchTicketFromAPI(ctx, token, ticketType)
+	}
+	return ticket, expiresIn, err
+}
+
+// fetchTicketFromAPI 使用 access_token 调用接口获取 ticket
+func (m *TicketManager) fetchTicketFromAPI(ctx context.Context, token string, ticketType TicketType) (string, int, error) {
+
+	query := url.Values{}
+	query.Set("access_token", token)
+	path := "/cgi-bin/get_jsapi_ticket"
+	if ticketType == TicketTypeAgentConfig {
+		path = "/cgi-bin/ticket/get"
+		query.Set("type", string(TicketTypeAgentConfig))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, m.tm.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create request: %w", redact.Error(err))
+	}
+
+	resp, err := m.tm.httpClient.Do(req)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to fetch ticket: %w", redact.Error(err))
+	}
+	defer resp.Body.Close()
+
+	var ticketResp TicketResponse
+	if err := json.NewDecoder(resp.Body).Decode(&ticketResp); err != nil {
+		return "", 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if ticketResp.ErrCode != 0 {
+		return "", 0, wecomerrors.New(ticketResp.ErrCode, ticketResp.ErrMsg)
+	}
+
+	if ticketResp.Ticket == "" {
+		return "", 0, errors.New("empty ticket in response")
+	}
+
+	return ticketResp.Ticket, ticketResp.ExpiresIn, nil
+}
+
+// cacheKey 获取 ticket 缓存key
+func (m *TicketManager) cacheKey(agentKey string, ticketType TicketType) string {
+	if agentKey == "" {
+		return fmt.Sprintf("wecom:ticket:%s:%s", ticketType, m.tm.corpID)
+	}
+	return fmt.Sprintf("wecom:ticket:%s:%s:%s", ticketType, m.tm.corpID, agentKey)
+}
+
+// lockKey 获取 ticket 刷新锁的key，与 token 的锁区分开以免获取 token 时死锁
+func (m *TicketManager) lockKey(agentKey string, ticketType TicketType) string {
+	return fmt.Sprintf("ticket:%s:%s", ticketType, agentKey)
+}
+
+// SignJSAPI 生成 JS-SDK 签名
+// 对 jsapi_ticket、noncestr、timestamp、url 按字段名的字典序拼接后做 sha1
+// url 为调用 JS 接口页面的完整URL，不包含 # 及其后面部分
+// 文档: https://developer.work.weixin.qq.com/document/path/90506
+func SignJSAPI(ticket, nonceStr string, timestamp int64, pageURL string) string {
+	if i := strings.IndexByte(pageURL, '#'); i >= 0 {
+		pageURL = pageURL[:i]
+	}
+	s := fmt.Sprintf("jsapi_ticket=%s&noncestr=%s&timestamp=%d&url=%s", ticket, nonceStr, timestamp, pageURL)
+	sum := sha1.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/pkg/logger"
+)
+
+func TestSignJSAPI(t *testing.T) {
+	// 官方文档示例
+	sig := SignJSAPI(
+		"sM4AOVdWfPE4DxkXGEs8VMCPGGVi4C3VM0P37wVUCFvkVAy_90u5h9nbSlYy3-Sl-HhTdfl2fzFy1AOcHKP7qg",
+		"Wm3WZYTPz0wzccnW",
+		1414587457,
+		"http://mp.weixin.qq.com?params=value#section",
+	)
+	assert.Equal(t, "0f9de62fce790f9a083d5c99e95740ceb90c27ed", sig)
+}
+
+// newTicketServer 创建模拟 gettoken 与 ticket 接口的服务
+func newTicketServer(t *testing.T, tokenCalls, ticketCalls *int32, expireFirstToken bool) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/cgi-bin/gettoken":
+			n := atomic.AddInt32(tokenCalls, 1)
+			fmt.Fprintf(w, `{"errcode":0,"access_token":"token-%d","expires_in":7200}`, n)
+		case "/cgi-bin/get_jsapi_ticket", "/cgi-bin/ticket/get":
+			if expireFirstToken && r.URL.Query().Get("access_token") == "token-1" {
+				fmt.Fprint(w, `{"errcode":42001,"errmsg":"access_token expired"}`)
+				return
+			}
+			n := atomic.AddInt32(ticketCalls, 1)
+			kind := "corp"
+			if r.URL.Query().Get("type") == "agent_config" {
+				kind = "agent"
+			}
+			fmt.Fprintf(w, `{"errcode":0,"ticket":"%s-ticket-%d","expires_in":7200}`, kind, n)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestTicketManager_GetTicket(t *testing.T) {
+	var tokenCalls, ticketCalls int32
+	srv := newTicketServer(t, &tokenCalls, &ticketCalls, false)
+	defer srv.Close()
+
+	tm := NewTokenManager("corp", "", srv.URL, nil, logger.NewNoopLogger())
+	tm.RegisterAgent("app", 1000002, "secret")
+	m := NewTicketManager(tm)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ticket, err := m.GetJSAPITicket(ctx, "app")
+			assert.NoError(t, err)
+			assert.Equal(t, "corp-ticket-1", ticket)
+		}()
+	}
+	wg.Wait()
+
+	ticket, err := m.GetAgentConfigTicket(ctx, "app")
+	require.NoError(t, err)
+	assert.Equal(t, "agent-ticket-2", ticket)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&tokenCalls))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&ticketCalls))
+}
+
+func TestTicketManager_NormalizeAgentKey(t *testing.T) {
+	var tokenCalls, ticketCalls int32
+	srv := newTicketServer(t, &tokenCalls, &ticketCalls, false)
+	defer srv.Close()
+
+	tm := NewTokenManager("corp", "", srv.URL, nil, logger.NewNoopLogger())
+	tm.RegisterAgent("app", 1000002, "secret")
+	tm.RegisterAgent("1000002", 1000002, "secret")
+	m := NewTicketManager(tm)
+	ctx := context.Background()
+
+	// 应用名称、应用ID与默认应用共用同一个 ticket
+	for _, agentKey := range []string{"app", "1000002", ""} {
+		ticket, err := m.GetJSAPITicket(ctx, agentKey)
+		require.NoError(t, err)
+		assert.Equal(t, "corp-ticket-1", ticket)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&ticketCalls))
+
+	// 配置了多个应用时，未指定应用的 ticket 单独缓存
+	tm.RegisterAgent("other", 1000003, "secret")
+	assert.Equal(t, "", m.normalizeAgentKey(""))
+	assert.Equal(t, "1000003", m.normalizeAgentKey("other"))
+	assert.Equal(t, "unknown", m.normalizeAgentKey("unknown"))
+}
+
+func TestTicketManager_RefreshExpiredToken(t *testing.T) {
+	var tokenCalls, ticketCalls int32
+	srv := newTicketServer(t, &tokenCalls, &ticketCalls, true)
+	defer srv.Close()
+
+	tm := NewTokenManager("corp", "secret", srv.URL, nil, logger.NewNoopLogger())
+	m := NewTicketManager(tm)
+
+	ticket, err := m.GetJSAPITicket(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "corp-ticket-1", ticket)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&tokenCalls))
+}
+
+func TestTicketManager_AgentID(t *testing.T) {
+	tm := NewTokenManager("corp", "", "", nil, logger.NewNoopLogger())
+	m := NewTicketManager(tm)
+
+	_, err := m.AgentID("")
+	assert.Error(t, err)
+
+	tm.RegisterAgent("app", 1000002, "secret")
+	tm.RegisterAgent("1000002", 1000002, "secret")
+	id, err := m.AgentID("")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000002), id)
+
+	tm.RegisterAgent("other", 1000003, "secret")
+	_, err = m.AgentID("")
+	assert.Error(t, err)
+
+	id, err = m.AgentID("other")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000003), id)
+}
@@ -2,6 +2,8 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,12 +13,27 @@ import (
 
 	"github.com/shuaidd/wecom-core/pkg/cache"
 	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/redact"
 )
 
 const (
 	// TokenExpireOffset token 提前刷新时间（秒）
 	// 提前 5 分钟刷新 token，避免在使用时过期
 	TokenExpireOffset = 300
+
+	// TokenFetchTimeout 调用 gettoken 的 HTTP 超时时间
+	TokenFetchTimeout = 30 * time.Second
+
+	// TokenLockTTL 分布式刷新锁的租约时长
+	// 大于 gettoken 的超时时间，保证请求仍在进行时租约不会过期而被其他实例同时刷新；
+	// 持有锁的实例异常退出时，其他实例最多等待该时长后接手刷新
+	TokenLockTTL = TokenFetchTimeout + 5*time.Second
+
+	// tokenLockMinBackoff 等待其他实例刷新时的初始轮询间隔
+	tokenLockMinBackoff = 50 * time.Millisecond
+	// tokenLockMaxBackoff 等待其他实例刷新时的最大轮询间隔
+	tokenLockMaxBackoff = 1 * time.Second
 )
 
 // TokenResponse token响应
@@ -27,6 +44,22 @@ type TokenResponse struct {
 	ExpiresIn   int    `json:"expires_in"`
 }
 
+// TokenFetcher 自定义 access_token 获取函数
+// 返回 token 及其有效期（秒），用于第三方应用、上下游等不使用 corpid+secret 调用 gettoken 的场景
+type TokenFetcher func(ctx context.Context, agentKey string) (token string, expiresIn int, err error)
+
+// TokenSource access_token 来源，HTTP 客户端通过它获取和刷新 token
+// agentKey 为 context 中指定的应用名称或ID，未指定时为空
+type TokenSource interface {
+	// GetTokenByAgent 获取应用的 access_token
+	GetTokenByAgent(ctx context.Context, agentKey string) (string, error)
+	// RefreshTokenIfStale 在 token 失效（40014/42001）时刷新，stale 为请求时使用的 token
+	RefreshTokenIfStale(ctx context.Context, agentKey, stale string) error
+}
+
+// TokenManager 是默认的 TokenSource 实现，通过 corpid+secret 调用 gettoken
+var _ TokenSource = (*TokenManager)(nil)
+
 // TokenManager Token管理器
 type TokenManager struct {
 	// corpID 企业ID
@@ -47,6 +80,16 @@ type TokenManager struct {
 	refreshLocks map[string]*sync.Mutex
 	// refreshLocksMapLock 保护refreshLocks map的锁
 	refreshLocksMapLock sync.Mutex
+	// locker 跨进程刷新锁(可选)，多实例共享缓存时避免各自刷新token
+	locker cache.Locker
+	// owner 当前实例持有分布式锁时的标识
+	owner string
+	// fetcher 自定义 token 获取函数(可选)，设置后替代 gettoken
+	fetcher TokenFetcher
+	// observer 可观测性钩子
+	observer observer.Observer
+	// cacheID 缓存key中的企业标识(可选)，为空时使用 corpID
+	cacheID string
 }
 
 // AgentInfo 应用信息
@@ -62,17 +105,63 @@ func NewTokenManager(corpID, corpSecret, baseURL string, c cache.Cache, log logg
 		c = NewMemoryCache()
 	}
 
-	return &TokenManager{
+	tm := &TokenManager{
 		corpID:              corpID,
 		corpSecret:          corpSecret,
 		baseURL:             baseURL,
 		cache:               c,
 		logger:              log,
-		httpClient:          &http.Client{Timeout: 30 * time.Second},
+		httpClient:          &http.Client{Timeout: TokenFetchTimeout},
 		agents:              make(map[string]*AgentInfo),
 		refreshLocks:        make(map[string]*sync.Mutex),
 		refreshLocksMapLock: sync.Mutex{},
+		owner:               newLockOwner(),
+		observer:            observer.NoopObserver{},
+	}
+
+	// 缓存同时实现了 Locker 时，默认使用其作为跨进程刷新锁
+	if l, ok := c.(cache.Locker); ok {
+		tm.locker = l
 	}
+
+	return tm
+}
+
+// SetLocker 设置跨进程刷新锁
+// 多个实例共享同一缓存（如 Redis）时，应设置基于同一存储的锁，保证同一时刻只有一个实例调用 gettoken
+func (tm *TokenManager) SetLocker(l cache.Locker) {
+	tm.locker = l
+}
+
+// SetTransport 设置调用 gettoken 使用的 HTTP 传输层
+func (tm *TokenManager) SetTransport(rt http.RoundTripper) {
+	tm.httpClient.Transport = rt
+}
+
+// SetTokenFetcher 设置自定义 token 获取函数
+// 设置后不再校验应用 secret，缓存与刷新锁逻辑保持不变
+func (tm *TokenManager) SetTokenFetcher(f TokenFetcher) {
+	tm.fetcher = f
+}
+
+// SetObserver 设置可观测性钩子，记录 token 获取与缓存命中情况
+func (tm *TokenManager) SetObserver(o observer.Observer) {
+	if o == nil {
+		o = observer.NoopObserver{}
+	}
+	tm.observer = o
+}
+
+// SetCacheID 设置缓存key与刷新锁中使用的企业标识
+// 同一企业存在多种 token 来源（如下级企业的共享应用）时，用于与自建应用的缓存区分开，
+// 请求、限流、熔断与可观测性事件仍使用 corpID
+func (tm *TokenManager) SetCacheID(id string) {
+	tm.cacheID = id
+}
+
+// CorpID 获取企业ID
+func (tm *TokenManager) CorpID() string {
+	return tm.corpID
 }
 
 // RegisterAgent 注册应用
@@ -107,7 +196,7 @@ func (tm *TokenManager) GetToken(ctx context.Context) (string, error) {
 func (tm *TokenManager) GetTokenByAgent(ctx context.Context, agentKey string) (string, error) {
 	// 获取应用的 secret
 	secret := tm.getAgentSecret(agentKey)
-	if secret == "" {
+	if secret == "" && tm.fetcher == nil {
 		return "", fmt.Errorf("agent not found or secret is empty: %s", agentKey)
 	}
 
@@ -115,7 +204,9 @@ func (tm *TokenManager) GetTokenByAgent(ctx context.Context, agentKey string) (s
 
 	// 1. 从缓存获取
 	token, expireAt, err := tm.cache.Get(ctx, cacheKey)
-	if err == nil && time.Now().Before(expireAt) {
+	hit := err == nil && time.Now().Before(expireAt)
+	tm.observer.TokenCacheLookup(ctx, &observer.CacheLookup{CorpID: tm.corpID, AgentKey: agentKey, Hit: hit})
+	if hit {
 		tm.logger.Debug("Token retrieved from cache",
 			logger.F("agent_key", agentKey),
 			logger.F("expire_at", expireAt))
@@ -136,31 +227,8 @@ func (tm *TokenManager) GetTokenByAgent(ctx context.Context, agentKey string) (s
 		return token, nil
 	}
 
-	// 4. 调用 API 获取 token
-	tm.logger.Info("Fetching new token from API",
-		logger.F("agent_key", agentKey))
-	token, expiresIn, err := tm.fetchTokenFromAPI(ctx, secret)
-	if err != nil {
-		tm.logger.Error("Failed to fetch token",
-			logger.F("agent_key", agentKey),
-			logger.F("error", err))
-		return "", err
-	}
-
-	// 5. 缓存 token（提前 5 分钟过期）
-	expireAt = time.Now().Add(time.Duration(expiresIn-TokenExpireOffset) * time.Second)
-	if err := tm.cache.Set(ctx, cacheKey, token, expireAt); err != nil {
-		tm.logger.Warn("Failed to cache token",
-			logger.F("agent_key", agentKey),
-			logger.F("error", err))
-	}
-
-	tm.logger.Info("Token refreshed successfully",
-		logger.F("agent_key", agentKey),
-		logger.F("expires_in", expiresIn),
-		logger.F("expire_at", expireAt))
-
-	return token, nil
+	// 4. 获取跨进程租约后调用 API 获取 token
+	return tm.refresh(ctx, agentKey, secret, "")
 }
 
 // RefreshToken 强制刷新token（用于 token 失效重试，默认应用）
@@ -169,10 +237,18 @@ func (tm *TokenManager) RefreshToken(ctx context.Context) error {
 }
 
 // RefreshTokenByAgent 强制刷新指定应用的token
+// 当前缓存中的 token 视为已失效；若等待期间其他协程或实例已完成刷新，则直接使用新的 token
 func (tm *TokenManager) RefreshTokenByAgent(ctx context.Context, agentKey string) error {
+	stale, _, _ := tm.cache.Get(ctx, tm.cacheKey(agentKey))
+	return tm.RefreshTokenIfStale(ctx, agentKey, stale)
+}
+
+// RefreshTokenIfStale 在 token 失效（40014/42001）时刷新token
+// stale 为请求时使用的 token，如果缓存中已有不同的有效 token，说明已被其他协程或实例刷新，不再重复调用 gettoken
+func (tm *TokenManager) RefreshTokenIfStale(ctx context.Context, agentKey, stale string) error {
 	// 获取应用的 secret
 	secret := tm.getAgentSecret(agentKey)
-	if secret == "" {
+	if secret == "" && tm.fetcher == nil {
 		return fmt.Errorf("agent not found or secret is empty: %s", agentKey)
 	}
 
@@ -180,31 +256,103 @@ func (tm *TokenManager) RefreshTokenByAgent(ctx context.Context, agentKey string
 	lock.Lock()
 	defer lock.Unlock()
 
+	if _, ok := tm.cachedToken(ctx, agentKey, stale); ok {
+		tm.logger.Debug("Token already refreshed",
+			logger.F("agent_key", agentKey))
+		return nil
+	}
+
 	tm.logger.Info("Force refreshing token",
 		logger.F("agent_key", agentKey))
 
-	token, expiresIn, err := tm.fetchTokenFromAPI(ctx, secret)
+	_, err := tm.refresh(ctx, agentKey, secret, stale)
+	return err
+}
+
+// refresh 在持有进程内刷新锁的情况下获取新token并写入缓存
+// 配置了 Locker 时先获取跨进程租约，获取后重新读取共享缓存；其他实例正在刷新时退避等待其结果
+func (tm *TokenManager) refresh(ctx context.Context, agentKey, secret, stale string) (string, error) {
+	if tm.locker != nil {
+		lockKey := tm.cacheKey(agentKey) + ":lock"
+		backoff := tokenLockMinBackoff
+		for {
+			locked, err := tm.locker.TryLock(ctx, lockKey, tm.owner, TokenLockTTL)
+			if err != nil {
+				// 锁服务不可用时退化为仅进程内加锁，避免无法获取token
+				tm.logger.Warn("Failed to acquire token lock, refreshing without it",
+					logger.F("agent_key", agentKey),
+					logger.F("error", err))
+				break
+			}
+			if locked {
+				defer func() {
+					if err := tm.locker.Unlock(context.WithoutCancel(ctx), lockKey, tm.owner); err != nil {
+						tm.logger.Warn("Failed to release token lock",
+							logger.F("agent_key", agentKey),
+							logger.F("error", err))
+					}
+				}()
+
+				// 获取租约后重新读取共享缓存（可能已被其他实例刷新）
+				if token, ok := tm.cachedToken(ctx, agentKey, stale); ok {
+					tm.logger.Debug("Token refreshed by another instance",
+						logger.F("agent_key", agentKey))
+					return token, nil
+				}
+				break
+			}
+
+			// 其他实例正在刷新，等待后从共享缓存读取
+			tm.logger.Debug("Token is being refreshed by another instance, waiting",
+				logger.F("agent_key", agentKey),
+				logger.F("backoff", backoff))
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return "", ctx.Err()
+			case <-timer.C:
+			}
+			if token, ok := tm.cachedToken(ctx, agentKey, stale); ok {
+				return token, nil
+			}
+			backoff = min(backoff*2, tokenLockMaxBackoff)
+		}
+	}
+
+	tm.logger.Info("Fetching new token from API",
+		logger.F("agent_key", agentKey))
+	token, expiresIn, err := tm.fetchToken(ctx, agentKey, secret)
 	if err != nil {
-		tm.logger.Error("Failed to refresh token",
+		tm.logger.Error("Failed to fetch token",
 			logger.F("agent_key", agentKey),
 			logger.F("error", err))
-		return err
+		return "", err
 	}
 
-	cacheKey := tm.cacheKey(agentKey)
+	// 缓存 token（提前 5 分钟过期）
 	expireAt := time.Now().Add(time.Duration(expiresIn-TokenExpireOffset) * time.Second)
-	if err := tm.cache.Set(ctx, cacheKey, token, expireAt); err != nil {
-		tm.logger.Warn("Failed to cache refreshed token",
+	if err := tm.cache.Set(ctx, tm.cacheKey(agentKey), token, expireAt); err != nil {
+		tm.logger.Warn("Failed to cache token",
 			logger.F("agent_key", agentKey),
 			logger.F("error", err))
 	}
 
-	tm.logger.Info("Token force refreshed successfully",
+	tm.logger.Info("Token refreshed successfully",
 		logger.F("agent_key", agentKey),
 		logger.F("expires_in", expiresIn),
 		logger.F("expire_at", expireAt))
 
-	return nil
+	return token, nil
+}
+
+// cachedToken 读取缓存中未过期且不同于 stale 的 token
+func (tm *TokenManager) cachedToken(ctx context.Context, agentKey, stale string) (string, bool) {
+	token, expireAt, err := tm.cache.Get(ctx, tm.cacheKey(agentKey))
+	if err != nil || !time.Now().Before(expireAt) || token == "" || token == stale {
+		return "", false
+	}
+	return token, true
 }
 
 // getAgentSecret 获取应用的 secret
@@ -232,6 +380,24 @@ func (tm *TokenManager) getAgentSecret(agentKey string) string {
 	return ""
 }
 
+// fetchToken 获取新token，设置了自定义获取函数时优先使用
+func (tm *TokenManager) fetchToken(ctx context.Context, agentKey, secret string) (token string, expiresIn int, err error) {
+	start := time.Now()
+	defer func() {
+		tm.observer.TokenFetched(ctx, &observer.TokenFetch{
+			CorpID:   tm.corpID,
+			AgentKey: agentKey,
+			Err:      err,
+			Duration: time.Since(start),
+		})
+	}()
+
+	if tm.fetcher != nil {
+		return tm.fetcher(ctx, agentKey)
+	}
+	return tm.fetchTokenFromAPI(ctx, secret)
+}
+
 // fetchTokenFromAPI 从API获取token
 func (tm *TokenManager) fetchTokenFromAPI(ctx context.Context, secret string) (token string, expiresIn int, err error) {
 	url := fmt.Sprintf("%s/cgi-bin/gettoken?corpid=%s&corpsecret=%s",
@@ -239,12 +405,12 @@ func (tm *TokenManager) fetchTokenFromAPI(ctx context.Context, secret string) (t
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to create request: %w", err)
+		return "", 0, fmt.Errorf("failed to create request: %w", redact.Error(err))
 	}
 
 	resp, err := tm.httpClient.Do(req)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to fetch token: %w", err)
+		return "", 0, fmt.Errorf("failed to fetch token: %w", redact.Error(err))
 	}
 	defer resp.Body.Close()
 
@@ -267,8 +433,21 @@ func (tm *TokenManager) fetchTokenFromAPI(ctx context.Context, secret string) (t
 
 // cacheKey 获取缓存key
 func (tm *TokenManager) cacheKey(agentKey string) string {
+	id := tm.corpID
+	if tm.cacheID != "" {
+		id = tm.cacheID
+	}
 	if agentKey == "" {
-		return fmt.Sprintf("wecom:token:%s", tm.corpID)
+		return fmt.Sprintf("wecom:token:%s", id)
+	}
+	return fmt.Sprintf("wecom:token:%s:%s", id, agentKey)
+}
+
+// newLockOwner 生成分布式锁持有者标识
+func newLockOwner() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
 	}
-	return fmt.Sprintf("wecom:token:%s:%s", tm.corpID, agentKey)
+	return hex.EncodeToString(b)
 }
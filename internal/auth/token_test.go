@@ -0,0 +1,217 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/pkg/cache"
+	"github.com/shuaidd/wecom-core/pkg/logger"
+)
+
+// fakeTokenServer 模拟 gettoken 接口，每次调用都会签发新的 token
+type fakeTokenServer struct {
+	*httptest.Server
+	calls int32
+}
+
+func newFakeTokenServer(t *testing.T) *fakeTokenServer {
+	t.Helper()
+	s := &fakeTokenServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&s.calls, 1)
+		// 放大并发窗口
+		time.Sleep(20 * time.Millisecond)
+		fmt.Fprintf(w, `{"errcode":0,"access_token":"token-%d","expires_in":7200}`, n)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeTokenServer) Calls() int32 {
+	return atomic.LoadInt32(&s.calls)
+}
+
+// newSharedManagers 创建共享同一存储的多个 TokenManager，模拟多实例部署
+func newSharedManagers(n int, baseURL string, store cache.Cache) []*TokenManager {
+	managers := make([]*TokenManager, n)
+	for i := range managers {
+		managers[i] = NewTokenManager("corp", "secret", baseURL, store, logger.NewNoopLogger())
+	}
+	return managers
+}
+
+func TestTokenManager_SharedStore_SingleFetch(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	managers := newSharedManagers(5, srv.URL, NewMemoryCache())
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	tokens := make(chan string, 50)
+	for _, tm := range managers {
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(tm *TokenManager) {
+				defer wg.Done()
+				token, err := tm.GetToken(ctx)
+				assert.NoError(t, err)
+				tokens <- token
+			}(tm)
+		}
+	}
+	wg.Wait()
+	close(tokens)
+
+	for token := range tokens {
+		assert.Equal(t, "token-1", token)
+	}
+	assert.Equal(t, int32(1), srv.Calls())
+}
+
+func TestTokenManager_SharedStore_RefreshIfStale(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	managers := newSharedManagers(5, srv.URL, NewMemoryCache())
+	ctx := context.Background()
+
+	token, err := managers[0].GetToken(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", token)
+
+	// 所有实例同时收到 42001，只应有一个实例重新获取
+	var wg sync.WaitGroup
+	for _, tm := range managers {
+		for i := 0; i < 4; i++ {
+			wg.Add(1)
+			go func(tm *TokenManager) {
+				defer wg.Done()
+				assert.NoError(t, tm.RefreshTokenIfStale(ctx, "", "token-1"))
+			}(tm)
+		}
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), srv.Calls())
+	for _, tm := range managers {
+		token, err := tm.GetToken(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "token-2", token)
+	}
+}
+
+func TestTokenManager_RefreshTokenByAgent_Forces(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	tm := NewTokenManager("corp", "secret", srv.URL, nil, logger.NewNoopLogger())
+	ctx := context.Background()
+
+	_, err := tm.GetToken(ctx)
+	require.NoError(t, err)
+	require.NoError(t, tm.RefreshToken(ctx))
+
+	token, err := tm.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-2", token)
+	assert.Equal(t, int32(2), srv.Calls())
+}
+
+func TestTokenManager_LeaseOutlivesFetch(t *testing.T) {
+	tm := NewTokenManager("corp", "secret", "http://127.0.0.1", NewMemoryCache(), logger.NewNoopLogger())
+	// gettoken 超时前租约不能过期，否则其他实例会同时刷新
+	assert.Greater(t, TokenLockTTL, tm.httpClient.Timeout)
+}
+
+func TestTokenManager_WaitsForLeaseHolder(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	store := NewMemoryCache()
+	tm := NewTokenManager("corp", "secret", srv.URL, store, logger.NewNoopLogger())
+	ctx := context.Background()
+
+	// 模拟另一个实例持有刷新租约
+	locker := store.(cache.Locker)
+	ok, err := locker.TryLock(ctx, tm.cacheKey("")+":lock", "other-instance", TokenLockTTL)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = store.Set(ctx, tm.cacheKey(""), "token-from-other", time.Now().Add(time.Hour))
+		_ = locker.Unlock(ctx, tm.cacheKey("")+":lock", "other-instance")
+	}()
+
+	token, err := tm.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "token-from-other", token)
+	assert.Equal(t, int32(0), srv.Calls())
+}
+
+func TestTokenManager_WaitRespectsContext(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	store := NewMemoryCache()
+	tm := NewTokenManager("corp", "secret", srv.URL, store, logger.NewNoopLogger())
+
+	locker := store.(cache.Locker)
+	_, err := locker.TryLock(context.Background(), tm.cacheKey("")+":lock", "other-instance", TokenLockTTL)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = tm.GetToken(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(0), srv.Calls())
+}
+
+// failingLocker 模拟不可用的锁服务
+type failingLocker struct{}
+
+func (failingLocker) TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return false, errors.New("lock backend unavailable")
+}
+
+func (failingLocker) Unlock(ctx context.Context, key, owner string) error {
+	return nil
+}
+
+func TestTokenManager_LockerFailureFallsBack(t *testing.T) {
+	srv := newFakeTokenServer(t)
+	tm := NewTokenManager("corp", "secret", srv.URL, nil, logger.NewNoopLogger())
+	tm.SetLocker(failingLocker{})
+
+	token, err := tm.GetToken(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "token-1", token)
+}
+
+func TestTokenManager_TokenFetcher(t *testing.T) {
+	var calls int32
+	tm := NewTokenManager("corp", "", "http://127.0.0.1:0", nil, logger.NewNoopLogger())
+	tm.SetTokenFetcher(func(ctx context.Context, agentKey string) (string, int, error) {
+		n := atomic.AddInt32(&calls, 1)
+		return fmt.Sprintf("fetched-%s-%d", agentKey, n), 7200, nil
+	})
+	ctx := context.Background()
+
+	token, err := tm.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fetched--1", token)
+
+	// 使用缓存
+	token, err = tm.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fetched--1", token)
+
+	// 失效后通过自定义函数刷新
+	require.NoError(t, tm.RefreshTokenIfStale(ctx, "", token))
+	token, err = tm.GetToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "fetched--2", token)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	assert.Equal(t, []string{""}, tm.agentKeys())
+}
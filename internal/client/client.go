@@ -1,6 +1,7 @@
 package client
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
@@ -12,7 +13,12 @@ import (
 	"github.com/shuaidd/wecom-core/internal/auth"
 	"github.com/shuaidd/wecom-core/internal/errors"
 	"github.com/shuaidd/wecom-core/internal/retry"
+	"github.com/shuaidd/wecom-core/pkg/breaker"
 	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/ratelimit"
+	"github.com/shuaidd/wecom-core/pkg/redact"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
 )
 
 // contextKey 用于在 context 中存储值的类型
@@ -25,6 +31,8 @@ const (
 	agentNameKey contextKey = "agent_name"
 	// agentIDKey 应用ID的 context key
 	agentIDKey contextKey = "agent_id"
+	// idempotentKey 调用方声明请求幂等的 context key
+	idempotentKey contextKey = "idempotent"
 )
 
 // WithTraceID 将 TraceId 添加到 context
@@ -53,6 +61,26 @@ func WithAgentID(ctx context.Context, agentID int64) context.Context {
 	return context.WithValue(ctx, agentIDKey, agentID)
 }
 
+// WithIdempotent 声明请求可以安全地重复发送
+// 请求已发出但未收到响应（超时、连接中断）时，默认只重试 GET 请求
+func WithIdempotent(ctx context.Context) context.Context {
+	return context.WithValue(ctx, idempotentKey, true)
+}
+
+// isIdempotent 判断请求是否幂等：GET 请求或通过 WithIdempotent 声明
+func isIdempotent(ctx context.Context, method HTTPMethod) bool {
+	if method == MethodGet {
+		return true
+	}
+	idempotent, _ := ctx.Value(idempotentKey).(bool)
+	return idempotent
+}
+
+// AgentKeyFromContext 从 context 中获取通过 WithAgentName/WithAgentID 指定的应用标识
+func AgentKeyFromContext(ctx context.Context) string {
+	return getAgentKey(ctx)
+}
+
 // getAgentKey 从 context 中获取应用标识（优先使用名称，其次使用ID）
 func getAgentKey(ctx context.Context) string {
 	if ctx == nil {
@@ -80,28 +108,80 @@ type Client struct {
 	baseURL string
 	// logger 日志记录器
 	logger logger.Logger
-	// tokenManager Token管理器
-	tokenManager *auth.TokenManager
+	// tokenSource access_token 来源
+	tokenSource auth.TokenSource
 	// retryExecutor 重试执行器
 	retryExecutor *retry.Executor
 	// interceptors 拦截器
 	interceptors *Interceptors
 	// debug 是否打印请求和响应详情
 	debug bool
+	// limiter 客户端限流器（可选）
+	limiter *ratelimit.Limiter
+	// breaker 熔断器（可选）
+	breaker *breaker.Breaker
+	// removeBreakerListener 移除注册到熔断器的状态变化回调
+	removeBreakerListener func()
+	// observer 可观测性钩子，默认为 NoopObserver
+	observer observer.Observer
+	// redactor debug 模式下请求与响应体的脱敏器
+	redactor *redact.Redactor
 }
 
 // New 创建HTTP客户端
-func New(baseURL string, timeout time.Duration, log logger.Logger, tm *auth.TokenManager, re *retry.Executor) *Client {
+// ts 为 nil 时请求不携带 access_token，用于群机器人等以 key 鉴权的接口
+func New(baseURL string, timeout time.Duration, log logger.Logger, ts auth.TokenSource, re *retry.Executor) *Client {
 	return &Client{
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
 		baseURL:       baseURL,
 		logger:        log,
-		tokenManager:  tm,
+		tokenSource:   ts,
 		retryExecutor: re,
 		interceptors:  NewInterceptors(),
 		debug:         false,
+		observer:      observer.NoopObserver{},
+		redactor:      redact.New(),
+	}
+}
+
+// SetTransport 设置 HTTP 传输层
+func (c *Client) SetTransport(rt http.RoundTripper) *Client {
+	c.httpClient.Transport = rt
+	return c
+}
+
+// SetRedactor 设置 debug 模式下请求与响应体的脱敏器
+// URL 与错误中的 access_token 等凭证总是会被脱敏
+func (c *Client) SetRedactor(r *redact.Redactor) *Client {
+	if r != nil {
+		c.redactor = r
+	}
+	return c
+}
+
+// SetObserver 设置可观测性钩子
+func (c *Client) SetObserver(o observer.Observer) *Client {
+	if o == nil {
+		o = observer.NoopObserver{}
+	}
+	c.observer = o
+	return c
+}
+
+// observeRequest 通知 Observer 请求尝试开始，返回其 context 与请求结束时调用的函数
+func (c *Client) observeRequest(ctx context.Context, req *observer.Request) (context.Context, func(statusCode int, err error)) {
+	req.TraceID = getTraceID(ctx)
+	ctx = c.observer.RequestStart(ctx, req)
+	start := time.Now()
+	return ctx, func(statusCode int, err error) {
+		c.observer.RequestFinish(ctx, req, &observer.Result{
+			StatusCode: statusCode,
+			ErrCode:    errors.GetErrorCode(err),
+			Err:        err,
+			Duration:   time.Since(start),
+		})
 	}
 }
 
@@ -111,6 +191,138 @@ func (c *Client) SetDebug(debug bool) *Client {
 	return c
 }
 
+// SetRateLimiter 设置客户端限流器
+// 每次请求（包括重试）前获取配额，收到 45009 时收紧配额
+func (c *Client) SetRateLimiter(l *ratelimit.Limiter) *Client {
+	c.limiter = l
+	return c
+}
+
+// SetCircuitBreaker 设置熔断器
+// 每次请求（包括重试）前检查熔断状态，熔断器打开时立即返回 ErrCircuitOpen 且不再重试
+// 状态变化会记录日志并通知 observer，多个客户端共享同一个熔断器时每个客户端只处理本企业的状态变化
+// 客户端不再使用时需调用 Close 移除注册到熔断器的回调
+func (c *Client) SetCircuitBreaker(b *breaker.Breaker) *Client {
+	if c.removeBreakerListener != nil {
+		c.removeBreakerListener()
+	}
+	c.breaker = b
+	c.removeBreakerListener = b.OnStateChange(func(key breaker.Key, from, to breaker.State) {
+		if key.CorpID != c.corpID() {
+			return
+		}
+		fields := []logger.Field{
+			logger.F("corp_id", key.CorpID),
+			logger.F("path", key.Path),
+			logger.F("agent_key", key.AgentKey),
+			logger.F("from", from.String()),
+			logger.F("to", to.String()),
+		}
+		if to == breaker.StateOpen {
+			c.logger.Warn("Circuit breaker opened", fields...)
+		} else {
+			c.logger.Info("Circuit breaker state changed", fields...)
+		}
+		c.observer.CircuitStateChanged(key, from, to)
+	})
+	return c
+}
+
+// Close 释放客户端注册到共享组件上的资源，如熔断器的状态变化回调
+func (c *Client) Close() {
+	if c.removeBreakerListener != nil {
+		c.removeBreakerListener()
+		c.removeBreakerListener = nil
+	}
+}
+
+// circuitCall 熔断器放行的一次请求
+type circuitCall struct {
+	breaker *breaker.Breaker
+	key     breaker.Key
+	sent    bool
+}
+
+// send 标记请求已发送，之后的结果计入熔断统计
+func (cc *circuitCall) send() {
+	cc.sent = true
+}
+
+// done 记录请求结果，请求未发送时（获取 token、构建请求或拦截器出错）只释放放行名额
+func (cc *circuitCall) done(err error) {
+	switch {
+	case cc.breaker == nil:
+	case cc.sent:
+		cc.breaker.Record(cc.key, err)
+	default:
+		cc.breaker.Cancel(cc.key)
+	}
+}
+
+// allowCircuit 检查熔断状态，放行时返回记录请求结果的 circuitCall
+func (c *Client) allowCircuit(ctx context.Context, agentKey, path string) (*circuitCall, error) {
+	if c.breaker == nil {
+		return &circuitCall{}, nil
+	}
+	key := breaker.Key{CorpID: c.corpID(), AgentKey: agentKey, Path: path}
+	if err := c.breaker.Allow(key); err != nil {
+		c.logger.Warn("Request rejected by circuit breaker", withTraceID(ctx,
+			logger.F("path", path),
+			logger.F("agent_key", agentKey),
+			logger.F("error", err))...)
+		return nil, err
+	}
+	return &circuitCall{breaker: c.breaker, key: key}, nil
+}
+
+// rateLimitKey 生成请求的限流维度
+func (c *Client) rateLimitKey(agentKey, path string) ratelimit.Key {
+	return ratelimit.Key{CorpID: c.corpID(), AgentKey: agentKey, Path: path}
+}
+
+// corpID 返回限流与熔断使用的企业标识
+// TokenSource 实现了 CorpID 方法（如 TokenManager）时按企业区分，否则为空
+func (c *Client) corpID() string {
+	if corp, ok := c.tokenSource.(interface{ CorpID() string }); ok {
+		return corp.CorpID()
+	}
+	return ""
+}
+
+// waitRateLimit 获取请求配额
+func (c *Client) waitRateLimit(ctx context.Context, agentKey, path string) error {
+	if c.limiter == nil {
+		return nil
+	}
+	if err := c.limiter.Wait(ctx, c.rateLimitKey(agentKey, path)); err != nil {
+		c.logger.Warn("Request rate limited by client", withTraceID(ctx,
+			logger.F("path", path),
+			logger.F("agent_key", agentKey),
+			logger.F("error", err))...)
+		return err
+	}
+	return nil
+}
+
+// penalizeRateLimit 收到频率限制错误时收紧配额
+func (c *Client) penalizeRateLimit(agentKey, path string, err error) {
+	if c.limiter != nil && errors.IsRateLimited(err) {
+		c.limiter.Penalize(c.rateLimitKey(agentKey, path))
+	}
+}
+
+// accessToken 获取应用的 access_token，未配置 TokenSource 时返回空字符串
+func (c *Client) accessToken(ctx context.Context, agentKey string) (string, error) {
+	if c.tokenSource == nil {
+		return "", nil
+	}
+	token, err := c.tokenSource.GetTokenByAgent(ctx, agentKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to get access token: %w", err)
+	}
+	return token, nil
+}
+
 // AddRequestInterceptor 添加请求拦截器
 func (c *Client) AddRequestInterceptor(interceptor RequestInterceptor) *Client {
 	c.interceptors.AddRequestInterceptor(interceptor)
@@ -132,29 +344,64 @@ func (c *Client) AddAfterResponseInterceptor(interceptor AfterResponseIntercepto
 // Do 执行HTTP请求（带自动 token 和重试）
 func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 	var resp *Response
+	attempt := 0
 
 	// 使用重试策略执行请求
-	err := c.retryExecutor.Do(ctx, func() error {
+	err := c.retryExecutor.DoRequest(ctx, wecomretry.Attempt{
+		Method:     string(req.Method),
+		Path:       req.Path,
+		AgentKey:   getAgentKey(ctx),
+		Idempotent: isIdempotent(ctx, req.Method),
+	}, func() (err error) {
+		attempt++
+
 		// 1. 从 context 获取应用标识
 		agentKey := getAgentKey(ctx)
 
-		// 2. 获取 access_token（根据应用标识）
-		token, err := c.tokenManager.GetTokenByAgent(ctx, agentKey)
+		// 1.1. 客户端限流
+		if err := c.waitRateLimit(ctx, agentKey, req.Path); err != nil {
+			return err
+		}
+
+		// 1.2. 熔断检查，放行的请求发送后记录结果
+		circuit, err := c.allowCircuit(ctx, agentKey, req.Path)
 		if err != nil {
-			return fmt.Errorf("failed to get access token: %w", err)
+			return err
+		}
+		defer func() { circuit.done(err) }()
+
+		// 2. 获取 access_token（根据应用标识），未配置 TokenSource 时不携带 token（如群机器人）
+		token, err := c.accessToken(ctx, agentKey)
+		if err != nil {
+			return err
+		}
+
+		// 3. 添加 token 到请求（重试时覆盖上一次使用的 token）
+		if token != "" {
+			req.SetQuery("access_token", token)
 		}
 
-		// 3. 添加 token 到请求
-		req.AddQuery("access_token", token)
+		// 3.1. 通知 Observer 请求开始，之后使用其返回的 context（可携带追踪 span）
+		ctx, finish := c.observeRequest(ctx, &observer.Request{
+			Method:   string(req.Method),
+			Path:     req.Path,
+			AgentKey: agentKey,
+			Attempt:  attempt,
+		})
+		statusCode := 0
+		defer func() { finish(statusCode, err) }()
 
 		// 4. 构建 HTTP 请求
 		httpReq, err := req.BuildHTTPRequest(ctx, c.baseURL)
 		if err != nil {
-			return fmt.Errorf("failed to build http request: %w", err)
+			return fmt.Errorf("failed to build http request: %w", redact.Error(err))
 		}
 
 		// 4.1. 执行请求前拦截器
 		if err := c.interceptors.executeRequestInterceptors(ctx, httpReq, req.Body); err != nil {
+			if httpReq.Body != nil {
+				httpReq.Body.Close()
+			}
 			c.logger.Error("Request interceptor failed", withTraceID(ctx,
 				logger.F("error", err))...)
 			return fmt.Errorf("request interceptor failed: %w", err)
@@ -164,7 +411,7 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 		startTime := time.Now()
 		c.logger.Debug("API Request", withTraceID(ctx,
 			logger.F("method", httpReq.Method),
-			logger.F("url", httpReq.URL.String()),
+			logger.F("url", redact.URL(httpReq.URL)),
 			logger.F("agent_key", agentKey))...)
 
 		// 5.1. Debug模式：打印请求详情
@@ -173,8 +420,11 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 		}
 
 		// 6. 发送请求
+		circuit.send()
 		httpResp, err := c.httpClient.Do(httpReq)
 		if err != nil {
+			// 错误中的 URL 包含 access_token
+			err = redact.Error(err)
 			duration := time.Since(startTime)
 			c.logger.Error("Request failed", withTraceID(ctx,
 				logger.F("error", err),
@@ -182,6 +432,7 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 			return fmt.Errorf("http request failed: %w", err)
 		}
 		defer httpResp.Body.Close()
+		statusCode = httpResp.StatusCode
 
 		// 6.1. 执行响应前拦截器（解析前）
 		if err := c.interceptors.executeResponseInterceptors(ctx, httpResp); err != nil {
@@ -194,6 +445,16 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 		resp, err = ParseResponse(httpResp)
 		duration := time.Since(startTime)
 
+		// 7.0. 响应体读取或解析失败（如读取超时）
+		if resp == nil {
+			c.logger.Error("Failed to parse response", withTraceID(ctx,
+				logger.F("url", redact.URL(httpReq.URL)),
+				logger.F("status_code", httpResp.StatusCode),
+				logger.F("error", err),
+				logger.F("duration", duration))...)
+			return err
+		}
+
 		// 7.1. Debug模式：打印响应详情
 		if c.debug {
 			c.logResponseDetails(ctx, httpResp.StatusCode, resp)
@@ -201,17 +462,20 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 
 		if err != nil {
 			c.logger.Error("Request failed", withTraceID(ctx,
-				logger.F("url", httpReq.URL.String()),
+				logger.F("url", redact.URL(httpReq.URL)),
 				logger.F("errcode", resp.ErrCode),
 				logger.F("errmsg", resp.ErrMsg),
 				logger.F("duration", duration))...)
 
+			// 7.2. 频率限制，收紧客户端配额
+			c.penalizeRateLimit(agentKey, req.Path, err)
+
 			// 8. Token 失效，刷新后重试
-			if errors.IsTokenExpired(err) {
+			if errors.IsTokenExpired(err) && c.tokenSource != nil {
 				c.logger.Warn("Token expired, refreshing", withTraceID(ctx,
 					logger.F("errcode", resp.ErrCode),
 					logger.F("agent_key", agentKey))...)
-				if refreshErr := c.tokenManager.RefreshTokenByAgent(ctx, agentKey); refreshErr != nil {
+				if refreshErr := c.tokenSource.RefreshTokenIfStale(ctx, agentKey, token); refreshErr != nil {
 					c.logger.Error("Failed to refresh token", withTraceID(ctx,
 						logger.F("error", refreshErr),
 						logger.F("agent_key", agentKey))...)
@@ -223,7 +487,7 @@ func (c *Client) Do(ctx context.Context, req *Request) (*Response, error) {
 
 		// 9. 记录成功日志
 		c.logger.Info("API Request successful", withTraceID(ctx,
-			logger.F("url", httpReq.URL.String()),
+			logger.F("url", redact.URL(httpReq.URL)),
 			logger.F("duration", duration))...)
 
 		// 9.1. 执行响应后拦截器（解析后）
@@ -288,137 +552,34 @@ func PostAndUnmarshal[T any](c *Client, ctx context.Context, path string, body a
 	return DoAndUnmarshal[T](c, ctx, req)
 }
 
-// PostMultipart 发送multipart/form-data POST请求
-func (c *Client) PostMultipart(ctx context.Context, path string, query url.Values, body []byte, contentType string) (*Response, error) {
-	req := NewMultipartRequest(path, body, contentType)
-	if query != nil {
-		req.Query = query
-	}
-	return c.Do(ctx, req)
-}
-
-// PostMultipartAndUnmarshal 发送multipart/form-data POST请求并自动解析响应
-func PostMultipartAndUnmarshal[T any](c *Client, ctx context.Context, path string, body []byte, contentType string) (*T, error) {
-	resp, err := c.PostMultipart(ctx, path, nil, body, contentType)
-	if err != nil {
-		return nil, err
-	}
-
-	var result T
-	if err := resp.Unmarshal(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
-}
-
-// PostMultipartAndUnmarshalWithQuery 发送带查询参数的multipart/form-data POST请求并自动解析响应
-func PostMultipartAndUnmarshalWithQuery[T any](c *Client, ctx context.Context, path string, query url.Values, body []byte, contentType string) (*T, error) {
-	resp, err := c.PostMultipart(ctx, path, query, body, contentType)
-	if err != nil {
-		return nil, err
-	}
-
-	var result T
-	if err := resp.Unmarshal(&result); err != nil {
-		return nil, err
-	}
-
-	return &result, nil
-}
-
-// GetMedia 下载媒体文件
+// GetMedia 下载媒体文件，整个文件读入内存
+// 大文件请使用 DownloadMedia 或 OpenMedia 流式下载
 func (c *Client) GetMedia(ctx context.Context, path string, query url.Values, headers map[string]string) ([]byte, error) {
 	var result []byte
+	attempt := 0
+	req := &MediaRequest{Path: path, Query: query}
 
 	// 使用重试策略执行请求
-	err := c.retryExecutor.Do(ctx, func() error {
-		// 1. 从 context 获取应用标识
-		agentKey := getAgentKey(ctx)
-
-		// 2. 获取 access_token（根据应用标识）
-		token, err := c.tokenManager.GetTokenByAgent(ctx, agentKey)
-		if err != nil {
-			return fmt.Errorf("failed to get access token: %w", err)
-		}
-
-		// 3. 添加 token 到查询参数
-		if query == nil {
-			query = url.Values{}
-		}
-		query.Set("access_token", token)
-
-		// 4. 构建完整URL
-		u, err := url.Parse(c.baseURL)
-		if err != nil {
-			return fmt.Errorf("invalid base URL: %w", err)
-		}
-		u.Path = path
-		u.RawQuery = query.Encode()
-
-		// 5. 创建HTTP请求
-		httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
-		if err != nil {
-			return fmt.Errorf("failed to create http request: %w", err)
-		}
-
-		// 6. 添加自定义headers（如Range）
-		for key, value := range headers {
-			httpReq.Header.Set(key, value)
-		}
-
-		// 7. 记录请求日志
+	err := c.retryExecutor.DoRequest(ctx, wecomretry.Attempt{
+		Method:     http.MethodGet,
+		Path:       path,
+		AgentKey:   getAgentKey(ctx),
+		Idempotent: true,
+	}, func() (err error) {
+		attempt++
+
+		// 1. 发送请求（限流、熔断、access_token 与错误响应由 openMedia 处理）
 		startTime := time.Now()
-		c.logger.Debug("API Request (Media)", withTraceID(ctx,
-			logger.F("method", httpReq.Method),
-			logger.F("url", httpReq.URL.String()),
-			logger.F("agent_key", agentKey))...)
-
-		// 8. 发送请求
-		httpResp, err := c.httpClient.Do(httpReq)
+		httpResp, finish, err := c.openMedia(ctx, req, headers, "", attempt)
 		if err != nil {
-			duration := time.Since(startTime)
-			c.logger.Error("Media request failed", withTraceID(ctx,
-				logger.F("error", err),
-				logger.F("duration", duration))...)
-			return fmt.Errorf("http request failed: %w", err)
+			return err
 		}
+		defer func() { finish(err) }()
 		defer httpResp.Body.Close()
 
-		duration := time.Since(startTime)
-
-		// 9. 检查HTTP状态码
-		if httpResp.StatusCode != http.StatusOK && httpResp.StatusCode != http.StatusPartialContent {
-			// 尝试解析错误响应
-			var errResp Response
-			body, _ := io.ReadAll(httpResp.Body)
-			if jsonErr := json.Unmarshal(body, &errResp); jsonErr == nil && errResp.ErrCode != 0 {
-				c.logger.Error("Media request failed", withTraceID(ctx,
-					logger.F("url", httpReq.URL.String()),
-					logger.F("errcode", errResp.ErrCode),
-					logger.F("errmsg", errResp.ErrMsg),
-					logger.F("duration", duration))...)
-
-				// Token 失效，刷新后重试
-				apiErr := errors.New(errResp.ErrCode, errResp.ErrMsg)
-				if errors.IsTokenExpired(apiErr) {
-					c.logger.Warn("Token expired, refreshing", withTraceID(ctx,
-						logger.F("errcode", errResp.ErrCode),
-						logger.F("agent_key", agentKey))...)
-					if refreshErr := c.tokenManager.RefreshTokenByAgent(ctx, agentKey); refreshErr != nil {
-						c.logger.Error("Failed to refresh token", withTraceID(ctx,
-							logger.F("error", refreshErr),
-							logger.F("agent_key", agentKey))...)
-					}
-				}
-
-				return apiErr
-			}
-			return fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
-		}
-
-		// 10. 读取响应体
+		// 2. 读取响应体
 		result, err = io.ReadAll(httpResp.Body)
+		duration := time.Since(startTime)
 		if err != nil {
 			c.logger.Error("Failed to read media response", withTraceID(ctx,
 				logger.F("error", err),
@@ -426,9 +587,9 @@ func (c *Client) GetMedia(ctx context.Context, path string, query url.Values, he
 			return fmt.Errorf("failed to read response body: %w", err)
 		}
 
-		// 11. 记录成功日志
+		// 3. 记录成功日志
 		c.logger.Info("Media request successful", withTraceID(ctx,
-			logger.F("url", httpReq.URL.String()),
+			logger.F("path", path),
 			logger.F("size", len(result)),
 			logger.F("duration", duration))...)
 
@@ -446,16 +607,16 @@ func (c *Client) GetMedia(ctx context.Context, path string, query url.Values, he
 func (c *Client) logRequestDetails(ctx context.Context, httpReq *http.Request, body any) {
 	c.logger.Info("==> Request Details", withTraceID(ctx,
 		logger.F("method", httpReq.Method),
-		logger.F("url", httpReq.URL.String()))...)
+		logger.F("url", redact.URL(httpReq.URL)))...)
 
 	if body != nil {
-		bodyJSON, err := json.MarshalIndent(body, "", "  ")
+		bodyJSON, err := c.redactor.Value(body)
 		if err != nil {
 			c.logger.Warn("Failed to marshal request body", withTraceID(ctx,
 				logger.F("error", err))...)
 		} else {
 			c.logger.Info("Request Body", withTraceID(ctx,
-				logger.F("body", string(bodyJSON)))...)
+				logger.F("body", indentJSON(bodyJSON)))...)
 		}
 	}
 }
@@ -468,16 +629,18 @@ func (c *Client) logResponseDetails(ctx context.Context, statusCode int, resp *R
 		logger.F("errmsg", resp.ErrMsg))...)
 
 	if len(resp.Body) > 0 {
-		var prettyJSON map[string]any
-		if err := json.Unmarshal(resp.Body, &prettyJSON); err == nil {
-			bodyJSON, _ := json.MarshalIndent(prettyJSON, "", "  ")
-			c.logger.Info("Response Body", withTraceID(ctx,
-				logger.F("body", string(bodyJSON)))...)
-		} else {
-			c.logger.Info("Response Body", withTraceID(ctx,
-				logger.F("body", string(resp.Body)))...)
-		}
+		c.logger.Info("Response Body", withTraceID(ctx,
+			logger.F("body", indentJSON(c.redactor.JSON(resp.Body))))...)
+	}
+}
+
+// indentJSON 格式化 JSON，不是 JSON 时原样返回
+func indentJSON(data []byte) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return string(data)
 	}
+	return buf.String()
 }
 
 // withTraceID 为日志字段添加 TraceId
@@ -0,0 +1,424 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shuaidd/wecom-core/internal/errors"
+	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/redact"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
+	"github.com/shuaidd/wecom-core/types/common"
+)
+
+// DefaultMediaChunkSize 流式下载默认的分块大小
+const DefaultMediaChunkSize int64 = 4 << 20
+
+// MediaRequest 流式下载请求
+type MediaRequest struct {
+	// Path 接口路径，如 /cgi-bin/media/get，请求携带 access_token
+	Path string
+	// Query 查询参数
+	Query url.Values
+	// URL 完整下载地址（如微盘、会议录制的下载地址），设置后忽略 Path 与 Query，
+	// 请求不携带 access_token，也不经过客户端限流与熔断
+	URL string
+	// Headers 额外的请求头，如微盘下载需要的 Cookie
+	Headers map[string]string
+	// ChunkSize 每个 Range 请求的字节数，为 0 时使用 DefaultMediaChunkSize，小于 0 时不分块
+	ChunkSize int64
+}
+
+// DownloadMedia 流式下载媒体文件并写入 w，返回文件信息
+// 文件按 ChunkSize 拆分为多个 Range 请求，每个请求单独受客户端超时约束；
+// 网络错误时按重试策略从已写入 w 的位置继续下载，不会重复写入；
+// 服务端返回 Content-Range 时校验各分块的起始位置与文件总大小
+func (c *Client) DownloadMedia(ctx context.Context, req *MediaRequest, w io.Writer) (*common.MediaInfo, error) {
+	d := &mediaDownload{client: c, req: req, w: w}
+	if err := d.run(ctx); err != nil {
+		return nil, err
+	}
+	return d.info, nil
+}
+
+// OpenMedia 以 io.ReadCloser 的形式流式读取媒体文件，分块与断点续传同 DownloadMedia
+// 收到第一个响应后返回文件信息，之后的下载错误在 Read 时返回；读取完毕或提前放弃时需调用 Close
+func (c *Client) OpenMedia(ctx context.Context, req *MediaRequest) (*common.MediaInfo, io.ReadCloser, error) {
+	pr, pw := io.Pipe()
+	infoCh := make(chan *common.MediaInfo, 1)
+	errCh := make(chan error, 1)
+
+	d := &mediaDownload{client: c, req: req, w: pw, onInfo: func(info *common.MediaInfo) {
+		infoCh <- info
+	}}
+	go func() {
+		err := d.run(ctx)
+		errCh <- err
+		_ = pw.CloseWithError(err)
+	}()
+
+	select {
+	case info := <-infoCh:
+		return info, pr, nil
+	case err := <-errCh:
+		return nil, nil, err
+	}
+}
+
+// mediaDownload 一次流式下载的状态
+type mediaDownload struct {
+	client *Client
+	req    *MediaRequest
+	w      io.Writer
+	onInfo func(*common.MediaInfo)
+
+	// info 文件信息，收到第一个响应后设置
+	info *common.MediaInfo
+	// offset 已写入 w 的字节数
+	offset  int64
+	chunks  int
+	attempt int
+	done    bool
+}
+
+// run 依次下载各分块，每个分块单独按重试策略重试
+func (d *mediaDownload) run(ctx context.Context) error {
+	path := d.req.Path
+	if d.req.URL != "" {
+		path = redactedPath(d.req.URL)
+	}
+
+	startTime := time.Now()
+	for !d.done {
+		err := d.client.retryExecutor.DoRequest(ctx, wecomretry.Attempt{
+			Method:     http.MethodGet,
+			Path:       path,
+			AgentKey:   getAgentKey(ctx),
+			Idempotent: true,
+		}, func() error {
+			return d.fetch(ctx)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	d.client.logger.Info("Media download successful", withTraceID(ctx,
+		logger.F("path", path),
+		logger.F("size", d.offset),
+		logger.F("chunks", d.chunks),
+		logger.F("duration", time.Since(startTime)))...)
+	return nil
+}
+
+// fetch 下载当前位置开始的一个分块并写入 w
+func (d *mediaDownload) fetch(ctx context.Context) (err error) {
+	d.attempt++
+
+	chunkSize := d.req.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = DefaultMediaChunkSize
+	}
+	rangeHeader := ""
+	switch {
+	case chunkSize > 0:
+		end := d.offset + chunkSize - 1
+		if d.info != nil && d.info.Size >= 0 {
+			end = min(end, d.info.Size-1)
+		}
+		rangeHeader = fmt.Sprintf("bytes=%d-%d", d.offset, end)
+	case d.offset > 0:
+		rangeHeader = fmt.Sprintf("bytes=%d-", d.offset)
+	}
+
+	resp, finish, err := d.client.openMedia(ctx, d.req, d.req.Headers, rangeHeader, d.attempt)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		resp.Body.Close()
+		finish(err)
+	}()
+
+	info := &common.MediaInfo{
+		Filename:    contentFilename(resp.Header.Get("Content-Disposition")),
+		ContentType: resp.Header.Get("Content-Type"),
+		Size:        resp.ContentLength,
+	}
+	// expected 本次响应应写入 w 的字节数，未知时为 -1
+	expected := resp.ContentLength
+	partial := resp.StatusCode == http.StatusPartialContent
+	if partial {
+		start, end, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok {
+			return fmt.Errorf("invalid Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		if start != d.offset {
+			return fmt.Errorf("unexpected Content-Range start %d, want %d", start, d.offset)
+		}
+		info.Size = total
+		expected = end - start + 1
+	}
+
+	switch {
+	case d.info == nil:
+		d.info = info
+		if d.onInfo != nil {
+			d.onInfo(info)
+		}
+	case d.info.Size < 0:
+		d.info.Size = info.Size
+	case info.Size >= 0 && info.Size != d.info.Size:
+		return fmt.Errorf("media size changed from %d to %d bytes during download", d.info.Size, info.Size)
+	}
+
+	body := io.Reader(resp.Body)
+	if !partial && d.offset > 0 {
+		// 服务端忽略了 Range，跳过已写入的部分
+		if _, err := io.CopyN(io.Discard, body, d.offset); err != nil {
+			return fmt.Errorf("failed to skip downloaded bytes: %w", err)
+		}
+		if expected >= 0 {
+			expected -= d.offset
+		}
+	}
+
+	n, err := io.Copy(d.w, body)
+	d.offset += n
+	if err != nil {
+		return fmt.Errorf("failed to read media body: %w", err)
+	}
+	if expected >= 0 && n < expected {
+		return fmt.Errorf("failed to read media body: %w", io.ErrUnexpectedEOF)
+	}
+	d.chunks++
+
+	// 判断是否下载完成
+	switch {
+	case d.info.Size >= 0 && d.offset > d.info.Size:
+		return fmt.Errorf("media size mismatch: received %d bytes, Content-Range reports %d", d.offset, d.info.Size)
+	case !partial:
+		if d.info.Size >= 0 && d.offset != d.info.Size {
+			return fmt.Errorf("media size mismatch: received %d bytes, want %d", d.offset, d.info.Size)
+		}
+		d.info.Size = d.offset
+		d.done = true
+	case d.info.Size >= 0:
+		d.done = d.offset == d.info.Size
+	default:
+		// 总大小未知（bytes x-y/*），返回的分块小于请求的大小时视为结束
+		d.done = n < chunkSize
+		if d.done {
+			d.info.Size = d.offset
+		}
+	}
+	return nil
+}
+
+// openMedia 发送一次媒体下载请求，返回状态码检查通过的响应
+// 调用方读取并关闭响应体后需调用 finish 记录请求结果（熔断与可观测性）
+func (c *Client) openMedia(ctx context.Context, req *MediaRequest, headers map[string]string, rangeHeader string, attempt int) (resp *http.Response, finish func(error), err error) {
+	agentKey := getAgentKey(ctx)
+	path := req.Path
+	circuit := &circuitCall{}
+	token := ""
+
+	var u *url.URL
+	if req.URL != "" {
+		u, err = url.Parse(req.URL)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid media URL: %w", redact.Error(err))
+		}
+		path = u.Path
+	} else {
+		// 1. 客户端限流
+		if err := c.waitRateLimit(ctx, agentKey, path); err != nil {
+			return nil, nil, err
+		}
+
+		// 2. 熔断检查，放行的请求发送后记录结果
+		if circuit, err = c.allowCircuit(ctx, agentKey, path); err != nil {
+			return nil, nil, err
+		}
+
+		// 3. 获取 access_token（根据应用标识）
+		if token, err = c.accessToken(ctx, agentKey); err != nil {
+			circuit.done(err)
+			return nil, nil, err
+		}
+
+		// 4. 构建完整URL，复制查询参数避免修改调用方的 Query
+		query := url.Values{}
+		for k, v := range req.Query {
+			query[k] = v
+		}
+		if token != "" {
+			query.Set("access_token", token)
+		}
+		if u, err = url.Parse(c.baseURL); err != nil {
+			circuit.done(err)
+			return nil, nil, fmt.Errorf("invalid base URL: %w", err)
+		}
+		u.Path = path
+		u.RawQuery = query.Encode()
+	}
+
+	// 5. 通知 Observer 请求开始，之后使用其返回的 context（可携带追踪 span）
+	ctx, done := c.observeRequest(ctx, &observer.Request{
+		Method:   http.MethodGet,
+		Path:     path,
+		AgentKey: agentKey,
+		Attempt:  attempt,
+		Media:    true,
+	})
+	statusCode := 0
+	complete := func(err error) {
+		circuit.done(err)
+		done(statusCode, err)
+	}
+	defer func() {
+		if err != nil {
+			complete(err)
+		}
+	}()
+
+	// 6. 创建HTTP请求，添加自定义headers（如Range、Cookie）
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create http request: %w", redact.Error(err))
+	}
+	for key, value := range headers {
+		httpReq.Header.Set(key, value)
+	}
+	if rangeHeader != "" {
+		httpReq.Header.Set("Range", rangeHeader)
+	}
+
+	// 7. 发送请求
+	startTime := time.Now()
+	c.logger.Debug("API Request (Media)", withTraceID(ctx,
+		logger.F("method", httpReq.Method),
+		logger.F("url", redact.URL(httpReq.URL)),
+		logger.F("range", rangeHeader),
+		logger.F("agent_key", agentKey))...)
+
+	circuit.send()
+	httpResp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		// 错误中的 URL 包含 access_token
+		err = redact.Error(err)
+		c.logger.Error("Media request failed", withTraceID(ctx,
+			logger.F("error", err),
+			logger.F("duration", time.Since(startTime)))...)
+		return nil, nil, fmt.Errorf("http request failed: %w", err)
+	}
+	statusCode = httpResp.StatusCode
+
+	// 8. 检查HTTP状态码，企业微信出错时可能返回 HTTP 200 与 JSON 错误
+	isJSON := strings.HasPrefix(httpResp.Header.Get("Content-Type"), "application/json")
+	ok := httpResp.StatusCode == http.StatusOK || httpResp.StatusCode == http.StatusPartialContent
+	if ok && !isJSON {
+		return httpResp, complete, nil
+	}
+
+	body, readErr := io.ReadAll(httpResp.Body)
+	httpResp.Body.Close()
+	if readErr != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", readErr)
+	}
+
+	var errResp Response
+	if jsonErr := json.Unmarshal(body, &errResp); jsonErr == nil && errResp.ErrCode != 0 {
+		c.logger.Error("Media request failed", withTraceID(ctx,
+			logger.F("url", redact.URL(httpReq.URL)),
+			logger.F("errcode", errResp.ErrCode),
+			logger.F("errmsg", errResp.ErrMsg),
+			logger.F("duration", time.Since(startTime)))...)
+
+		// Token 失效，刷新后重试
+		apiErr := errors.New(errResp.ErrCode, errResp.ErrMsg)
+		if req.URL == "" {
+			c.penalizeRateLimit(agentKey, path, apiErr)
+			if errors.IsTokenExpired(apiErr) && c.tokenSource != nil {
+				c.logger.Warn("Token expired, refreshing", withTraceID(ctx,
+					logger.F("errcode", errResp.ErrCode),
+					logger.F("agent_key", agentKey))...)
+				if refreshErr := c.tokenSource.RefreshTokenIfStale(ctx, agentKey, token); refreshErr != nil {
+					c.logger.Error("Failed to refresh token", withTraceID(ctx,
+						logger.F("error", refreshErr),
+						logger.F("agent_key", agentKey))...)
+				}
+			}
+		}
+		return nil, nil, apiErr
+	}
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected status code: %d", httpResp.StatusCode)
+	}
+
+	// JSON 文件本身，恢复已读取的响应体
+	httpResp.Body = io.NopCloser(bytes.NewReader(body))
+	return httpResp, complete, nil
+}
+
+// parseContentRange 解析 Content-Range，格式为 bytes start-end/total，总大小未知（*）时 total 为 -1
+func parseContentRange(v string) (start, end, total int64, ok bool) {
+	rest, found := strings.CutPrefix(v, "bytes ")
+	if !found {
+		return 0, 0, 0, false
+	}
+	rng, size, found := strings.Cut(rest, "/")
+	if !found {
+		return 0, 0, 0, false
+	}
+	s, e, found := strings.Cut(rng, "-")
+	if !found {
+		return 0, 0, 0, false
+	}
+
+	var err error
+	if start, err = strconv.ParseInt(s, 10, 64); err != nil {
+		return 0, 0, 0, false
+	}
+	if end, err = strconv.ParseInt(e, 10, 64); err != nil || end < start {
+		return 0, 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil || total <= end {
+			return 0, 0, 0, false
+		}
+	}
+	return start, end, total, true
+}
+
+// contentFilename 从 Content-Disposition 中获取文件名
+func contentFilename(v string) string {
+	if v == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(v)
+	if err != nil {
+		return ""
+	}
+	return params["filename"]
+}
+
+// redactedPath 返回下载地址中用于日志与重试策略的路径，不包含可能带有签名的查询参数
+func redactedPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Path
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContentRange(t *testing.T) {
+	tests := []struct {
+		value             string
+		start, end, total int64
+		ok                bool
+	}{
+		{"bytes 0-1023/2048", 0, 1023, 2048, true},
+		{"bytes 1024-2047/2048", 1024, 2047, 2048, true},
+		{"bytes 0-99/*", 0, 99, -1, true},
+		{"bytes 0-2048/2048", 0, 0, 0, false},
+		{"bytes 10-5/100", 0, 0, 0, false},
+		{"bytes */2048", 0, 0, 0, false},
+		{"items 0-1/2", 0, 0, 0, false},
+		{"", 0, 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			start, end, total, ok := parseContentRange(tt.value)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.start, start)
+			assert.Equal(t, tt.end, end)
+			assert.Equal(t, tt.total, total)
+		})
+	}
+}
+
+func TestContentFilename(t *testing.T) {
+	assert.Equal(t, "video.mp4", contentFilename(`attachment; filename="video.mp4"`))
+	assert.Equal(t, "视频.mp4", contentFilename(`attachment; filename*=UTF-8''%E8%A7%86%E9%A2%91.mp4`))
+	assert.Empty(t, contentFilename("attachment"))
+	assert.Empty(t, contentFilename(""))
+}
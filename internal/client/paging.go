@@ -0,0 +1,80 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"iter"
+)
+
+// ErrPageNotAdvanced 分页接口声明还有更多数据，但返回的游标未变化
+// 继续请求会陷入死循环，迭代器以此错误结束
+var ErrPageNotAdvanced = errors.New("pagination cursor did not advance")
+
+// Page 分页接口返回的一页数据
+// C 为游标类型：next_cursor 类接口为 string，offset 类接口为数值
+type Page[C comparable, T any] struct {
+	// Items 本页数据
+	Items []T
+	// Next 下一页的游标
+	Next C
+	// HasMore 是否还有更多数据
+	HasMore bool
+}
+
+// PageFetcher 根据游标拉取一页数据
+type PageFetcher[C comparable, T any] func(ctx context.Context, cursor C) (*Page[C, T], error)
+
+// CursorPage 创建以 next_cursor 为空表示结束的分页数据
+func CursorPage[T any](items []T, nextCursor string) *Page[string, T] {
+	return &Page[string, T]{
+		Items:   items,
+		Next:    nextCursor,
+		HasMore: nextCursor != "",
+	}
+}
+
+// Paginate 将分页接口转换为逐条返回数据的迭代器
+// 从 start 游标开始依次拉取，直到没有更多数据、调用方停止迭代、context 取消或出现错误
+// 出现错误时以 (零值, err) 的形式返回一次后结束
+//
+// 示例：
+//
+//	for user, err := range client.Paginate(ctx, "", fetch) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    ...
+//	}
+func Paginate[C comparable, T any](ctx context.Context, start C, fetch PageFetcher[C, T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cursor := start
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			page, err := fetch(ctx, cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range page.Items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if !page.HasMore {
+				return
+			}
+			if page.Next == cursor {
+				yield(zero, ErrPageNotAdvanced)
+				return
+			}
+			cursor = page.Next
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collect 收集迭代器返回的全部数据与第一个错误
+func collect[T any](seq func(func(T, error) bool)) ([]T, error) {
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func TestPaginate_Cursor(t *testing.T) {
+	pages := map[string]*Page[string, int]{
+		"":   CursorPage([]int{1, 2}, "c1"),
+		"c1": CursorPage([]int{3}, "c2"),
+		"c2": CursorPage([]int{4, 5}, ""),
+	}
+	var cursors []string
+	fetch := func(ctx context.Context, cursor string) (*Page[string, int], error) {
+		cursors = append(cursors, cursor)
+		return pages[cursor], nil
+	}
+
+	items, err := collect(Paginate(context.Background(), "", fetch))
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, items)
+	assert.Equal(t, []string{"", "c1", "c2"}, cursors)
+}
+
+func TestPaginate_Offset(t *testing.T) {
+	fetch := func(ctx context.Context, offset uint32) (*Page[uint32, uint32], error) {
+		return &Page[uint32, uint32]{
+			Items:   []uint32{offset, offset + 1},
+			Next:    offset + 2,
+			HasMore: offset < 4,
+		}, nil
+	}
+
+	items, err := collect(Paginate(context.Background(), 0, fetch))
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{0, 1, 2, 3, 4, 5}, items)
+}
+
+func TestPaginate_StopEarly(t *testing.T) {
+	calls := 0
+	fetch := func(ctx context.Context, cursor string) (*Page[string, int], error) {
+		calls++
+		return CursorPage([]int{1, 2, 3}, "next"+cursor), nil
+	}
+
+	count := 0
+	for range Paginate(context.Background(), "", fetch) {
+		count++
+		if count == 4 {
+			break
+		}
+	}
+	assert.Equal(t, 4, count)
+	assert.Equal(t, 2, calls)
+}
+
+func TestPaginate_Error(t *testing.T) {
+	errFetch := errors.New("fetch failed")
+	fetch := func(ctx context.Context, cursor string) (*Page[string, int], error) {
+		if cursor == "" {
+			return CursorPage([]int{1}, "c1"), nil
+		}
+		return nil, errFetch
+	}
+
+	items, err := collect(Paginate(context.Background(), "", fetch))
+	assert.ErrorIs(t, err, errFetch)
+	assert.Equal(t, []int{1}, items)
+}
+
+func TestPaginate_ContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	fetch := func(ctx context.Context, cursor string) (*Page[string, int], error) {
+		cancel()
+		return CursorPage([]int{1}, "c"+cursor), nil
+	}
+
+	items, err := collect(Paginate(ctx, "", fetch))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, []int{1}, items)
+}
+
+func TestPaginate_NotAdvanced(t *testing.T) {
+	fetch := func(ctx context.Context, cursor string) (*Page[string, int], error) {
+		return &Page[string, int]{Items: []int{1}, Next: cursor, HasMore: true}, nil
+	}
+
+	_, err := collect(Paginate(context.Background(), "", fetch))
+	assert.ErrorIs(t, err, ErrPageNotAdvanced)
+}
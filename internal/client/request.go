@@ -30,10 +30,8 @@ type Request struct {
 	Query url.Values
 	// Body 请求体
 	Body any
-	// RawBody 原始请求体（用于multipart）
-	RawBody []byte
-	// ContentType 内容类型（用于multipart）
-	ContentType string
+	// Stream 流式请求体（用于文件上传），设置后忽略 Body
+	Stream *StreamBody
 }
 
 // NewRequest 创建新请求
@@ -51,20 +49,28 @@ func (r *Request) AddQuery(key, value string) *Request {
 	return r
 }
 
+// SetQuery 设置查询参数（覆盖已有的同名参数）
+func (r *Request) SetQuery(key, value string) *Request {
+	if r.Query == nil {
+		r.Query = url.Values{}
+	}
+	r.Query.Set(key, value)
+	return r
+}
+
 // SetBody 设置请求体
 func (r *Request) SetBody(body any) *Request {
 	r.Body = body
 	return r
 }
 
-// NewMultipartRequest 创建multipart/form-data请求
-func NewMultipartRequest(path string, body []byte, contentType string) *Request {
+// NewStreamRequest 创建流式请求体的POST请求
+func NewStreamRequest(path string, body *StreamBody) *Request {
 	return &Request{
-		Method:      MethodPost,
-		Path:        path,
-		Query:       url.Values{},
-		RawBody:     body,
-		ContentType: contentType,
+		Method: MethodPost,
+		Path:   path,
+		Query:  url.Values{},
+		Stream: body,
 	}
 }
 
@@ -81,9 +87,13 @@ func (r *Request) BuildHTTPRequest(ctx context.Context, baseURL string) (*http.R
 	// 构建请求体
 	var body io.Reader
 	if r.Method == MethodPost {
-		if r.RawBody != nil {
-			// 使用原始body（用于multipart）
-			body = bytes.NewReader(r.RawBody)
+		if r.Stream != nil {
+			// 流式请求体（用于文件上传），每次构建请求重新打开
+			stream, err := r.Stream.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open request body: %w", err)
+			}
+			body = stream
 		} else if r.Body != nil {
 			// 使用JSON body
 			jsonData, err := json.Marshal(r.Body)
@@ -97,14 +107,19 @@ func (r *Request) BuildHTTPRequest(ctx context.Context, baseURL string) (*http.R
 	// 创建HTTP请求
 	req, err := http.NewRequestWithContext(ctx, string(r.Method), u.String(), body)
 	if err != nil {
+		if c, ok := body.(io.Closer); ok {
+			c.Close()
+		}
 		return nil, fmt.Errorf("failed to create http request: %w", err)
 	}
 
 	// 设置请求头
 	if r.Method == MethodPost {
-		if r.ContentType != "" {
-			// 使用自定义ContentType（用于multipart）
-			req.Header.Set("Content-Type", r.ContentType)
+		if r.Stream != nil {
+			// 流式请求体的长度已知，不使用 chunked 编码
+			req.Header.Set("Content-Type", r.Stream.ContentType)
+			req.ContentLength = r.Stream.Size
+			req.GetBody = r.Stream.Open
 		} else if r.Body != nil {
 			// 使用JSON ContentType
 			req.Header.Set("Content-Type", "application/json; charset=utf-8")
@@ -0,0 +1,179 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+
+	"github.com/shuaidd/wecom-core/pkg/upload"
+)
+
+// StreamBody 流式请求体
+// 每次请求尝试（包括重试）调用 Open 重新读取数据源，内存占用与文件大小无关
+type StreamBody struct {
+	// Open 打开请求体
+	Open func() (io.ReadCloser, error)
+	// Size 请求体长度（字节）
+	Size int64
+	// ContentType 内容类型
+	ContentType string
+}
+
+// NewMultipartBody 创建只包含一个文件字段的 multipart/form-data 流式请求体
+// 分隔符与文件头在创建时生成，请求体长度 = 文件头 + 文件内容 + 结束分隔符
+func NewMultipartBody(field string, src upload.Source) (*StreamBody, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if _, err := writer.CreateFormFile(field, src.Name()); err != nil {
+		return nil, fmt.Errorf("failed to create form file: %w", err)
+	}
+	head := bytes.Clone(buf.Bytes())
+
+	buf.Reset()
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+	}
+	tail := bytes.Clone(buf.Bytes())
+
+	size := src.Size()
+	return &StreamBody{
+		Open: func() (io.ReadCloser, error) {
+			rc, err := src.Open()
+			if err != nil {
+				return nil, err
+			}
+			return &readCloser{
+				Reader: io.MultiReader(bytes.NewReader(head), exactReader(rc, size), bytes.NewReader(tail)),
+				Closer: rc,
+			}, nil
+		},
+		Size:        int64(len(head)) + size + int64(len(tail)),
+		ContentType: writer.FormDataContentType(),
+	}, nil
+}
+
+// NewBase64JSONBody 创建 JSON 流式请求体，field 字段为数据源内容的 base64 编码，其余字段取自 fields
+// 用于 addcheckinuserface 等以 base64 字符串提交文件的接口
+func NewBase64JSONBody(fields map[string]string, field string, src upload.Source) (*StreamBody, error) {
+	// {"k":"v",...,"field":" + base64 + "}
+	if fields == nil {
+		fields = map[string]string{}
+	}
+	obj, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	name, err := json.Marshal(field)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+	head := bytes.TrimSuffix(obj, []byte("}"))
+	if len(fields) > 0 {
+		head = append(head, ',')
+	}
+	head = append(head, name...)
+	head = append(head, ':', '"')
+	tail := []byte(`"}`)
+
+	size := src.Size()
+	return &StreamBody{
+		Open: func() (io.ReadCloser, error) {
+			rc, err := src.Open()
+			if err != nil {
+				return nil, err
+			}
+			encoded := base64Reader(exactReader(rc, size))
+			return &readCloser{
+				Reader: io.MultiReader(bytes.NewReader(head), encoded, bytes.NewReader(tail)),
+				Closer: closers{encoded, rc},
+			}, nil
+		},
+		Size:        int64(len(head)) + int64(base64.StdEncoding.EncodedLen(int(size))) + int64(len(tail)),
+		ContentType: "application/json; charset=utf-8",
+	}, nil
+}
+
+// PostStream 发送流式请求体的POST请求
+func (c *Client) PostStream(ctx context.Context, path string, query url.Values, body *StreamBody) (*Response, error) {
+	req := NewStreamRequest(path, body)
+	if query != nil {
+		req.Query = query
+	}
+	return c.Do(ctx, req)
+}
+
+// PostStreamAndUnmarshal 发送流式请求体的POST请求并自动解析响应
+func PostStreamAndUnmarshal[T any](c *Client, ctx context.Context, path string, query url.Values, body *StreamBody) (*T, error) {
+	req := NewStreamRequest(path, body)
+	if query != nil {
+		req.Query = query
+	}
+	return DoAndUnmarshal[T](c, ctx, req)
+}
+
+// PostUploadAndUnmarshal 以 multipart/form-data 流式上传文件并自动解析响应
+func PostUploadAndUnmarshal[T any](c *Client, ctx context.Context, path string, query url.Values, field string, src upload.Source) (*T, error) {
+	body, err := NewMultipartBody(field, src)
+	if err != nil {
+		return nil, err
+	}
+	return PostStreamAndUnmarshal[T](c, ctx, path, query, body)
+}
+
+// readCloser 组合 Reader 与 Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// closers 依次关闭多个 Closer
+type closers []io.Closer
+
+func (cs closers) Close() error {
+	var first error
+	for _, c := range cs {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// exactReader 读取 r 的前 size 个字节，数据源不足 size 时返回 io.ErrUnexpectedEOF，避免请求体与声明的长度不一致
+func exactReader(r io.Reader, size int64) io.Reader {
+	return &sizedReader{r: io.LimitReader(r, size), remaining: size}
+}
+
+// sizedReader 校验读取长度的 Reader
+type sizedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (s *sizedReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	s.remaining -= int64(n)
+	if err == io.EOF && s.remaining > 0 {
+		return n, fmt.Errorf("upload source is shorter than its declared size: %w", io.ErrUnexpectedEOF)
+	}
+	return n, err
+}
+
+// base64Reader 返回 r 的 base64 编码流
+func base64Reader(r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		enc := base64.NewEncoder(base64.StdEncoding, pw)
+		_, err := io.Copy(enc, r)
+		if err == nil {
+			err = enc.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
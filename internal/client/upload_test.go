@@ -0,0 +1,108 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"testing"
+
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readStream(t *testing.T, body *StreamBody) []byte {
+	t.Helper()
+	rc, err := body.Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return data
+}
+
+func TestNewMultipartBody(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	body, err := NewMultipartBody("media", upload.Bytes("a.txt", content))
+	require.NoError(t, err)
+
+	// 每次打开都得到完整且长度一致的请求体
+	for i := 0; i < 2; i++ {
+		data := readStream(t, body)
+		assert.Equal(t, body.Size, int64(len(data)))
+
+		_, params, err := mime.ParseMediaType(body.ContentType)
+		require.NoError(t, err)
+		reader := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+		part, err := reader.NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "media", part.FormName())
+		assert.Equal(t, "a.txt", part.FileName())
+		got, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.Equal(t, content, got)
+		_, err = reader.NextPart()
+		assert.ErrorIs(t, err, io.EOF)
+	}
+}
+
+func TestNewMultipartBody_ShortSource(t *testing.T) {
+	src := upload.Func("a.txt", 10, func() (io.Reader, error) {
+		return bytes.NewReader([]byte("short")), nil
+	})
+	body, err := NewMultipartBody("media", src)
+	require.NoError(t, err)
+
+	rc, err := body.Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	_, err = io.ReadAll(rc)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+func TestNewBase64JSONBody(t *testing.T) {
+	content := bytes.Repeat([]byte{0xff, 0x00, 0x7f}, 3001)
+	body, err := NewBase64JSONBody(map[string]string{"userid": "zhangsan"}, "userface", upload.Bytes("face.jpg", content))
+	require.NoError(t, err)
+
+	data := readStream(t, body)
+	assert.Equal(t, body.Size, int64(len(data)))
+
+	var got map[string]string
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, "zhangsan", got["userid"])
+	assert.Equal(t, base64.StdEncoding.EncodeToString(content), got["userface"])
+
+	// 无其他字段
+	body, err = NewBase64JSONBody(nil, "data", upload.Bytes("x", []byte("hi")))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"data":"aGk="}`, string(readStream(t, body)))
+}
+
+func TestBuildHTTPRequest_Stream(t *testing.T) {
+	body, err := NewMultipartBody("media", upload.Bytes("a.txt", []byte("hello")))
+	require.NoError(t, err)
+
+	req := NewStreamRequest("/cgi-bin/media/upload", body)
+	httpReq, err := req.BuildHTTPRequest(context.Background(), "https://qyapi.weixin.qq.com")
+	require.NoError(t, err)
+	assert.Equal(t, body.Size, httpReq.ContentLength)
+	assert.Equal(t, body.ContentType, httpReq.Header.Get("Content-Type"))
+
+	first, err := io.ReadAll(httpReq.Body)
+	require.NoError(t, err)
+	require.NoError(t, httpReq.Body.Close())
+
+	// 重试时通过 GetBody 重新打开数据源
+	require.NotNil(t, httpReq.GetBody)
+	rc, err := httpReq.GetBody()
+	require.NoError(t, err)
+	second, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, first, second)
+}
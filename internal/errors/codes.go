@@ -23,6 +23,9 @@ const (
 	// ErrCodeSystemBusy 系统繁忙
 	ErrCodeSystemBusy = 10001
 
+	// ErrCodeSystemError 系统繁忙（服务器暂不可用）
+	ErrCodeSystemError = -1
+
 	// ErrCodeInvalidParameter 参数错误
 	ErrCodeInvalidParameter = 40003
 
@@ -72,10 +75,10 @@ func IsRateLimited(err error) bool {
 	return code == ErrCodeAPIFreqLimit
 }
 
-// IsSystemBusy 判断是否为系统繁忙错误
+// IsSystemBusy 判断是否为系统繁忙错误（10001、-1）
 func IsSystemBusy(err error) bool {
 	code := GetErrorCode(err)
-	return code == ErrCodeSystemBusy
+	return code == ErrCodeSystemBusy || code == ErrCodeSystemError
 }
 
 // IsRetriable 判断错误是否可重试
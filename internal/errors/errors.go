@@ -68,3 +68,11 @@ func GetErrorCode(err error) int {
 	}
 	return 0
 }
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未发送（客户端错误，没有对应的企业微信错误码）
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// IsCircuitOpen 判断是否为熔断器打开导致的错误
+func IsCircuitOpen(err error) bool {
+	return errors.Is(err, ErrCircuitOpen)
+}
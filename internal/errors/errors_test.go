@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -148,6 +149,11 @@ func TestIsSystemBusy(t *testing.T) {
 			err:      New(ErrCodeSystemBusy, "system busy"),
 			expected: true,
 		},
+		{
+			name:     "system error",
+			err:      New(ErrCodeSystemError, "system error"),
+			expected: true,
+		},
 		{
 			name:     "other error",
 			err:      New(ErrCodeAPIFreqLimit, "api freq out of limit"),
@@ -201,3 +207,10 @@ func TestIsRetriable(t *testing.T) {
 		})
 	}
 }
+
+func TestIsCircuitOpen(t *testing.T) {
+	assert.True(t, IsCircuitOpen(ErrCircuitOpen))
+	assert.True(t, IsCircuitOpen(fmt.Errorf("request failed: %w", ErrCircuitOpen)))
+	assert.False(t, IsCircuitOpen(New(ErrCodeSystemBusy, "system busy")))
+	assert.False(t, IsRetriable(ErrCircuitOpen))
+}
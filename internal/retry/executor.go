@@ -4,72 +4,65 @@ import (
 	"context"
 	"time"
 
-	"github.com/shuaidd/wecom-core/internal/errors"
 	"github.com/shuaidd/wecom-core/pkg/logger"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
 )
 
 // Executor 重试执行器
 type Executor struct {
-	policy *Policy
+	policy wecomretry.Policy
 	logger logger.Logger
 }
 
 // NewExecutor 创建重试执行器
-func NewExecutor(policy *Policy, log logger.Logger) *Executor {
+func NewExecutor(policy wecomretry.Policy, log logger.Logger) *Executor {
 	return &Executor{
 		policy: policy,
 		logger: log,
 	}
 }
 
-// Do 执行函数并在失败时重试
+// Do 执行函数并在失败时重试，fn 视为幂等
 func (e *Executor) Do(ctx context.Context, fn func() error) error {
-	var lastErr error
+	return e.DoRequest(ctx, wecomretry.Attempt{Idempotent: true}, fn)
+}
 
-	for attempt := 0; attempt <= e.policy.MaxRetries; attempt++ {
+// DoRequest 执行请求并在失败时由重试策略决定是否重试
+// req 描述请求（方法、路径、应用、是否幂等），Attempt 与 Err 由执行器填充
+func (e *Executor) DoRequest(ctx context.Context, req wecomretry.Attempt, fn func() error) error {
+	for attempt := 1; ; attempt++ {
 		// 执行函数
 		err := fn()
 		if err == nil {
 			return nil
 		}
 
-		lastErr = err
-
-		// 判断是否需要重试
-		if !e.shouldRetry(err) {
-			e.logger.Info("Error not retriable",
+		// 由重试策略判断是否需要重试
+		a := req
+		a.Attempt = attempt
+		a.Err = err
+		backoff, ok := e.policy.Retry(ctx, &a)
+		if !ok {
+			e.logger.Info("Not retrying",
+				logger.F("attempts", attempt),
+				logger.F("class", wecomretry.Classify(err)),
+				logger.F("idempotent", req.Idempotent),
 				logger.F("error", err))
 			return err
 		}
 
-		// 最后一次尝试失败
-		if attempt == e.policy.MaxRetries {
-			e.logger.Warn("Max retries reached",
-				logger.F("attempts", attempt+1),
-				logger.F("error", err))
-			break
-		}
-
-		// 计算退避时间
-		backoff := e.policy.Backoff(attempt)
 		e.logger.Info("Retrying after backoff",
-			logger.F("attempt", attempt+1),
+			logger.F("attempt", attempt),
 			logger.F("backoff", backoff),
 			logger.F("error", err))
 
 		// 等待
+		timer := time.NewTimer(backoff)
 		select {
-		case <-time.After(backoff):
+		case <-timer.C:
 		case <-ctx.Done():
+			timer.Stop()
 			return ctx.Err()
 		}
 	}
-
-	return lastErr
-}
-
-// shouldRetry 判断错误是否需要重试
-func (e *Executor) shouldRetry(err error) bool {
-	// 使用 errors 包中的判断函数
-	return errors.IsRetriable(err)
 }
@@ -11,6 +11,7 @@ import (
 
 	internalErrors "github.com/shuaidd/wecom-core/internal/errors"
 	"github.com/shuaidd/wecom-core/pkg/logger"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
 )
 
 func TestExecutor_Do_Success(t *testing.T) {
@@ -118,3 +119,62 @@ func TestExecutor_Do_StandardError(t *testing.T) {
 	assert.Equal(t, expectedErr, err)
 	assert.Equal(t, 1, callCount, "should not retry standard error")
 }
+
+// timeoutError 测试用的超时错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestExecutor_DoRequest_Idempotency(t *testing.T) {
+	policy := NewPolicy(3, time.Millisecond, 10*time.Millisecond)
+	executor := NewExecutor(policy, logger.NewNoopLogger())
+
+	callCount := 0
+	err := executor.DoRequest(context.Background(), wecomretry.Attempt{Method: "POST"}, func() error {
+		callCount++
+		return timeoutError{}
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, callCount, "should not retry non-idempotent request after it was sent")
+
+	callCount = 0
+	err = executor.DoRequest(context.Background(), wecomretry.Attempt{Method: "POST", Idempotent: true}, func() error {
+		callCount++
+		if callCount < 2 {
+			return timeoutError{}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, callCount)
+}
+
+// recordingPolicy 记录策略收到的尝试
+type recordingPolicy struct {
+	attempts []wecomretry.Attempt
+}
+
+func (p *recordingPolicy) Retry(_ context.Context, a *wecomretry.Attempt) (time.Duration, bool) {
+	p.attempts = append(p.attempts, *a)
+	return 0, a.Attempt < 3
+}
+
+func TestExecutor_DoRequest_Policy(t *testing.T) {
+	policy := &recordingPolicy{}
+	executor := NewExecutor(policy, logger.NewNoopLogger())
+
+	expectedErr := errors.New("standard error")
+	err := executor.DoRequest(context.Background(), wecomretry.Attempt{Method: "GET", Path: "/cgi-bin/user/get"}, func() error {
+		return expectedErr
+	})
+
+	require.Equal(t, expectedErr, err)
+	require.Len(t, policy.attempts, 3)
+	for i, a := range policy.attempts {
+		assert.Equal(t, i+1, a.Attempt)
+		assert.Equal(t, "/cgi-bin/user/get", a.Path)
+		assert.Equal(t, expectedErr, a.Err)
+	}
+}
@@ -2,33 +2,14 @@ package retry
 
 import (
 	"time"
+
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
 )
 
-// Policy 重试策略
-type Policy struct {
-	// MaxRetries 最大重试次数
-	MaxRetries int
-	// InitialBackoff 初始退避时间
-	InitialBackoff time.Duration
-	// MaxBackoff 最大退避时间
-	MaxBackoff time.Duration
-}
+// Policy 默认重试策略（指数退避 + full jitter），见 pkg/retry.Exponential
+type Policy = wecomretry.Exponential
 
-// NewPolicy 创建重试策略
+// NewPolicy 创建默认重试策略
 func NewPolicy(maxRetries int, initialBackoff, maxBackoff time.Duration) *Policy {
-	return &Policy{
-		MaxRetries:     maxRetries,
-		InitialBackoff: initialBackoff,
-		MaxBackoff:     maxBackoff,
-	}
-}
-
-// Backoff 计算退避时间（指数退避算法）
-// backoff = InitialBackoff * 2^attempt
-func (p *Policy) Backoff(attempt int) time.Duration {
-	backoff := p.InitialBackoff * (1 << attempt)
-	if backoff > p.MaxBackoff {
-		return p.MaxBackoff
-	}
-	return backoff
+	return wecomretry.NewExponential(maxRetries, initialBackoff, maxBackoff)
 }
@@ -0,0 +1,417 @@
+// Package breaker 提供按接口维度的熔断器
+//
+// 企业微信某个接口持续返回系统繁忙（10001）或请求超时时，熔断器在连续失败达到阈值后打开，
+// 打开期间该接口的请求立即返回 ErrCircuitOpen 而不再发送和重试；
+// 经过冷却时间后进入半开状态，放行少量探测请求，探测成功则关闭，失败则重新打开。
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态，请求未发送
+// 熔断器返回的错误为 *OpenError，可以通过 errors.Is(err, ErrCircuitOpen) 判断
+var ErrCircuitOpen = wecomerrors.ErrCircuitOpen
+
+const (
+	// DefaultFailureThreshold 默认连续失败次数阈值
+	DefaultFailureThreshold = 5
+	// DefaultOpenTimeout 默认打开状态的冷却时间
+	DefaultOpenTimeout = 30 * time.Second
+	// DefaultHalfOpenRequests 默认半开状态放行的探测请求数
+	DefaultHalfOpenRequests = 1
+)
+
+// State 熔断器状态
+type State int
+
+const (
+	// StateClosed 关闭，请求正常放行
+	StateClosed State = iota
+	// StateOpen 打开，请求立即失败
+	StateOpen
+	// StateHalfOpen 半开，放行有限的探测请求
+	StateHalfOpen
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("state(%d)", int(s))
+	}
+}
+
+// Key 一次请求的熔断维度
+// 不同企业的熔断状态相互独立，多个企业共享同一个熔断器时，一个企业的失败不会熔断其他企业
+type Key struct {
+	// CorpID 企业ID
+	CorpID string
+	// AgentKey 应用名称或ID（仅在 WithPerAgent 时参与区分）
+	AgentKey string
+	// Path 接口路径，如 /cgi-bin/message/send
+	Path string
+}
+
+// String 返回熔断维度的字符串表示
+func (k Key) String() string {
+	s := k.Path
+	if k.AgentKey != "" {
+		s = k.AgentKey + "|" + s
+	}
+	if k.CorpID != "" {
+		s = k.CorpID + "|" + s
+	}
+	return s
+}
+
+// OpenError 熔断器打开时返回的错误
+type OpenError struct {
+	// Key 被熔断的维度
+	Key Key
+	// RetryAfter 距离进入半开状态的剩余时间，半开状态下探测请求已满时为 0
+	RetryAfter time.Duration
+}
+
+// Error 实现 error 接口
+func (e *OpenError) Error() string {
+	return fmt.Sprintf("%v: %s, retry after %s", ErrCircuitOpen, e.Key, e.RetryAfter)
+}
+
+// Is 支持 errors.Is(err, ErrCircuitOpen)
+func (e *OpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// StateChangeFunc 熔断器状态变化回调
+type StateChangeFunc func(key Key, from, to State)
+
+// FailureFunc 判断请求结果是否计为失败
+type FailureFunc func(err error) bool
+
+// IsFailure 默认的失败判断：系统繁忙（10001）与请求超时
+// 其他业务错误（如参数错误、成员不存在）说明接口可用，不计为失败
+func IsFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if wecomerrors.IsSystemBusy(err) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var timeout interface{ Timeout() bool }
+	return errors.As(err, &timeout) && timeout.Timeout()
+}
+
+// Option 熔断器选项
+type Option func(*Breaker)
+
+// WithFailureThreshold 设置打开熔断器的连续失败次数，默认 5 次
+func WithFailureThreshold(n int) Option {
+	return func(b *Breaker) {
+		b.failureThreshold = n
+	}
+}
+
+// WithOpenTimeout 设置打开状态的冷却时间，之后进入半开状态，默认 30 秒
+func WithOpenTimeout(d time.Duration) Option {
+	return func(b *Breaker) {
+		b.openTimeout = d
+	}
+}
+
+// WithHalfOpenRequests 设置半开状态放行的探测请求数，全部成功后关闭熔断器，默认 1 个
+func WithHalfOpenRequests(n int) Option {
+	return func(b *Breaker) {
+		b.halfOpenRequests = n
+	}
+}
+
+// WithPerAgent 按应用分别熔断，默认仅按接口路径熔断
+func WithPerAgent() Option {
+	return func(b *Breaker) {
+		b.perAgent = true
+	}
+}
+
+// WithPaths 仅对这些接口生效（支持以 * 结尾的前缀匹配），默认对所有接口生效
+func WithPaths(paths ...string) Option {
+	return func(b *Breaker) {
+		b.paths = append(b.paths, paths...)
+	}
+}
+
+// WithFailureFunc 设置失败判断函数，默认为 IsFailure
+func WithFailureFunc(fn FailureFunc) Option {
+	return func(b *Breaker) {
+		b.isFailure = fn
+	}
+}
+
+// WithStateChange 添加状态变化回调
+func WithStateChange(fn StateChangeFunc) Option {
+	return func(b *Breaker) {
+		b.listeners = append(b.listeners, &listener{fn: fn})
+	}
+}
+
+// circuit 单个维度的熔断状态
+type circuit struct {
+	state State
+	// failures 关闭状态下的连续失败次数
+	failures int
+	// openedAt 进入打开状态的时间
+	openedAt time.Time
+	// probes 半开状态下已放行的探测请求数
+	probes int
+	// successes 半开状态下成功的探测请求数
+	successes int
+}
+
+// listener 状态变化回调，以指针区分便于移除
+type listener struct {
+	fn StateChangeFunc
+}
+
+// transition 状态变化，在释放锁后通知回调
+type transition struct {
+	key      Key
+	from, to State
+}
+
+// Breaker 熔断器
+// 按接口（可选按应用）维护熔断状态，可在多个 goroutine、多个客户端之间共享
+type Breaker struct {
+	mu       sync.Mutex
+	circuits map[Key]*circuit
+
+	failureThreshold int
+	openTimeout      time.Duration
+	halfOpenRequests int
+	perAgent         bool
+	paths            []string
+	isFailure        FailureFunc
+	listeners        []*listener
+
+	// now 当前时间（便于测试）
+	now func() time.Time
+}
+
+// New 创建熔断器
+func New(opts ...Option) *Breaker {
+	b := &Breaker{
+		circuits:         make(map[Key]*circuit),
+		failureThreshold: DefaultFailureThreshold,
+		openTimeout:      DefaultOpenTimeout,
+		halfOpenRequests: DefaultHalfOpenRequests,
+		isFailure:        IsFailure,
+		now:              time.Now,
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if b.failureThreshold <= 0 {
+		b.failureThreshold = DefaultFailureThreshold
+	}
+	if b.openTimeout <= 0 {
+		b.openTimeout = DefaultOpenTimeout
+	}
+	if b.halfOpenRequests <= 0 {
+		b.halfOpenRequests = DefaultHalfOpenRequests
+	}
+	return b
+}
+
+// OnStateChange 添加状态变化回调，回调在状态变化后同步调用，不应阻塞
+// 返回移除该回调的函数，熔断器比添加回调的一方存活更久时（如多个客户端共享熔断器）应在不再需要时调用
+func (b *Breaker) OnStateChange(fn StateChangeFunc) (remove func()) {
+	l := &listener{fn: fn}
+	b.mu.Lock()
+	b.listeners = append(b.listeners, l)
+	b.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.listeners = slices.DeleteFunc(b.listeners, func(x *listener) bool { return x == l })
+		})
+	}
+}
+
+// Allow 判断请求是否放行
+// 放行的请求必须在完成后调用 Record 记录结果，未发送的请求调用 Cancel；拒绝时返回 *OpenError
+func (b *Breaker) Allow(key Key) error {
+	if !b.matches(key.Path) {
+		return nil
+	}
+	key = b.normalize(key)
+
+	b.mu.Lock()
+	c := b.circuit(key)
+	now := b.now()
+
+	var changed []transition
+	if c.state == StateOpen {
+		if wait := c.openedAt.Add(b.openTimeout).Sub(now); wait > 0 {
+			b.mu.Unlock()
+			return &OpenError{Key: key, RetryAfter: wait}
+		}
+		changed = append(changed, b.setState(key, c, StateHalfOpen, now))
+	}
+	if c.state == StateHalfOpen {
+		if c.probes >= b.halfOpenRequests {
+			b.mu.Unlock()
+			b.notify(changed)
+			return &OpenError{Key: key}
+		}
+		c.probes++
+	}
+	b.mu.Unlock()
+
+	b.notify(changed)
+	return nil
+}
+
+// Record 记录放行请求的结果
+func (b *Breaker) Record(key Key, err error) {
+	if !b.matches(key.Path) {
+		return
+	}
+	key = b.normalize(key)
+	failed := b.isFailure(err)
+
+	b.mu.Lock()
+	c := b.circuit(key)
+	now := b.now()
+
+	var changed []transition
+	switch c.state {
+	case StateClosed:
+		if !failed {
+			c.failures = 0
+			break
+		}
+		c.failures++
+		if c.failures >= b.failureThreshold {
+			changed = append(changed, b.setState(key, c, StateOpen, now))
+		}
+	case StateHalfOpen:
+		if failed {
+			changed = append(changed, b.setState(key, c, StateOpen, now))
+			break
+		}
+		c.successes++
+		if c.successes >= b.halfOpenRequests {
+			changed = append(changed, b.setState(key, c, StateClosed, now))
+		}
+	}
+	b.mu.Unlock()
+
+	b.notify(changed)
+}
+
+// Cancel 取消放行但未发送的请求（如获取 token 失败），不计入结果
+// 半开状态下释放该请求占用的探测名额，关闭状态下不影响连续失败计数
+func (b *Breaker) Cancel(key Key) {
+	if !b.matches(key.Path) {
+		return
+	}
+	key = b.normalize(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[key]; ok && c.state == StateHalfOpen && c.probes > 0 {
+		c.probes--
+	}
+}
+
+// State 返回指定维度当前的状态
+func (b *Breaker) State(key Key) State {
+	key = b.normalize(key)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if c, ok := b.circuits[key]; ok {
+		return c.state
+	}
+	return StateClosed
+}
+
+// setState 切换状态并重置计数，调用方需持有锁
+func (b *Breaker) setState(key Key, c *circuit, to State, now time.Time) transition {
+	t := transition{key: key, from: c.state, to: to}
+	c.state = to
+	c.failures = 0
+	c.probes = 0
+	c.successes = 0
+	if to == StateOpen {
+		c.openedAt = now
+	}
+	return t
+}
+
+// notify 通知状态变化回调
+func (b *Breaker) notify(changed []transition) {
+	if len(changed) == 0 {
+		return
+	}
+	b.mu.Lock()
+	listeners := slices.Clone(b.listeners)
+	b.mu.Unlock()
+
+	for _, t := range changed {
+		for _, l := range listeners {
+			l.fn(t.key, t.from, t.to)
+		}
+	}
+}
+
+// circuit 返回维度的熔断状态，不存在时创建，调用方需持有锁
+func (b *Breaker) circuit(key Key) *circuit {
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+// normalize 未按应用熔断时忽略应用标识
+func (b *Breaker) normalize(key Key) Key {
+	if !b.perAgent {
+		key.AgentKey = ""
+	}
+	return key
+}
+
+// matches 判断熔断器是否适用于接口
+func (b *Breaker) matches(path string) bool {
+	if len(b.paths) == 0 {
+		return true
+	}
+	for _, p := range b.paths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if p == path {
+			return true
+		}
+	}
+	return false
+}
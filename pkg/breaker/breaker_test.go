@@ -0,0 +1,204 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+)
+
+var (
+	errBusy     = wecomerrors.New(wecomerrors.ErrCodeSystemBusy, "system busy")
+	errBusiness = wecomerrors.New(wecomerrors.ErrCodeUserNotFound, "user not found")
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestBreaker(clock *fakeClock, opts ...Option) *Breaker {
+	b := New(opts...)
+	b.now = clock.Now
+	return b
+}
+
+// fail 放行并记录一次失败
+func fail(t *testing.T, b *Breaker, key Key) {
+	t.Helper()
+	require.NoError(t, b.Allow(key))
+	b.Record(key, errBusy)
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, WithFailureThreshold(3), WithOpenTimeout(10*time.Second))
+	key := Key{Path: "/cgi-bin/message/send"}
+
+	fail(t, b, key)
+	fail(t, b, key)
+	// 业务错误说明接口可用，重置连续失败计数
+	require.NoError(t, b.Allow(key))
+	b.Record(key, errBusiness)
+	fail(t, b, key)
+	fail(t, b, key)
+	assert.Equal(t, StateClosed, b.State(key))
+
+	fail(t, b, key)
+	assert.Equal(t, StateOpen, b.State(key))
+
+	err := b.Allow(key)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.True(t, wecomerrors.IsCircuitOpen(err))
+	var openErr *OpenError
+	require.ErrorAs(t, err, &openErr)
+	assert.Equal(t, 10*time.Second, openErr.RetryAfter)
+
+	// 其他接口不受影响
+	assert.NoError(t, b.Allow(Key{Path: "/cgi-bin/user/get"}))
+}
+
+func TestBreaker_HalfOpen(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, WithFailureThreshold(1), WithOpenTimeout(time.Second), WithHalfOpenRequests(2))
+	key := Key{Path: "/cgi-bin/user/get"}
+
+	fail(t, b, key)
+	require.Equal(t, StateOpen, b.State(key))
+
+	// 冷却后放行有限的探测请求
+	clock.Advance(time.Second)
+	require.NoError(t, b.Allow(key))
+	assert.Equal(t, StateHalfOpen, b.State(key))
+	require.NoError(t, b.Allow(key))
+	assert.ErrorIs(t, b.Allow(key), ErrCircuitOpen)
+
+	// 探测失败重新打开
+	b.Record(key, errBusy)
+	assert.Equal(t, StateOpen, b.State(key))
+
+	// 探测全部成功后关闭
+	clock.Advance(time.Second)
+	require.NoError(t, b.Allow(key))
+	require.NoError(t, b.Allow(key))
+	b.Record(key, nil)
+	assert.Equal(t, StateHalfOpen, b.State(key))
+	b.Record(key, nil)
+	assert.Equal(t, StateClosed, b.State(key))
+}
+
+func TestBreaker_Cancel(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, WithFailureThreshold(2), WithOpenTimeout(time.Second))
+	key := Key{Path: "/cgi-bin/user/get"}
+
+	// 未发送的请求不重置连续失败计数
+	fail(t, b, key)
+	require.NoError(t, b.Allow(key))
+	b.Cancel(key)
+	fail(t, b, key)
+	require.Equal(t, StateOpen, b.State(key))
+
+	// 半开状态下释放探测名额，不关闭熔断器
+	clock.Advance(time.Second)
+	require.NoError(t, b.Allow(key))
+	assert.ErrorIs(t, b.Allow(key), ErrCircuitOpen)
+	b.Cancel(key)
+	assert.Equal(t, StateHalfOpen, b.State(key))
+
+	require.NoError(t, b.Allow(key))
+	b.Record(key, nil)
+	assert.Equal(t, StateClosed, b.State(key))
+}
+
+func TestBreaker_StateChange(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var changes []string
+	b := newTestBreaker(clock, WithFailureThreshold(1), WithOpenTimeout(time.Second),
+		WithStateChange(func(key Key, from, to State) {
+			changes = append(changes, fmt.Sprintf("%s:%s->%s", key, from, to))
+		}))
+	key := Key{Path: "/cgi-bin/user/get"}
+
+	fail(t, b, key)
+	clock.Advance(time.Second)
+	require.NoError(t, b.Allow(key))
+	b.Record(key, nil)
+
+	assert.Equal(t, []string{
+		"/cgi-bin/user/get:closed->open",
+		"/cgi-bin/user/get:open->half-open",
+		"/cgi-bin/user/get:half-open->closed",
+	}, changes)
+}
+
+func TestBreaker_PerAgentAndPaths(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, WithFailureThreshold(1), WithPerAgent(), WithPaths("/cgi-bin/message/*"))
+
+	fail(t, b, Key{AgentKey: "notify", Path: "/cgi-bin/message/send"})
+	assert.ErrorIs(t, b.Allow(Key{AgentKey: "notify", Path: "/cgi-bin/message/send"}), ErrCircuitOpen)
+	assert.NoError(t, b.Allow(Key{AgentKey: "hr", Path: "/cgi-bin/message/send"}))
+
+	// 不匹配的接口不熔断
+	fail(t, b, Key{AgentKey: "notify", Path: "/cgi-bin/user/get"})
+	assert.NoError(t, b.Allow(Key{AgentKey: "notify", Path: "/cgi-bin/user/get"}))
+}
+
+func TestBreaker_PerCorp(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, WithFailureThreshold(1))
+
+	// 一个企业熔断不影响共享熔断器的其他企业
+	fail(t, b, Key{CorpID: "wwcorpa", Path: "/cgi-bin/user/get"})
+	err := b.Allow(Key{CorpID: "wwcorpa", Path: "/cgi-bin/user/get"})
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	assert.Contains(t, err.Error(), "wwcorpa|/cgi-bin/user/get")
+	assert.NoError(t, b.Allow(Key{CorpID: "wwcorpb", Path: "/cgi-bin/user/get"}))
+}
+
+func TestBreaker_RemoveStateChange(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := newTestBreaker(clock, WithFailureThreshold(1))
+
+	var first, second int
+	remove := b.OnStateChange(func(Key, State, State) { first++ })
+	b.OnStateChange(func(Key, State, State) { second++ })
+
+	fail(t, b, Key{Path: "/cgi-bin/user/get"})
+	remove()
+	remove()
+	fail(t, b, Key{Path: "/cgi-bin/department/list"})
+
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+}
+
+func TestIsFailure(t *testing.T) {
+	assert.False(t, IsFailure(nil))
+	assert.True(t, IsFailure(errBusy))
+	assert.True(t, IsFailure(fmt.Errorf("http request failed: %w", context.DeadlineExceeded)))
+	assert.True(t, IsFailure(&net.OpError{Op: "dial", Err: timeoutError{}}))
+	assert.False(t, IsFailure(errBusiness))
+	assert.False(t, IsFailure(context.Canceled))
+	assert.False(t, IsFailure(errors.New("failed to build http request")))
+}
+
+// timeoutError 模拟网络超时
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
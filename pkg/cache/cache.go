@@ -26,3 +26,17 @@ type Cache interface {
 	// Delete 删除缓存的token
 	Delete(ctx context.Context, key string) error
 }
+
+// Locker 跨进程锁接口（可选）
+// 多个实例共享同一缓存（如 Redis）时，TokenManager 通过该接口获取刷新租约，
+// 保证同一时刻只有一个实例调用 gettoken，避免各实例互相使对方的 token 失效。
+// Cache 实现同时实现 Locker 时会被自动使用，也可以通过 config.WithLocker 单独指定。
+type Locker interface {
+	// TryLock 尝试获取锁，不阻塞
+	// owner 为持有者标识，ttl 为租约时长（持有者异常退出时锁自动释放）
+	// 锁已被其他持有者占用时返回 false, nil
+	TryLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Unlock 释放锁，仅当锁的持有者为 owner 时生效
+	Unlock(ctx context.Context, key, owner string) error
+}
@@ -0,0 +1,219 @@
+package callback
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/shuaidd/wecom-core/config"
+)
+
+const (
+	// pkcs7BlockSize 企业微信使用 32 字节作为 PKCS#7 填充块大小
+	pkcs7BlockSize = 32
+	// randomPrefixLength 明文前缀随机串长度
+	randomPrefixLength = 16
+)
+
+// Crypto 回调消息加解密器
+// 实现企业微信回调的 msg_signature 校验、AES-256-CBC 加解密及 ReceiveID 校验
+// 文档: https://developer.work.weixin.qq.com/document/path/90968
+type Crypto struct {
+	// token 回调配置的 Token
+	token string
+	// aesKey 由 EncodingAESKey 解码得到的 32 字节密钥
+	aesKey []byte
+	// receiveID 企业应用为 CorpID，第三方应用为 SuiteID，为空时不校验
+	receiveID string
+}
+
+// NewCrypto 创建回调消息加解密器
+// receiveID 为空时跳过 ReceiveID 校验
+func NewCrypto(token, encodingAESKey, receiveID string) (*Crypto, error) {
+	if len(encodingAESKey) != config.EncodingAESKeyLength {
+		return nil, ErrInvalidEncodingAESKey
+	}
+	aesKey, err := base64.StdEncoding.DecodeString(encodingAESKey + "=")
+	if err != nil || len(aesKey) != 32 {
+		return nil, ErrInvalidEncodingAESKey
+	}
+
+	return &Crypto{
+		token:     token,
+		aesKey:    aesKey,
+		receiveID: receiveID,
+	}, nil
+}
+
+// NewCryptoFromConfig 根据SDK配置创建指定应用的回调消息加解密器
+// agentKey 为应用名称或ID，为空时使用全局回调配置，ReceiveID 为 CorpID
+func NewCryptoFromConfig(cfg *config.Config, agentKey string) (*Crypto, error) {
+	token, encodingAESKey := cfg.GetCallbackConfig(agentKey)
+	return NewCrypto(token, encodingAESKey, cfg.CorpID)
+}
+
+// ReceiveID 返回校验使用的 ReceiveID
+func (c *Crypto) ReceiveID() string {
+	return c.receiveID
+}
+
+// Signature 计算消息签名
+// msg_signature = sha1(sort(token, timestamp, nonce, encrypt))
+func (c *Crypto) Signature(timestamp, nonce, encrypt string) string {
+	parts := []string{c.token, timestamp, nonce, encrypt}
+	sort.Strings(parts)
+
+	sum := sha1.Sum([]byte(strings.Join(parts, "")))
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifySignature 校验消息签名
+func (c *Crypto) VerifySignature(msgSignature, timestamp, nonce, encrypt string) bool {
+	expected := c.Signature(timestamp, nonce, encrypt)
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(msgSignature)) == 1
+}
+
+// VerifyURL 验证回调URL
+// 校验 GET 请求中的签名并解密 echostr，返回需原样响应的明文
+func (c *Crypto) VerifyURL(msgSignature, timestamp, nonce, echoStr string) ([]byte, error) {
+	if !c.VerifySignature(msgSignature, timestamp, nonce, echoStr) {
+		return nil, ErrInvalidSignature
+	}
+	return c.Decrypt(echoStr)
+}
+
+// DecryptMsg 解密 POST 回调消息
+// body 为原始请求体（XML 或 JSON 信封），返回解密后的消息明文
+func (c *Crypto) DecryptMsg(msgSignature, timestamp, nonce string, body []byte) ([]byte, error) {
+	envelope, err := ParseEnvelope(body)
+	if err != nil {
+		return nil, err
+	}
+	if !c.VerifySignature(msgSignature, timestamp, nonce, envelope.Encrypt) {
+		return nil, ErrInvalidSignature
+	}
+	return c.Decrypt(envelope.Encrypt)
+}
+
+// EncryptMsg 加密被动回复消息，返回 XML 格式的加密信封
+func (c *Crypto) EncryptMsg(reply []byte, timestamp, nonce string) ([]byte, error) {
+	return c.encryptMsg(reply, timestamp, nonce, FormatXML)
+}
+
+// EncryptMsgJSON 加密被动回复消息，返回 JSON 格式的加密信封
+func (c *Crypto) EncryptMsgJSON(reply []byte, timestamp, nonce string) ([]byte, error) {
+	return c.encryptMsg(reply, timestamp, nonce, FormatJSON)
+}
+
+// encryptMsg 加密并按指定格式封装
+func (c *Crypto) encryptMsg(reply []byte, timestamp, nonce string, format Format) ([]byte, error) {
+	encrypt, err := c.Encrypt(reply)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &ResponseEnvelope{
+		Encrypt:      encrypt,
+		MsgSignature: c.Signature(timestamp, nonce, encrypt),
+		TimeStamp:    timestamp,
+		Nonce:        nonce,
+	}
+	return resp.Marshal(format)
+}
+
+// Decrypt 解密 Base64 编码的密文并校验 ReceiveID
+func (c *Crypto) Decrypt(encrypt string) ([]byte, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypt)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCiphertext, err)
+	}
+	if len(ciphertext) == 0 || len(ciphertext)%aes.BlockSize != 0 {
+		return nil, ErrInvalidCiphertext
+	}
+
+	block, err := aes.NewCipher(c.aesKey)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	cipher.NewCBCDecrypter(block, c.aesKey[:aes.BlockSize]).CryptBlocks(plaintext, ciphertext)
+
+	plaintext, err = pkcs7Unpad(plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	// random(16B) + msg_len(4B) + msg + receiveid
+	if len(plaintext) < randomPrefixLength+4 {
+		return nil, ErrInvalidCiphertext
+	}
+	content := plaintext[randomPrefixLength:]
+	msgLen := int(binary.BigEndian.Uint32(content[:4]))
+	if msgLen > len(content)-4 {
+		return nil, ErrInvalidCiphertext
+	}
+	msg := content[4 : 4+msgLen]
+	receiveID := string(content[4+msgLen:])
+
+	if c.receiveID != "" && receiveID != c.receiveID {
+		return nil, ErrInvalidReceiveID
+	}
+
+	return msg, nil
+}
+
+// Encrypt 加密消息明文，返回 Base64 编码的密文
+func (c *Crypto) Encrypt(msg []byte) (string, error) {
+	random := make([]byte, randomPrefixLength)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("failed to generate random prefix: %w", err)
+	}
+
+	var buf bytes.Buffer
+	buf.Write(random)
+	if err := binary.Write(&buf, binary.BigEndian, uint32(len(msg))); err != nil {
+		return "", err
+	}
+	buf.Write(msg)
+	buf.WriteString(c.receiveID)
+
+	plaintext := pkcs7Pad(buf.Bytes())
+
+	block, err := aes.NewCipher(c.aesKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, c.aesKey[:aes.BlockSize]).CryptBlocks(ciphertext, plaintext)
+
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// pkcs7Pad 按 32 字节块大小填充
+func pkcs7Pad(data []byte) []byte {
+	padding := pkcs7BlockSize - len(data)%pkcs7BlockSize
+	return append(data, bytes.Repeat([]byte{byte(padding)}, padding)...)
+}
+
+// pkcs7Unpad 去除 PKCS#7 填充
+func pkcs7Unpad(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return nil, ErrInvalidPadding
+	}
+	padding := int(data[len(data)-1])
+	if padding < 1 || padding > pkcs7BlockSize || padding > len(data) {
+		return nil, ErrInvalidPadding
+	}
+	return data[:len(data)-padding], nil
+}
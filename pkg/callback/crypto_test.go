@@ -0,0 +1,118 @@
+package callback
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/config"
+)
+
+const (
+	testToken          = "QDG6eK"
+	testReceiveID      = "wx5823bf96d3bd56c7"
+	testEncodingAESKey = "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C"
+)
+
+func newTestCrypto(t *testing.T) *Crypto {
+	t.Helper()
+	c, err := NewCrypto(testToken, testEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+	return c
+}
+
+func TestNewCrypto_InvalidKey(t *testing.T) {
+	_, err := NewCrypto(testToken, "short", testReceiveID)
+	assert.ErrorIs(t, err, ErrInvalidEncodingAESKey)
+}
+
+func TestCrypto_VerifyURL(t *testing.T) {
+	c := newTestCrypto(t)
+
+	// 官方文档示例数据
+	echo, err := c.VerifyURL(
+		"5c45ff5e21c57e6ad56bac8758b79b1d9ac89fd3",
+		"1409659589",
+		"263014780",
+		"P9nAzCzyDtyTWESHep1vC5X9xho/qYX3Zpb4yKa9SKld1DsH3Iyt3tP3zNdtp+4RPcs8TgAE7OaBO+FZXvnaqQ==",
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "1616140317555161061", string(echo))
+}
+
+func TestCrypto_VerifyURL_InvalidSignature(t *testing.T) {
+	c := newTestCrypto(t)
+
+	_, err := c.VerifyURL("bad", "1409659589", "263014780",
+		"P9nAzCzyDtyTWESHep1vC5X9xho/qYX3Zpb4yKa9SKld1DsH3Iyt3tP3zNdtp+4RPcs8TgAE7OaBO+FZXvnaqQ==")
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestCrypto_EncryptDecryptRoundTrip(t *testing.T) {
+	c := newTestCrypto(t)
+	msg := []byte("<xml><Content><![CDATA[hello]]></Content></xml>")
+
+	envelope, err := c.EncryptMsg(msg, "1409659589", "263014780")
+	require.NoError(t, err)
+
+	var resp struct {
+		Encrypt      string `xml:"Encrypt"`
+		MsgSignature string `xml:"MsgSignature"`
+	}
+	require.NoError(t, xml.Unmarshal(envelope, &resp))
+
+	// 构造回调请求信封并解密
+	body := []byte("<xml><ToUserName><![CDATA[" + testReceiveID + "]]></ToUserName><Encrypt><![CDATA[" +
+		resp.Encrypt + "]]></Encrypt><AgentID><![CDATA[218]]></AgentID></xml>")
+	got, err := c.DecryptMsg(resp.MsgSignature, "1409659589", "263014780", body)
+	require.NoError(t, err)
+	assert.Equal(t, msg, got)
+}
+
+func TestCrypto_DecryptJSONEnvelope(t *testing.T) {
+	c := newTestCrypto(t)
+
+	encrypt, err := c.Encrypt([]byte(`{"msgtype":"text"}`))
+	require.NoError(t, err)
+	signature := c.Signature("1", "2", encrypt)
+
+	body := []byte(`{"tousername":"` + testReceiveID + `","encrypt":"` + encrypt + `","agentid":1000002}`)
+	got, err := c.DecryptMsg(signature, "1", "2", body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"msgtype":"text"}`, string(got))
+
+	envelope, err := ParseEnvelope(body)
+	require.NoError(t, err)
+	assert.Equal(t, FormatJSON, envelope.Format)
+	assert.Equal(t, "1000002", envelope.AgentID)
+}
+
+func TestCrypto_Decrypt_ReceiveIDMismatch(t *testing.T) {
+	c := newTestCrypto(t)
+	encrypt, err := c.Encrypt([]byte("hello"))
+	require.NoError(t, err)
+
+	other, err := NewCrypto(testToken, testEncodingAESKey, "another_corp")
+	require.NoError(t, err)
+
+	_, err = other.Decrypt(encrypt)
+	assert.ErrorIs(t, err, ErrInvalidReceiveID)
+}
+
+func TestNewCryptoFromConfig(t *testing.T) {
+	cfg := config.New(
+		config.WithCorpID(testReceiveID),
+		config.WithAgent("app", 1000002, "secret"),
+		config.WithAgentCallback("app", testToken, testEncodingAESKey),
+	)
+	require.NoError(t, cfg.Validate())
+
+	c, err := NewCryptoFromConfig(cfg, "app")
+	require.NoError(t, err)
+	assert.Equal(t, testReceiveID, c.ReceiveID())
+
+	_, err = NewCryptoFromConfig(cfg, "")
+	assert.ErrorIs(t, err, ErrInvalidEncodingAESKey)
+}
@@ -0,0 +1,112 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+)
+
+// Format 回调消息体格式
+type Format int
+
+const (
+	// FormatXML XML 格式（默认）
+	FormatXML Format = iota
+	// FormatJSON JSON 格式
+	FormatJSON
+)
+
+// Envelope 回调请求的加密信封
+type Envelope struct {
+	// ToUserName 企业微信的CorpID，第三方应用为SuiteID
+	ToUserName string `xml:"ToUserName" json:"tousername"`
+	// Encrypt 消息密文
+	Encrypt string `xml:"Encrypt" json:"encrypt"`
+	// AgentID 接收的应用id，可能为空
+	AgentID string `xml:"AgentID" json:"agentid"`
+	// Format 信封格式
+	Format Format `xml:"-" json:"-"`
+}
+
+// ParseEnvelope 解析回调请求体中的加密信封，自动识别 XML 与 JSON 格式
+func ParseEnvelope(body []byte) (*Envelope, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, ErrInvalidEnvelope
+	}
+
+	envelope := &Envelope{}
+	if body[0] == '{' {
+		// JSON 信封中 agentid 可能为数字或字符串
+		var raw struct {
+			ToUserName string          `json:"tousername"`
+			Encrypt    string          `json:"encrypt"`
+			AgentID    json.RawMessage `json:"agentid"`
+		}
+		if err := json.Unmarshal(body, &raw); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+		}
+		envelope.ToUserName = raw.ToUserName
+		envelope.Encrypt = raw.Encrypt
+		envelope.AgentID = string(bytes.Trim(raw.AgentID, `"`))
+		envelope.Format = FormatJSON
+	} else {
+		if err := xml.Unmarshal(body, envelope); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidEnvelope, err)
+		}
+		envelope.Format = FormatXML
+	}
+
+	if envelope.Encrypt == "" {
+		return nil, ErrInvalidEnvelope
+	}
+	return envelope, nil
+}
+
+// ResponseEnvelope 被动回复的加密信封
+type ResponseEnvelope struct {
+	// Encrypt 加密后的消息密文
+	Encrypt string
+	// MsgSignature 消息签名
+	MsgSignature string
+	// TimeStamp 时间戳
+	TimeStamp string
+	// Nonce 随机数
+	Nonce string
+}
+
+// cdata XML CDATA 节点
+type cdata struct {
+	Value string `xml:",cdata"`
+}
+
+// Marshal 按指定格式序列化加密信封
+func (r *ResponseEnvelope) Marshal(format Format) ([]byte, error) {
+	if format == FormatJSON {
+		timestamp, err := strconv.ParseInt(r.TimeStamp, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp: %w", err)
+		}
+		return json.Marshal(struct {
+			Encrypt      string `json:"encrypt"`
+			MsgSignature string `json:"msgsignature"`
+			TimeStamp    int64  `json:"timestamp"`
+			Nonce        string `json:"nonce"`
+		}{r.Encrypt, r.MsgSignature, timestamp, r.Nonce})
+	}
+
+	return xml.Marshal(struct {
+		XMLName      xml.Name `xml:"xml"`
+		Encrypt      cdata    `xml:"Encrypt"`
+		MsgSignature cdata    `xml:"MsgSignature"`
+		TimeStamp    string   `xml:"TimeStamp"`
+		Nonce        cdata    `xml:"Nonce"`
+	}{
+		Encrypt:      cdata{r.Encrypt},
+		MsgSignature: cdata{r.MsgSignature},
+		TimeStamp:    r.TimeStamp,
+		Nonce:        cdata{r.Nonce},
+	})
+}
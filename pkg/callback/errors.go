@@ -0,0 +1,23 @@
+package callback
+
+import "errors"
+
+var (
+	// ErrInvalidEncodingAESKey EncodingAESKey 无效（应为43位）
+	ErrInvalidEncodingAESKey = errors.New("callback: invalid encodingAESKey")
+
+	// ErrInvalidSignature 消息签名校验失败
+	ErrInvalidSignature = errors.New("callback: invalid msg_signature")
+
+	// ErrInvalidCiphertext 密文格式错误
+	ErrInvalidCiphertext = errors.New("callback: invalid ciphertext")
+
+	// ErrInvalidPadding PKCS#7 填充错误
+	ErrInvalidPadding = errors.New("callback: invalid pkcs7 padding")
+
+	// ErrInvalidReceiveID ReceiveID 不匹配
+	ErrInvalidReceiveID = errors.New("callback: receiveid mismatch")
+
+	// ErrInvalidEnvelope 回调消息体格式错误
+	ErrInvalidEnvelope = errors.New("callback: invalid envelope")
+)
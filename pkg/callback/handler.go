@@ -0,0 +1,333 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/pkg/logger"
+)
+
+// maxBodySize 回调请求体的最大长度
+const maxBodySize = 1 << 20
+
+// HandlerFunc 回调消息处理函数
+// ctx 中已通过 WithAgentName 设置了匹配到的应用，可直接用于调用SDK接口
+// 返回非 nil 的 Reply 时会加密后作为被动回复响应，无需回复时返回 nil
+type HandlerFunc func(ctx context.Context, msg *Message) (Reply, error)
+
+// Typed 将处理指定消息结构体的函数转换为 HandlerFunc
+// 当消息的 Payload 不是 *T 类型时，会按 T 重新解析原始消息
+//
+// 示例：
+//
+//	h.HandleMessage(callback.MsgTypeText, callback.Typed(func(ctx context.Context, m *callback.TextMessage) (callback.Reply, error) {
+//	    return callback.NewTextReply("收到: " + m.Content), nil
+//	}))
+func Typed[T any](fn func(ctx context.Context, payload *T) (Reply, error)) HandlerFunc {
+	return func(ctx context.Context, msg *Message) (Reply, error) {
+		if payload, ok := msg.Payload.(*T); ok {
+			return fn(ctx, payload)
+		}
+		payload := new(T)
+		if err := msg.Decode(payload); err != nil {
+			return nil, err
+		}
+		return fn(ctx, payload)
+	}
+}
+
+// agentCrypto 应用及其回调加解密器
+type agentCrypto struct {
+	agentKey string
+	crypto   *Crypto
+}
+
+// Handler 企业微信回调 http.Handler
+// 负责URL验证、消息解密、按 MsgType/Event/ChangeType 分发到注册的处理函数
+// 多应用场景下可共用同一个回调地址，根据信封中的 AgentID 或签名匹配应用
+type Handler struct {
+	// cfg SDK配置
+	cfg *config.Config
+	// logger 日志记录器
+	logger logger.Logger
+	// cryptos 已配置回调的应用加解密器
+	cryptos []*agentCrypto
+	// byAgentID 按应用ID索引的加解密器
+	byAgentID map[string]*agentCrypto
+
+	// mu 保护路由表
+	mu sync.RWMutex
+	// routes 路由表
+	routes map[string]HandlerFunc
+	// fallback 未匹配路由时的处理函数
+	fallback HandlerFunc
+}
+
+// NewHandler 根据SDK配置创建回调处理器
+// 全局回调配置（WithCallback）与各应用回调配置（WithAgentCallback）至少需要配置一项
+func NewHandler(cfg *config.Config) (*Handler, error) {
+	h := &Handler{
+		cfg:       cfg,
+		logger:    cfg.Logger,
+		byAgentID: make(map[string]*agentCrypto),
+		routes:    make(map[string]HandlerFunc),
+	}
+	if h.logger == nil {
+		h.logger = logger.NewNoopLogger()
+	}
+
+	// 各应用单独配置的回调
+	seen := make(map[*config.AgentConfig]bool)
+	for _, agent := range cfg.Agents {
+		if seen[agent] || agent.EncodingAESKey == "" {
+			continue
+		}
+		seen[agent] = true
+
+		crypto, err := NewCrypto(agent.Token, agent.EncodingAESKey, cfg.CorpID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid callback config for agent %d: %w", agent.AgentID, err)
+		}
+		agentKey := agent.AgentName
+		if agentKey == "" {
+			agentKey = strconv.FormatInt(agent.AgentID, 10)
+		}
+		ac := &agentCrypto{agentKey: agentKey, crypto: crypto}
+		h.cryptos = append(h.cryptos, ac)
+		if agent.AgentID > 0 {
+			h.byAgentID[strconv.FormatInt(agent.AgentID, 10)] = ac
+		}
+	}
+
+	// 全局回调配置
+	if cfg.EncodingAESKey != "" {
+		crypto, err := NewCrypto(cfg.Token, cfg.EncodingAESKey, cfg.CorpID)
+		if err != nil {
+			return nil, err
+		}
+		h.cryptos = append(h.cryptos, &agentCrypto{crypto: crypto})
+	}
+
+	if len(h.cryptos) == 0 {
+		return nil, ErrInvalidEncodingAESKey
+	}
+
+	return h, nil
+}
+
+// HandleMessage 注册普通消息处理函数，如 MsgTypeText
+func (h *Handler) HandleMessage(msgType string, fn HandlerFunc) *Handler {
+	return h.handle(routeKey(msgType, "", ""), fn)
+}
+
+// HandleEvent 注册事件处理函数，如 EventClick
+// 对于带 ChangeType 的事件，未注册具体变更类型时也会分发到此处
+func (h *Handler) HandleEvent(event string, fn HandlerFunc) *Handler {
+	return h.handle(routeKey(MsgTypeEvent, event, ""), fn)
+}
+
+// HandleChange 注册变更事件处理函数，如 (EventChangeContact, ChangeTypeCreateUser)
+func (h *Handler) HandleChange(event, changeType string, fn HandlerFunc) *Handler {
+	return h.handle(routeKey(MsgTypeEvent, event, changeType), fn)
+}
+
+// HandleDefault 注册未匹配任何路由时的处理函数
+func (h *Handler) HandleDefault(fn HandlerFunc) *Handler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = fn
+	return h
+}
+
+// handle 注册路由
+func (h *Handler) handle(key string, fn HandlerFunc) *Handler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.routes[key] = fn
+	return h
+}
+
+// route 查找消息对应的处理函数
+func (h *Handler) route(msg *Message) HandlerFunc {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if fn, ok := h.routes[msg.routeKey()]; ok {
+		return fn
+	}
+	if msg.ChangeType != "" {
+		if fn, ok := h.routes[routeKey(msg.MsgType, msg.Event, "")]; ok {
+			return fn
+		}
+	}
+	return h.fallback
+}
+
+// ServeHTTP 实现 http.Handler
+// GET 请求用于验证回调URL，POST 请求用于接收消息与事件
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.serveVerify(w, r)
+	case http.MethodPost:
+		h.serveMessage(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// serveVerify 处理URL验证请求
+func (h *Handler) serveVerify(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	signature, timestamp, nonce, echoStr := q.Get("msg_signature"), q.Get("timestamp"), q.Get("nonce"), q.Get("echostr")
+
+	ac, plain, err := h.resolve(h.cryptos, signature, timestamp, nonce, echoStr)
+	if err != nil {
+		h.logger.Warn("Callback URL verification failed",
+			logger.F("error", err))
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	h.logger.Debug("Callback URL verified",
+		logger.F("agent_key", ac.agentKey))
+
+	_, _ = w.Write(plain)
+}
+
+// serveMessage 处理消息与事件推送
+func (h *Handler) serveMessage(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	signature, timestamp, nonce := q.Get("msg_signature"), q.Get("timestamp"), q.Get("nonce")
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	envelope, err := ParseEnvelope(body)
+	if err != nil {
+		h.logger.Warn("Invalid callback envelope", logger.F("error", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	ac, plain, err := h.resolve(h.candidates(envelope), signature, timestamp, nonce, envelope.Encrypt)
+	if err != nil {
+		h.logger.Warn("Failed to decrypt callback message",
+			logger.F("agent_id", envelope.AgentID),
+			logger.F("error", err))
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	msg, err := ParseMessage(plain, envelope.Format)
+	if err != nil {
+		h.logger.Warn("Failed to parse callback message",
+			logger.F("agent_key", ac.agentKey),
+			logger.F("error", err))
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	msg.AgentKey = h.agentKey(ac, envelope.AgentID)
+
+	ctx := r.Context()
+	if msg.AgentKey != "" {
+		ctx = client.WithAgentName(ctx, msg.AgentKey)
+	}
+
+	reply, err := h.dispatch(ctx, msg)
+	if err != nil {
+		// 返回非 200 时企业微信会重试推送
+		h.logger.Error("Callback handler failed",
+			logger.F("agent_key", msg.AgentKey),
+			logger.F("msg_type", msg.MsgType),
+			logger.F("event", msg.Event),
+			logger.F("change_type", msg.ChangeType),
+			logger.F("error", err))
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if reply == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	resp, err := ac.crypto.EncryptReply(msg, reply, timestamp, nonce)
+	if err != nil {
+		// 处理函数已经执行，返回非 200 会导致企业微信重试推送并重复执行处理函数，
+		// 因此只记录错误并以空包响应（如 JSON 格式的回调不支持被动回复）
+		h.logger.Error("Failed to build passive reply",
+			logger.F("agent_key", msg.AgentKey),
+			logger.F("reply_type", reply.MsgType()),
+			logger.F("error", err))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	_, _ = w.Write(resp)
+}
+
+// dispatch 分发消息到处理函数
+func (h *Handler) dispatch(ctx context.Context, msg *Message) (Reply, error) {
+	fn := h.route(msg)
+	if fn == nil {
+		h.logger.Debug("No callback handler registered",
+			logger.F("agent_key", msg.AgentKey),
+			logger.F("msg_type", msg.MsgType),
+			logger.F("event", msg.Event),
+			logger.F("change_type", msg.ChangeType))
+		return nil, nil
+	}
+	return fn(ctx, msg)
+}
+
+// candidates 返回可能匹配信封的加解密器，优先使用 AgentID 对应的应用
+func (h *Handler) candidates(envelope *Envelope) []*agentCrypto {
+	if ac, ok := h.byAgentID[envelope.AgentID]; ok {
+		return []*agentCrypto{ac}
+	}
+	return h.cryptos
+}
+
+// resolve 依次校验签名并解密，返回第一个成功的应用及明文
+func (h *Handler) resolve(candidates []*agentCrypto, signature, timestamp, nonce, encrypt string) (*agentCrypto, []byte, error) {
+	err := ErrInvalidSignature
+	for _, ac := range candidates {
+		if !ac.crypto.VerifySignature(signature, timestamp, nonce, encrypt) {
+			continue
+		}
+		var plain []byte
+		if plain, err = ac.crypto.Decrypt(encrypt); err == nil {
+			return ac, plain, nil
+		}
+	}
+	return nil, nil, err
+}
+
+// agentKey 返回消息所属应用的key
+// 使用全局回调配置时，根据信封中的 AgentID 查找已配置的应用
+func (h *Handler) agentKey(ac *agentCrypto, agentID string) string {
+	if ac.agentKey != "" || agentID == "" {
+		return ac.agentKey
+	}
+	id, err := strconv.ParseInt(agentID, 10, 64)
+	if err != nil {
+		return ""
+	}
+	if agent := h.cfg.GetAgentByID(id); agent != nil {
+		if agent.AgentName != "" {
+			return agent.AgentName
+		}
+		return agentID
+	}
+	return ""
+}
@@ -0,0 +1,152 @@
+package callback
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/config"
+)
+
+const otherEncodingAESKey = "abcdefghijklmnopqrstuvwxyz0123456789ABCDEFG"
+
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	cfg := config.New(
+		config.WithCorpID(testReceiveID),
+		config.WithAgent("sales", 1000002, "secret1"),
+		config.WithAgent("hr", 1000003, "secret2"),
+		config.WithAgentCallback("sales", testToken, testEncodingAESKey),
+		config.WithAgentCallback("hr", "hr_token", otherEncodingAESKey),
+	)
+	h, err := NewHandler(cfg)
+	require.NoError(t, err)
+	return h
+}
+
+// postMessage 以指定应用的回调配置加密消息并发送到 handler
+func postMessage(t *testing.T, h http.Handler, crypto *Crypto, agentID, plain string) *httptest.ResponseRecorder {
+	t.Helper()
+	encrypt, err := crypto.Encrypt([]byte(plain))
+	require.NoError(t, err)
+
+	body := "<xml><ToUserName><![CDATA[" + testReceiveID + "]]></ToUserName><Encrypt><![CDATA[" +
+		encrypt + "]]></Encrypt><AgentID><![CDATA[" + agentID + "]]></AgentID></xml>"
+	q := url.Values{
+		"msg_signature": {crypto.Signature("1409659589", "nonce", encrypt)},
+		"timestamp":     {"1409659589"},
+		"nonce":         {"nonce"},
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/callback?"+q.Encode(), strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_VerifyURL(t *testing.T) {
+	h := newTestHandler(t)
+	hr, err := NewCrypto("hr_token", otherEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+
+	echo, err := hr.Encrypt([]byte("echo-123"))
+	require.NoError(t, err)
+	q := url.Values{
+		"msg_signature": {hr.Signature("1", "2", echo)},
+		"timestamp":     {"1"},
+		"nonce":         {"2"},
+		"echostr":       {echo},
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback?"+q.Encode(), nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "echo-123", rec.Body.String())
+
+	q.Set("msg_signature", "invalid")
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/callback?"+q.Encode(), nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestHandler_DispatchTypedMessage(t *testing.T) {
+	h := newTestHandler(t)
+	crypto, err := NewCrypto(testToken, testEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+
+	var got *TextMessage
+	var agentKey string
+	h.HandleMessage(MsgTypeText, func(ctx context.Context, msg *Message) (Reply, error) {
+		agentKey = msg.AgentKey
+		return Typed(func(ctx context.Context, m *TextMessage) (Reply, error) {
+			got = m
+			return nil, nil
+		})(ctx, msg)
+	})
+
+	rec := postMessage(t, h, crypto, "1000002", `<xml><ToUserName><![CDATA[corp]]></ToUserName>
+<FromUserName><![CDATA[zhangsan]]></FromUserName><CreateTime>1348831860</CreateTime>
+<MsgType><![CDATA[text]]></MsgType><Content><![CDATA[hello]]></Content>
+<MsgId>1234567890123456</MsgId><AgentID>1000002</AgentID></xml>`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, got)
+	assert.Equal(t, "hello", got.Content)
+	assert.Equal(t, "zhangsan", got.FromUserName)
+	assert.Equal(t, int64(1234567890123456), got.MsgID)
+	assert.Equal(t, "sales", agentKey)
+}
+
+func TestHandler_DispatchChangeEvent(t *testing.T) {
+	h := newTestHandler(t)
+	crypto, err := NewCrypto("hr_token", otherEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+
+	var created *ChangeContactUserEvent
+	var fallbackEvent string
+	h.HandleChange(EventChangeContact, ChangeTypeCreateUser, Typed(func(ctx context.Context, e *ChangeContactUserEvent) (Reply, error) {
+		created = e
+		return nil, nil
+	}))
+	h.HandleEvent(EventChangeContact, func(ctx context.Context, msg *Message) (Reply, error) {
+		fallbackEvent = msg.ChangeType
+		return nil, nil
+	})
+
+	// 通讯录事件的信封中没有 AgentID，需按签名匹配应用
+	rec := postMessage(t, h, crypto, "", `<xml><ToUserName><![CDATA[corp]]></ToUserName>
+<FromUserName><![CDATA[sys]]></FromUserName><CreateTime>1403610513</CreateTime>
+<MsgType><![CDATA[event]]></MsgType><Event><![CDATA[change_contact]]></Event>
+<ChangeType>create_user</ChangeType><UserID><![CDATA[zhangsan]]></UserID>
+<Name><![CDATA[张三]]></Name><Department><![CDATA[1,2,3]]></Department></xml>`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	require.NotNil(t, created)
+	assert.Equal(t, "zhangsan", created.UserID)
+	assert.Equal(t, "1,2,3", created.Department)
+
+	rec = postMessage(t, h, crypto, "", `<xml><MsgType>event</MsgType><Event>change_contact</Event>
+<ChangeType>delete_party</ChangeType><Id>2</Id></xml>`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, ChangeTypeDeleteParty, fallbackEvent)
+}
+
+func TestHandler_HandlerError(t *testing.T) {
+	h := newTestHandler(t)
+	crypto, err := NewCrypto(testToken, testEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+
+	h.HandleEvent(EventClick, func(ctx context.Context, msg *Message) (Reply, error) {
+		assert.IsType(t, &ClickEvent{}, msg.Payload)
+		return nil, assert.AnError
+	})
+
+	rec := postMessage(t, h, crypto, "1000002",
+		`<xml><MsgType>event</MsgType><Event>click</Event><EventKey>menu_1</EventKey></xml>`)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
@@ -0,0 +1,616 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+)
+
+// 消息类型
+const (
+	// MsgTypeText 文本消息
+	MsgTypeText = "text"
+	// MsgTypeImage 图片消息
+	MsgTypeImage = "image"
+	// MsgTypeVoice 语音消息
+	MsgTypeVoice = "voice"
+	// MsgTypeVideo 视频消息
+	MsgTypeVideo = "video"
+	// MsgTypeLocation 位置消息
+	MsgTypeLocation = "location"
+	// MsgTypeLink 链接消息
+	MsgTypeLink = "link"
+	// MsgTypeEvent 事件
+	MsgTypeEvent = "event"
+)
+
+// 事件类型
+const (
+	// EventSubscribe 成员关注
+	EventSubscribe = "subscribe"
+	// EventUnsubscribe 成员取消关注
+	EventUnsubscribe = "unsubscribe"
+	// EventEnterAgent 进入应用
+	EventEnterAgent = "enter_agent"
+	// EventLocation 上报地理位置
+	EventLocation = "LOCATION"
+	// EventBatchJobResult 异步任务完成
+	EventBatchJobResult = "batch_job_result"
+	// EventClick 点击菜单拉取消息
+	EventClick = "click"
+	// EventView 点击菜单跳转链接
+	EventView = "view"
+	// EventScancodePush 扫码推事件
+	EventScancodePush = "scancode_push"
+	// EventScancodeWaitMsg 扫码推事件且弹出"消息接收中"提示框
+	EventScancodeWaitMsg = "scancode_waitmsg"
+	// EventLocationSelect 弹出地理位置选择器
+	EventLocationSelect = "location_select"
+	// EventChangeContact 通讯录变更
+	EventChangeContact = "change_contact"
+	// EventChangeExternalContact 客户变更
+	EventChangeExternalContact = "change_external_contact"
+	// EventChangeExternalChat 客户群变更
+	EventChangeExternalChat = "change_external_chat"
+	// EventChangeExternalTag 客户标签变更
+	EventChangeExternalTag = "change_external_tag"
+	// EventSysApprovalChange 审批申请状态变化
+	EventSysApprovalChange = "sys_approval_change"
+	// EventKfMsgOrEvent 微信客服消息与事件
+	EventKfMsgOrEvent = "kf_msg_or_event"
+	// EventTemplateCardEvent 模板卡片事件
+	EventTemplateCardEvent = "template_card_event"
+	// EventTemplateCardMenuEvent 模板卡片右上角菜单事件
+	EventTemplateCardMenuEvent = "template_card_menu_event"
+)
+
+// 变更类型
+const (
+	// ChangeTypeCreateUser 新增成员
+	ChangeTypeCreateUser = "create_user"
+	// ChangeTypeUpdateUser 更新成员
+	ChangeTypeUpdateUser = "update_user"
+	// ChangeTypeDeleteUser 删除成员
+	ChangeTypeDeleteUser = "delete_user"
+	// ChangeTypeCreateParty 新增部门
+	ChangeTypeCreateParty = "create_party"
+	// ChangeTypeUpdateParty 更新部门
+	ChangeTypeUpdateParty = "update_party"
+	// ChangeTypeDeleteParty 删除部门
+	ChangeTypeDeleteParty = "delete_party"
+	// ChangeTypeUpdateTag 标签成员变更
+	ChangeTypeUpdateTag = "update_tag"
+
+	// ChangeTypeAddExternalContact 添加企业客户
+	ChangeTypeAddExternalContact = "add_external_contact"
+	// ChangeTypeEditExternalContact 编辑企业客户
+	ChangeTypeEditExternalContact = "edit_external_contact"
+	// ChangeTypeAddHalfExternalContact 外部联系人免验证添加成员
+	ChangeTypeAddHalfExternalContact = "add_half_external_contact"
+	// ChangeTypeDelExternalContact 删除企业客户
+	ChangeTypeDelExternalContact = "del_external_contact"
+	// ChangeTypeDelFollowUser 删除跟进成员
+	ChangeTypeDelFollowUser = "del_follow_user"
+	// ChangeTypeTransferFail 客户接替失败
+	ChangeTypeTransferFail = "transfer_fail"
+
+	// ChangeTypeCreate 创建（客户群、客户标签）
+	ChangeTypeCreate = "create"
+	// ChangeTypeUpdate 变更（客户群、客户标签）
+	ChangeTypeUpdate = "update"
+	// ChangeTypeDismiss 解散（客户群）
+	ChangeTypeDismiss = "dismiss"
+	// ChangeTypeDelete 删除（客户标签）
+	ChangeTypeDelete = "delete"
+	// ChangeTypeShuffle 重排（客户标签）
+	ChangeTypeShuffle = "shuffle"
+)
+
+// Header 回调消息公共字段
+type Header struct {
+	// ToUserName 企业微信CorpID
+	ToUserName string `xml:"ToUserName"`
+	// FromUserName 消息发送者，成员UserID或sys
+	FromUserName string `xml:"FromUserName"`
+	// CreateTime 消息创建时间（整型）
+	CreateTime int64 `xml:"CreateTime"`
+	// MsgType 消息类型
+	MsgType string `xml:"MsgType"`
+	// AgentID 企业应用的id
+	AgentID int64 `xml:"AgentID"`
+}
+
+// EventHeader 事件公共字段
+type EventHeader struct {
+	Header
+	// Event 事件类型
+	Event string `xml:"Event"`
+}
+
+// Message 解密后的回调消息
+type Message struct {
+	EventHeader
+	// ChangeType 变更类型（通讯录、客户等变更事件）
+	ChangeType string `xml:"ChangeType"`
+
+	// AgentKey 匹配到的应用key（应用名称或ID），未匹配时为空
+	AgentKey string `xml:"-" json:"-"`
+	// Format 消息格式
+	Format Format `xml:"-" json:"-"`
+	// Raw 解密后的原始消息明文
+	Raw []byte `xml:"-" json:"-"`
+	// Payload 按消息类型解析得到的结构体指针，如 *TextMessage、*ClickEvent
+	// 未知类型时为 nil
+	Payload any `xml:"-" json:"-"`
+}
+
+// ParseMessage 解析解密后的消息明文
+func ParseMessage(raw []byte, format Format) (*Message, error) {
+	msg := &Message{
+		Format: format,
+		Raw:    raw,
+	}
+	if err := msg.Decode(msg); err != nil {
+		return nil, err
+	}
+
+	if factory, ok := payloadFactories[msg.routeKey()]; ok {
+		payload := factory()
+		if err := msg.Decode(payload); err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+	} else if factory, ok := payloadFactories[routeKey(msg.MsgType, msg.Event, "")]; ok {
+		payload := factory()
+		if err := msg.Decode(payload); err != nil {
+			return nil, err
+		}
+		msg.Payload = payload
+	}
+
+	return msg, nil
+}
+
+// Decode 将原始消息解析到指定结构体
+// 两种格式均按 xml 标签解析：JSON 格式的回调字段名与 XML 相同，先转换为等价的 XML，
+// 保证 Location_X、MemChangeList>Item 等与 Go 字段名不一致的字段在两种格式下行为一致
+func (m *Message) Decode(v any) error {
+	raw := m.Raw
+	if m.Format == FormatJSON {
+		converted, err := jsonMessageToXML(raw)
+		if err != nil {
+			return fmt.Errorf("failed to decode callback message: %w", err)
+		}
+		raw = converted
+	}
+	if err := xml.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("failed to decode callback message: %w", err)
+	}
+	return nil
+}
+
+// jsonMessageToXML 将 JSON 格式的回调消息转换为等价的 XML，字段名保持不变
+func jsonMessageToXML(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := jsonToXML(dec, e, "xml", sameName); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sameName 保持 JSON 字段名作为 XML 元素名
+func sameName(key string) string { return key }
+
+// routeKey 消息的路由key
+func (m *Message) routeKey() string {
+	return routeKey(m.MsgType, m.Event, m.ChangeType)
+}
+
+// routeKey 由消息类型、事件类型、变更类型组成路由key
+func routeKey(msgType, event, changeType string) string {
+	if msgType != MsgTypeEvent {
+		return "msg:" + msgType
+	}
+	if changeType == "" {
+		return "event:" + event
+	}
+	return "event:" + event + ":" + changeType
+}
+
+// payloadFactories 路由key到消息结构体的映射
+var payloadFactories = map[string]func() any{
+	routeKey(MsgTypeText, "", ""):     func() any { return &TextMessage{} },
+	routeKey(MsgTypeImage, "", ""):    func() any { return &ImageMessage{} },
+	routeKey(MsgTypeVoice, "", ""):    func() any { return &VoiceMessage{} },
+	routeKey(MsgTypeVideo, "", ""):    func() any { return &VideoMessage{} },
+	routeKey(MsgTypeLocation, "", ""): func() any { return &LocationMessage{} },
+	routeKey(MsgTypeLink, "", ""):     func() any { return &LinkMessage{} },
+
+	routeKey(MsgTypeEvent, EventSubscribe, ""):             func() any { return &SubscribeEvent{} },
+	routeKey(MsgTypeEvent, EventUnsubscribe, ""):           func() any { return &SubscribeEvent{} },
+	routeKey(MsgTypeEvent, EventEnterAgent, ""):            func() any { return &EnterAgentEvent{} },
+	routeKey(MsgTypeEvent, EventLocation, ""):              func() any { return &LocationEvent{} },
+	routeKey(MsgTypeEvent, EventBatchJobResult, ""):        func() any { return &BatchJobResultEvent{} },
+	routeKey(MsgTypeEvent, EventClick, ""):                 func() any { return &ClickEvent{} },
+	routeKey(MsgTypeEvent, EventView, ""):                  func() any { return &ViewEvent{} },
+	routeKey(MsgTypeEvent, EventScancodePush, ""):          func() any { return &ScancodeEvent{} },
+	routeKey(MsgTypeEvent, EventScancodeWaitMsg, ""):       func() any { return &ScancodeEvent{} },
+	routeKey(MsgTypeEvent, EventLocationSelect, ""):        func() any { return &LocationSelectEvent{} },
+	routeKey(MsgTypeEvent, EventSysApprovalChange, ""):     func() any { return &SysApprovalChangeEvent{} },
+	routeKey(MsgTypeEvent, EventKfMsgOrEvent, ""):          func() any { return &KfMsgOrEvent{} },
+	routeKey(MsgTypeEvent, EventTemplateCardEvent, ""):     func() any { return &TemplateCardEvent{} },
+	routeKey(MsgTypeEvent, EventTemplateCardMenuEvent, ""): func() any { return &TemplateCardEvent{} },
+
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeCreateUser):  func() any { return &ChangeContactUserEvent{} },
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeUpdateUser):  func() any { return &ChangeContactUserEvent{} },
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeDeleteUser):  func() any { return &ChangeContactUserEvent{} },
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeCreateParty): func() any { return &ChangeContactPartyEvent{} },
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeUpdateParty): func() any { return &ChangeContactPartyEvent{} },
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeDeleteParty): func() any { return &ChangeContactPartyEvent{} },
+	routeKey(MsgTypeEvent, EventChangeContact, ChangeTypeUpdateTag):   func() any { return &ChangeContactTagEvent{} },
+
+	routeKey(MsgTypeEvent, EventChangeExternalContact, ""): func() any { return &ChangeExternalContactEvent{} },
+	routeKey(MsgTypeEvent, EventChangeExternalChat, ""):    func() any { return &ChangeExternalChatEvent{} },
+	routeKey(MsgTypeEvent, EventChangeExternalTag, ""):     func() any { return &ChangeExternalTagEvent{} },
+}
+
+// TextMessage 文本消息
+type TextMessage struct {
+	Header
+	// MsgID 消息id，64位整型
+	MsgID int64 `xml:"MsgId"`
+	// Content 文本消息内容
+	Content string `xml:"Content"`
+}
+
+// ImageMessage 图片消息
+type ImageMessage struct {
+	Header
+	// MsgID 消息id
+	MsgID int64 `xml:"MsgId"`
+	// PicURL 图片链接
+	PicURL string `xml:"PicUrl"`
+	// MediaID 图片媒体文件id，可以调用获取媒体文件接口拉取
+	MediaID string `xml:"MediaId"`
+}
+
+// VoiceMessage 语音消息
+type VoiceMessage struct {
+	Header
+	// MsgID 消息id
+	MsgID int64 `xml:"MsgId"`
+	// MediaID 语音媒体文件id
+	MediaID string `xml:"MediaId"`
+	// Format 语音格式，如amr，speex等
+	Format string `xml:"Format"`
+}
+
+// VideoMessage 视频消息
+type VideoMessage struct {
+	Header
+	// MsgID 消息id
+	MsgID int64 `xml:"MsgId"`
+	// MediaID 视频媒体文件id
+	MediaID string `xml:"MediaId"`
+	// ThumbMediaID 视频消息缩略图的媒体id
+	ThumbMediaID string `xml:"ThumbMediaId"`
+}
+
+// LocationMessage 位置消息
+type LocationMessage struct {
+	Header
+	// MsgID 消息id
+	MsgID int64 `xml:"MsgId"`
+	// LocationX 地理位置纬度
+	LocationX float64 `xml:"Location_X"`
+	// LocationY 地理位置经度
+	LocationY float64 `xml:"Location_Y"`
+	// Scale 地图缩放大小
+	Scale int `xml:"Scale"`
+	// Label 地理位置信息
+	Label string `xml:"Label"`
+	// AppType app类型，在企业微信固定返回wxwork
+	AppType string `xml:"AppType"`
+}
+
+// LinkMessage 链接消息
+type LinkMessage struct {
+	Header
+	// MsgID 消息id
+	MsgID int64 `xml:"MsgId"`
+	// Title 标题
+	Title string `xml:"Title"`
+	// Description 描述
+	Description string `xml:"Description"`
+	// URL 链接跳转的url
+	URL string `xml:"Url"`
+	// PicURL 封面缩略图的url
+	PicURL string `xml:"PicUrl"`
+}
+
+// SubscribeEvent 成员关注及取消关注事件
+type SubscribeEvent struct {
+	EventHeader
+}
+
+// EnterAgentEvent 进入应用事件
+type EnterAgentEvent struct {
+	EventHeader
+	// EventKey 事件KEY值，此事件该值为空
+	EventKey string `xml:"EventKey"`
+}
+
+// LocationEvent 上报地理位置事件
+type LocationEvent struct {
+	EventHeader
+	// Latitude 地理位置纬度
+	Latitude float64 `xml:"Latitude"`
+	// Longitude 地理位置经度
+	Longitude float64 `xml:"Longitude"`
+	// Precision 地理位置精度
+	Precision float64 `xml:"Precision"`
+	// AppType app类型
+	AppType string `xml:"AppType"`
+}
+
+// BatchJobResultEvent 异步任务完成事件
+type BatchJobResultEvent struct {
+	EventHeader
+	// BatchJob 异步任务信息
+	BatchJob struct {
+		// JobID 异步任务id
+		JobID string `xml:"JobId"`
+		// JobType 操作类型，sync_user/replace_user/invite_user/replace_party
+		JobType string `xml:"JobType"`
+		// ErrCode 返回码
+		ErrCode int `xml:"ErrCode"`
+		// ErrMsg 对返回码的文本描述内容
+		ErrMsg string `xml:"ErrMsg"`
+	} `xml:"BatchJob"`
+}
+
+// ClickEvent 点击菜单拉取消息的事件
+type ClickEvent struct {
+	EventHeader
+	// EventKey 与自定义菜单接口中KEY值对应
+	EventKey string `xml:"EventKey"`
+}
+
+// ViewEvent 点击菜单跳转链接的事件
+type ViewEvent struct {
+	EventHeader
+	// EventKey 设置的跳转URL
+	EventKey string `xml:"EventKey"`
+}
+
+// ScancodeEvent 扫码推事件
+type ScancodeEvent struct {
+	EventHeader
+	// EventKey 与自定义菜单接口中KEY值对应
+	EventKey string `xml:"EventKey"`
+	// ScanCodeInfo 扫描信息
+	ScanCodeInfo struct {
+		// ScanType 扫描类型，一般是qrcode
+		ScanType string `xml:"ScanType"`
+		// ScanResult 扫描结果，即二维码对应的字符串信息
+		ScanResult string `xml:"ScanResult"`
+	} `xml:"ScanCodeInfo"`
+}
+
+// LocationSelectEvent 弹出地理位置选择器的事件
+type LocationSelectEvent struct {
+	EventHeader
+	// EventKey 与自定义菜单接口中KEY值对应
+	EventKey string `xml:"EventKey"`
+	// SendLocationInfo 发送的位置信息
+	SendLocationInfo struct {
+		// LocationX X坐标信息
+		LocationX float64 `xml:"Location_X"`
+		// LocationY Y坐标信息
+		LocationY float64 `xml:"Location_Y"`
+		// Scale 精度
+		Scale int `xml:"Scale"`
+		// Label 地理位置的字符串信息
+		Label string `xml:"Label"`
+		// PoiName POI的名字
+		PoiName string `xml:"Poiname"`
+	} `xml:"SendLocationInfo"`
+}
+
+// ChangeContactUserEvent 成员变更事件（新增、更新、删除成员）
+type ChangeContactUserEvent struct {
+	EventHeader
+	// ChangeType 变更类型
+	ChangeType string `xml:"ChangeType"`
+	// UserID 成员UserID
+	UserID string `xml:"UserID"`
+	// NewUserID 新的UserID，变更时推送（userid由系统生成时可更改一次）
+	NewUserID string `xml:"NewUserID"`
+	// Name 成员名称
+	Name string `xml:"Name"`
+	// Department 成员部门列表，逗号分隔
+	Department string `xml:"Department"`
+	// MainDepartment 主部门
+	MainDepartment int64 `xml:"MainDepartment"`
+	// IsLeaderInDept 表示所在部门是否为部门负责人，逗号分隔
+	IsLeaderInDept string `xml:"IsLeaderInDept"`
+	// DirectLeader 直属上级UserID，逗号分隔
+	DirectLeader string `xml:"DirectLeader"`
+	// Position 职位信息
+	Position string `xml:"Position"`
+	// Mobile 手机号码
+	Mobile string `xml:"Mobile"`
+	// Gender 性别，1表示男性，2表示女性
+	Gender int `xml:"Gender"`
+	// Email 邮箱
+	Email string `xml:"Email"`
+	// BizMail 企业邮箱
+	BizMail string `xml:"BizMail"`
+	// Status 激活状态：1=已激活 2=已禁用 4=未激活 5=已退出企业
+	Status int `xml:"Status"`
+	// Avatar 头像url
+	Avatar string `xml:"Avatar"`
+	// Alias 成员别名
+	Alias string `xml:"Alias"`
+	// Telephone 座机
+	Telephone string `xml:"Telephone"`
+	// Address 地址
+	Address string `xml:"Address"`
+}
+
+// ChangeContactPartyEvent 部门变更事件（新增、更新、删除部门）
+type ChangeContactPartyEvent struct {
+	EventHeader
+	// ChangeType 变更类型
+	ChangeType string `xml:"ChangeType"`
+	// ID 部门Id
+	ID int64 `xml:"Id"`
+	// Name 部门名称
+	Name string `xml:"Name"`
+	// ParentID 父部门id
+	ParentID int64 `xml:"ParentId"`
+	// Order 部门排序
+	Order int64 `xml:"Order"`
+}
+
+// ChangeContactTagEvent 标签成员变更事件
+type ChangeContactTagEvent struct {
+	EventHeader
+	// ChangeType 变更类型，固定为update_tag
+	ChangeType string `xml:"ChangeType"`
+	// TagID 标签Id
+	TagID int64 `xml:"TagId"`
+	// AddUserItems 标签中新增的成员userid列表，用逗号分隔
+	AddUserItems string `xml:"AddUserItems"`
+	// DelUserItems 标签中删除的成员userid列表，用逗号分隔
+	DelUserItems string `xml:"DelUserItems"`
+	// AddPartyItems 标签中新增的部门id列表，用逗号分隔
+	AddPartyItems string `xml:"AddPartyItems"`
+	// DelPartyItems 标签中删除的部门id列表，用逗号分隔
+	DelPartyItems string `xml:"DelPartyItems"`
+}
+
+// ChangeExternalContactEvent 企业客户变更事件
+type ChangeExternalContactEvent struct {
+	EventHeader
+	// ChangeType 变更类型
+	ChangeType string `xml:"ChangeType"`
+	// UserID 企业服务人员的UserID
+	UserID string `xml:"UserID"`
+	// ExternalUserID 外部联系人的userid
+	ExternalUserID string `xml:"ExternalUserID"`
+	// State 添加此用户的「联系我」方式配置的state参数
+	State string `xml:"State"`
+	// WelcomeCode 欢迎语code，可用于发送欢迎语
+	WelcomeCode string `xml:"WelcomeCode"`
+	// Source 删除客户的操作来源，DELETE_BY_TRANSFER表示由于在职继承而被删除
+	Source string `xml:"Source"`
+	// FailReason 接替失败的原因，customer_refused/customer_limit_exceed
+	FailReason string `xml:"FailReason"`
+}
+
+// ChangeExternalChatEvent 客户群变更事件
+type ChangeExternalChatEvent struct {
+	EventHeader
+	// ChangeType 变更类型，create/update/dismiss
+	ChangeType string `xml:"ChangeType"`
+	// ChatID 群ID
+	ChatID string `xml:"ChatId"`
+	// UpdateDetail 变更详情
+	UpdateDetail string `xml:"UpdateDetail"`
+	// JoinScene 成员入群方式
+	JoinScene int `xml:"JoinScene"`
+	// QuitScene 成员退群方式
+	QuitScene int `xml:"QuitScene"`
+	// MemChangeCnt 成员变更数量
+	MemChangeCnt int `xml:"MemChangeCnt"`
+	// MemChangeList 变更的成员列表
+	MemChangeList []string `xml:"MemChangeList>Item"`
+	// LastMemVer 变更前的群成员版本号
+	LastMemVer string `xml:"LastMemVer"`
+	// CurMemVer 变更后的群成员版本号
+	CurMemVer string `xml:"CurMemVer"`
+}
+
+// ChangeExternalTagEvent 企业客户标签变更事件
+type ChangeExternalTagEvent struct {
+	EventHeader
+	// ChangeType 变更类型，create/update/delete/shuffle
+	ChangeType string `xml:"ChangeType"`
+	// ID 标签或标签组的ID
+	ID string `xml:"Id"`
+	// TagType 类型，tag或tag_group
+	TagType string `xml:"TagType"`
+	// StrategyID 规则组id
+	StrategyID int64 `xml:"StrategyId"`
+}
+
+// SysApprovalChangeEvent 审批申请状态变化事件
+type SysApprovalChangeEvent struct {
+	EventHeader
+	// ApprovalInfo 审批信息
+	ApprovalInfo ApprovalInfo `xml:"ApprovalInfo"`
+}
+
+// ApprovalInfo 审批申请状态变化回调的审批信息
+type ApprovalInfo struct {
+	// SpNo 审批编号
+	SpNo string `xml:"SpNo"`
+	// SpName 审批申请类型名称
+	SpName string `xml:"SpName"`
+	// SpStatus 申请单状态：1-审批中；2-已通过；3-已驳回；4-已撤销；6-通过后撤销；7-已删除；10-已支付
+	SpStatus int `xml:"SpStatus"`
+	// TemplateID 审批模板id
+	TemplateID string `xml:"TemplateId"`
+	// ApplyTime 审批申请提交时间
+	ApplyTime int64 `xml:"ApplyTime"`
+	// Applyer 申请人信息
+	Applyer struct {
+		// UserID 申请人userid
+		UserID string `xml:"UserId"`
+		// Party 申请人所在部门pid
+		Party string `xml:"Party"`
+	} `xml:"Applyer"`
+	// StatuChangeEvent 审批申请状态变化类型：1-提单；2-同意；3-驳回；4-转审；5-催办；6-撤销；8-通过后撤销；10-添加备注
+	StatuChangeEvent int `xml:"StatuChangeEvent"`
+}
+
+// KfMsgOrEvent 微信客服消息与事件通知
+// 收到后需调用 kf/sync_msg 接口拉取具体的消息内容
+type KfMsgOrEvent struct {
+	EventHeader
+	// Token 调用拉取消息接口时需要传此token，用于校验请求的合法性
+	Token string `xml:"Token"`
+	// OpenKfID 有新消息的客服账号
+	OpenKfID string `xml:"OpenKfId"`
+}
+
+// TemplateCardEvent 模板卡片事件（按钮点击、投票、右上角菜单等）
+type TemplateCardEvent struct {
+	EventHeader
+	// EventKey 与发送模板卡片消息时指定的按钮btn:key值相同
+	EventKey string `xml:"EventKey"`
+	// TaskID 与发送模板卡片消息时指定的task_id相同
+	TaskID string `xml:"TaskId"`
+	// CardType 通用模板卡片的类型
+	CardType string `xml:"CardType"`
+	// ResponseCode 用于调用更新卡片接口的ResponseCode，72小时内有效，且只能使用一次
+	ResponseCode string `xml:"ResponseCode"`
+	// SelectedItems 用户点击提交的选择类数据
+	SelectedItems []SelectedItem `xml:"SelectedItems>SelectedItem"`
+}
+
+// SelectedItem 模板卡片选择项
+type SelectedItem struct {
+	// QuestionKey 问题的key值
+	QuestionKey string `xml:"QuestionKey"`
+	// OptionIDs 对应问题的选项列表
+	OptionIDs []string `xml:"OptionIds>OptionId"`
+}
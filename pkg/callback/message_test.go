@@ -0,0 +1,59 @@
+package callback
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMessage_Location(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		raw    string
+	}{
+		{"xml", FormatXML, `<xml><ToUserName><![CDATA[corp]]></ToUserName><FromUserName><![CDATA[zhangsan]]></FromUserName>
+			<CreateTime>1348831860</CreateTime><MsgType><![CDATA[location]]></MsgType><MsgId>1234567890123456</MsgId>
+			<Location_X>23.134521</Location_X><Location_Y>113.358803</Location_Y><Scale>20</Scale>
+			<Label><![CDATA[位置信息]]></Label><AgentID>1000002</AgentID></xml>`},
+		{"json", FormatJSON, `{"ToUserName":"corp","FromUserName":"zhangsan","CreateTime":1348831860,"MsgType":"location",
+			"MsgId":1234567890123456,"Location_X":23.134521,"Location_Y":113.358803,"Scale":20,
+			"Label":"位置信息","AgentID":1000002}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseMessage([]byte(tt.raw), tt.format)
+			require.NoError(t, err)
+			assert.Equal(t, "zhangsan", msg.FromUserName)
+			assert.Equal(t, int64(1000002), msg.AgentID)
+
+			loc, ok := msg.Payload.(*LocationMessage)
+			require.True(t, ok)
+			assert.Equal(t, int64(1234567890123456), loc.MsgID)
+			assert.Equal(t, 23.134521, loc.LocationX)
+			assert.Equal(t, 113.358803, loc.LocationY)
+			assert.Equal(t, 20, loc.Scale)
+			assert.Equal(t, "位置信息", loc.Label)
+		})
+	}
+}
+
+func TestParseMessage_JSONNestedFields(t *testing.T) {
+	raw := `{"ToUserName":"corp","MsgType":"event","Event":"change_external_chat","ChangeType":"update",
+		"ChatId":"wrOgQhDgAAMYQiS5ol9G7gK9JVAAAA","UpdateDetail":"add_member","JoinScene":1,"MemChangeCnt":2,
+		"MemChangeList":{"Item":["zhangsan","lisi"]},"Extra":null}`
+
+	msg, err := ParseMessage([]byte(raw), FormatJSON)
+	require.NoError(t, err)
+
+	event, ok := msg.Payload.(*ChangeExternalChatEvent)
+	require.True(t, ok)
+	assert.Equal(t, "wrOgQhDgAAMYQiS5ol9G7gK9JVAAAA", event.ChatID)
+	assert.Equal(t, "add_member", event.UpdateDetail)
+	assert.Equal(t, []string{"zhangsan", "lisi"}, event.MemChangeList)
+
+	_, err = ParseMessage([]byte(`{"MsgType":`), FormatJSON)
+	assert.Error(t, err)
+}
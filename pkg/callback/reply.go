@@ -0,0 +1,345 @@
+package callback
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shuaidd/wecom-core/types/message"
+)
+
+// ErrReplyFormatUnsupported JSON 格式的回调不支持被动回复
+var ErrReplyFormatUnsupported = errors.New("callback: passive reply is only supported for xml callbacks")
+
+// 被动回复消息类型
+const (
+	// ReplyTypeText 文本消息
+	ReplyTypeText = "text"
+	// ReplyTypeImage 图片消息
+	ReplyTypeImage = "image"
+	// ReplyTypeVoice 语音消息
+	ReplyTypeVoice = "voice"
+	// ReplyTypeVideo 视频消息
+	ReplyTypeVideo = "video"
+	// ReplyTypeNews 图文消息
+	ReplyTypeNews = "news"
+	// ReplyTypeUpdateButton 更新模板卡片按钮为不可点击状态
+	ReplyTypeUpdateButton = "update_button"
+	// ReplyTypeUpdateTemplateCard 更新为新的模板卡片
+	ReplyTypeUpdateTemplateCard = "update_template_card"
+)
+
+// Reply 被动回复消息
+// 文档: https://developer.work.weixin.qq.com/document/path/90241
+type Reply interface {
+	// MsgType 回复的消息类型
+	MsgType() string
+	// encodeBody 写入消息类型相关的字段
+	encodeBody(e *xml.Encoder) error
+}
+
+// TextReply 文本回复
+type TextReply struct {
+	message.TextMessage
+}
+
+// NewTextReply 创建文本回复
+func NewTextReply(content string) *TextReply {
+	return &TextReply{TextMessage: message.TextMessage{Content: content}}
+}
+
+// MsgType 实现 Reply
+func (r *TextReply) MsgType() string { return ReplyTypeText }
+
+func (r *TextReply) encodeBody(e *xml.Encoder) error {
+	return encodeCDATA(e, "Content", r.Content)
+}
+
+// ImageReply 图片回复
+type ImageReply struct {
+	message.MediaMessage
+}
+
+// NewImageReply 创建图片回复
+func NewImageReply(mediaID string) *ImageReply {
+	return &ImageReply{MediaMessage: message.MediaMessage{MediaID: mediaID}}
+}
+
+// MsgType 实现 Reply
+func (r *ImageReply) MsgType() string { return ReplyTypeImage }
+
+func (r *ImageReply) encodeBody(e *xml.Encoder) error {
+	return encodeMedia(e, "Image", r.MediaID)
+}
+
+// VoiceReply 语音回复
+type VoiceReply struct {
+	message.MediaMessage
+}
+
+// NewVoiceReply 创建语音回复
+func NewVoiceReply(mediaID string) *VoiceReply {
+	return &VoiceReply{MediaMessage: message.MediaMessage{MediaID: mediaID}}
+}
+
+// MsgType 实现 Reply
+func (r *VoiceReply) MsgType() string { return ReplyTypeVoice }
+
+func (r *VoiceReply) encodeBody(e *xml.Encoder) error {
+	return encodeMedia(e, "Voice", r.MediaID)
+}
+
+// VideoReply 视频回复
+type VideoReply struct {
+	message.VideoMessage
+}
+
+// NewVideoReply 创建视频回复
+func NewVideoReply(mediaID, title, description string) *VideoReply {
+	return &VideoReply{VideoMessage: message.VideoMessage{
+		MediaID:     mediaID,
+		Title:       title,
+		Description: description,
+	}}
+}
+
+// MsgType 实现 Reply
+func (r *VideoReply) MsgType() string { return ReplyTypeVideo }
+
+func (r *VideoReply) encodeBody(e *xml.Encoder) error {
+	return e.EncodeElement(struct {
+		MediaID     cdata `xml:"MediaId"`
+		Title       cdata `xml:"Title"`
+		Description cdata `xml:"Description"`
+	}{cdata{r.MediaID}, cdata{r.Title}, cdata{r.Description}}, start("Video"))
+}
+
+// NewsReply 图文回复，最多8条图文
+type NewsReply struct {
+	Articles []message.NewsArticle
+}
+
+// NewNewsReply 创建图文回复
+func NewNewsReply(articles ...message.NewsArticle) *NewsReply {
+	return &NewsReply{Articles: articles}
+}
+
+// MsgType 实现 Reply
+func (r *NewsReply) MsgType() string { return ReplyTypeNews }
+
+func (r *NewsReply) encodeBody(e *xml.Encoder) error {
+	if len(r.Articles) == 0 || len(r.Articles) > 8 {
+		return fmt.Errorf("news reply supports 1 to 8 articles, got %d", len(r.Articles))
+	}
+
+	type item struct {
+		Title       cdata `xml:"Title"`
+		Description cdata `xml:"Description"`
+		PicURL      cdata `xml:"PicUrl"`
+		URL         cdata `xml:"Url"`
+	}
+	items := make([]item, 0, len(r.Articles))
+	for _, a := range r.Articles {
+		items = append(items, item{cdata{a.Title}, cdata{a.Description}, cdata{a.PicURL}, cdata{a.URL}})
+	}
+
+	if err := e.EncodeElement(len(items), start("ArticleCount")); err != nil {
+		return err
+	}
+	return e.EncodeElement(struct {
+		Items []item `xml:"item"`
+	}{items}, start("Articles"))
+}
+
+// UpdateButtonReply 将模板卡片的按钮更新为不可点击状态
+// 仅可在收到模板卡片事件（template_card_event）时回复
+type UpdateButtonReply struct {
+	message.UpdateButton
+}
+
+// NewUpdateButtonReply 创建更新按钮回复
+func NewUpdateButtonReply(replaceName string) *UpdateButtonReply {
+	return &UpdateButtonReply{UpdateButton: message.UpdateButton{ReplaceName: replaceName}}
+}
+
+// MsgType 实现 Reply
+func (r *UpdateButtonReply) MsgType() string { return ReplyTypeUpdateButton }
+
+func (r *UpdateButtonReply) encodeBody(e *xml.Encoder) error {
+	return e.EncodeElement(struct {
+		ReplaceName cdata `xml:"ReplaceName"`
+	}{cdata{r.ReplaceName}}, start("Button"))
+}
+
+// TemplateCardReply 将模板卡片更新为新的卡片
+// 仅可在收到模板卡片事件（template_card_event）时回复
+type TemplateCardReply struct {
+	// TemplateCard 新的卡片内容，与发送模板卡片消息的结构一致
+	TemplateCard *message.TemplateCardMessage
+}
+
+// NewTemplateCardReply 创建更新模板卡片回复
+func NewTemplateCardReply(card *message.TemplateCardMessage) *TemplateCardReply {
+	return &TemplateCardReply{TemplateCard: card}
+}
+
+// MsgType 实现 Reply
+func (r *TemplateCardReply) MsgType() string { return ReplyTypeUpdateTemplateCard }
+
+func (r *TemplateCardReply) encodeBody(e *xml.Encoder) error {
+	if r.TemplateCard == nil {
+		return errors.New("template card reply requires a template card")
+	}
+	data, err := json.Marshal(r.TemplateCard)
+	if err != nil {
+		return fmt.Errorf("failed to marshal template card: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return jsonToXML(dec, e, "TemplateCard", xmlName)
+}
+
+// BuildReply 生成被动回复的消息明文（XML）
+// 收发双方取自回调消息：ToUserName 为消息发送者，FromUserName 为 CorpID
+func BuildReply(msg *Message, reply Reply) ([]byte, error) {
+	if msg.Format == FormatJSON {
+		return nil, ErrReplyFormatUnsupported
+	}
+
+	var buf bytes.Buffer
+	e := xml.NewEncoder(&buf)
+
+	root := start("xml")
+	if err := e.EncodeToken(root); err != nil {
+		return nil, err
+	}
+	if err := encodeCDATA(e, "ToUserName", msg.FromUserName); err != nil {
+		return nil, err
+	}
+	if err := encodeCDATA(e, "FromUserName", msg.ToUserName); err != nil {
+		return nil, err
+	}
+	if err := e.EncodeElement(time.Now().Unix(), start("CreateTime")); err != nil {
+		return nil, err
+	}
+	if err := encodeCDATA(e, "MsgType", reply.MsgType()); err != nil {
+		return nil, err
+	}
+	if err := reply.encodeBody(e); err != nil {
+		return nil, err
+	}
+	if err := e.EncodeToken(root.End()); err != nil {
+		return nil, err
+	}
+	if err := e.Flush(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// EncryptReply 生成并加密被动回复，返回可直接响应的加密信封
+func (c *Crypto) EncryptReply(msg *Message, reply Reply, timestamp, nonce string) ([]byte, error) {
+	plain, err := BuildReply(msg, reply)
+	if err != nil {
+		return nil, err
+	}
+	return c.EncryptMsg(plain, timestamp, nonce)
+}
+
+// start 创建 XML 起始元素
+func start(name string) xml.StartElement {
+	return xml.StartElement{Name: xml.Name{Local: name}}
+}
+
+// encodeCDATA 写入 CDATA 元素
+func encodeCDATA(e *xml.Encoder, name, value string) error {
+	return e.EncodeElement(cdata{value}, start(name))
+}
+
+// encodeMedia 写入包含 MediaId 的元素
+func encodeMedia(e *xml.Encoder, name, mediaID string) error {
+	return e.EncodeElement(struct {
+		MediaID cdata `xml:"MediaId"`
+	}{cdata{mediaID}}, start(name))
+}
+
+// xmlNameOverrides JSON 字段名到 XML 元素名的特殊映射
+var xmlNameOverrides = map[string]string{
+	"keyname":  "KeyName",
+	"appid":    "AppId",
+	"pagepath": "PagePath",
+	"userid":   "UserId",
+}
+
+// xmlName 将 JSON 字段名（snake_case）转换为 XML 元素名（PascalCase）
+func xmlName(key string) string {
+	if name, ok := xmlNameOverrides[key]; ok {
+		return name
+	}
+	parts := strings.Split(key, "_")
+	for i, p := range parts {
+		if p != "" {
+			parts[i] = strings.ToUpper(p[:1]) + p[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// jsonToXML 将 JSON 值转换为 XML，rename 将 JSON 字段名转换为 XML 元素名
+// 对象转换为子元素，数组转换为同名的重复元素
+func jsonToXML(dec *json.Decoder, e *xml.Encoder, name string, rename func(string) string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch v := tok.(type) {
+	case json.Delim:
+		switch v {
+		case '{':
+			el := start(name)
+			if err := e.EncodeToken(el); err != nil {
+				return err
+			}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				key, _ := keyTok.(string)
+				if err := jsonToXML(dec, e, rename(key), rename); err != nil {
+					return err
+				}
+			}
+			if _, err := dec.Token(); err != nil && err != io.EOF {
+				return err
+			}
+			return e.EncodeToken(el.End())
+		case '[':
+			for dec.More() {
+				if err := jsonToXML(dec, e, name, rename); err != nil {
+					return err
+				}
+			}
+			_, err := dec.Token()
+			return err
+		}
+		return fmt.Errorf("unexpected json delimiter %v", v)
+	case string:
+		return encodeCDATA(e, name, v)
+	case json.Number:
+		return e.EncodeElement(v.String(), start(name))
+	case bool:
+		return e.EncodeElement(strconv.FormatBool(v), start(name))
+	case nil:
+		return nil
+	}
+	return fmt.Errorf("unexpected json token %v", tok)
+}
@@ -0,0 +1,177 @@
+package callback
+
+import (
+	"context"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/types/message"
+)
+
+func testMessage() *Message {
+	return &Message{EventHeader: EventHeader{Header: Header{
+		ToUserName:   "corp",
+		FromUserName: "zhangsan",
+	}}}
+}
+
+func TestBuildReply_Text(t *testing.T) {
+	data, err := BuildReply(testMessage(), NewTextReply("hello"))
+	require.NoError(t, err)
+
+	var got struct {
+		ToUserName   string `xml:"ToUserName"`
+		FromUserName string `xml:"FromUserName"`
+		MsgType      string `xml:"MsgType"`
+		Content      string `xml:"Content"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &got))
+	assert.Equal(t, "zhangsan", got.ToUserName)
+	assert.Equal(t, "corp", got.FromUserName)
+	assert.Equal(t, ReplyTypeText, got.MsgType)
+	assert.Equal(t, "hello", got.Content)
+	assert.Contains(t, string(data), "<Content><![CDATA[hello]]></Content>")
+}
+
+func TestBuildReply_News(t *testing.T) {
+	data, err := BuildReply(testMessage(), NewNewsReply(
+		message.NewsArticle{Title: "a", URL: "https://a"},
+		message.NewsArticle{Title: "b", PicURL: "https://b.png"},
+	))
+	require.NoError(t, err)
+
+	var got struct {
+		ArticleCount int `xml:"ArticleCount"`
+		Articles     []struct {
+			Title  string `xml:"Title"`
+			URL    string `xml:"Url"`
+			PicURL string `xml:"PicUrl"`
+		} `xml:"Articles>item"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &got))
+	assert.Equal(t, 2, got.ArticleCount)
+	require.Len(t, got.Articles, 2)
+	assert.Equal(t, "https://a", got.Articles[0].URL)
+	assert.Equal(t, "https://b.png", got.Articles[1].PicURL)
+
+	_, err = BuildReply(testMessage(), NewNewsReply())
+	assert.Error(t, err)
+}
+
+func TestBuildReply_TemplateCard(t *testing.T) {
+	card := &message.TemplateCardMessage{
+		CardType:  message.TemplateCardTypeButtonInteraction,
+		Source:    &message.CardSource{IconURL: "https://icon", Desc: "来源"},
+		MainTitle: &message.CardMainTitle{Title: "标题"},
+		TaskID:    "task_1",
+		ButtonList: []message.CardButton{
+			{Text: "同意", Style: 1, Key: "agree"},
+			{Text: "拒绝", Style: 2, Key: "reject"},
+		},
+		HorizontalContentList: []message.CardHorizontalContent{{KeyName: "申请人", Value: "张三"}},
+	}
+	data, err := BuildReply(testMessage(), NewTemplateCardReply(card))
+	require.NoError(t, err)
+
+	var got struct {
+		MsgType      string `xml:"MsgType"`
+		TemplateCard struct {
+			CardType string `xml:"CardType"`
+			TaskID   string `xml:"TaskId"`
+			Source   struct {
+				IconURL string `xml:"IconUrl"`
+			} `xml:"Source"`
+			ButtonList []struct {
+				Text  string `xml:"Text"`
+				Style int    `xml:"Style"`
+				Key   string `xml:"Key"`
+			} `xml:"ButtonList"`
+			HorizontalContentList []struct {
+				KeyName string `xml:"KeyName"`
+			} `xml:"HorizontalContentList"`
+		} `xml:"TemplateCard"`
+	}
+	require.NoError(t, xml.Unmarshal(data, &got))
+	assert.Equal(t, ReplyTypeUpdateTemplateCard, got.MsgType)
+	assert.Equal(t, "button_interaction", got.TemplateCard.CardType)
+	assert.Equal(t, "task_1", got.TemplateCard.TaskID)
+	assert.Equal(t, "https://icon", got.TemplateCard.Source.IconURL)
+	require.Len(t, got.TemplateCard.ButtonList, 2)
+	assert.Equal(t, "reject", got.TemplateCard.ButtonList[1].Key)
+	assert.Equal(t, 2, got.TemplateCard.ButtonList[1].Style)
+	assert.Equal(t, "申请人", got.TemplateCard.HorizontalContentList[0].KeyName)
+}
+
+func TestBuildReply_JSONUnsupported(t *testing.T) {
+	msg := testMessage()
+	msg.Format = FormatJSON
+	_, err := BuildReply(msg, NewTextReply("hello"))
+	assert.ErrorIs(t, err, ErrReplyFormatUnsupported)
+}
+
+func TestHandler_PassiveReply(t *testing.T) {
+	h := newTestHandler(t)
+	crypto, err := NewCrypto(testToken, testEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+
+	h.HandleMessage(MsgTypeText, Typed(func(ctx context.Context, m *TextMessage) (Reply, error) {
+		return NewTextReply("echo: " + m.Content), nil
+	}))
+
+	rec := postMessage(t, h, crypto, "1000002", `<xml><ToUserName>corp</ToUserName>
+<FromUserName>zhangsan</FromUserName><MsgType>text</MsgType><Content>hi</Content></xml>`)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var envelope struct {
+		Encrypt      string `xml:"Encrypt"`
+		MsgSignature string `xml:"MsgSignature"`
+		TimeStamp    string `xml:"TimeStamp"`
+		Nonce        string `xml:"Nonce"`
+	}
+	require.NoError(t, xml.Unmarshal(rec.Body.Bytes(), &envelope))
+	assert.True(t, crypto.VerifySignature(envelope.MsgSignature, envelope.TimeStamp, envelope.Nonce, envelope.Encrypt))
+
+	plain, err := crypto.Decrypt(envelope.Encrypt)
+	require.NoError(t, err)
+	reply, err := ParseMessage(plain, FormatXML)
+	require.NoError(t, err)
+	require.IsType(t, &TextMessage{}, reply.Payload)
+	assert.Equal(t, "echo: hi", reply.Payload.(*TextMessage).Content)
+	assert.Equal(t, "zhangsan", reply.ToUserName)
+}
+
+func TestHandler_PassiveReplyJSONCallback(t *testing.T) {
+	h := newTestHandler(t)
+	crypto, err := NewCrypto(testToken, testEncodingAESKey, testReceiveID)
+	require.NoError(t, err)
+
+	calls := 0
+	h.HandleMessage(MsgTypeText, Typed(func(ctx context.Context, m *TextMessage) (Reply, error) {
+		calls++
+		return NewTextReply("echo: " + m.Content), nil
+	}))
+
+	encrypt, err := crypto.Encrypt([]byte(`{"ToUserName":"corp","FromUserName":"zhangsan","CreateTime":1348831860,` +
+		`"MsgType":"text","Content":"hello","MsgId":1,"AgentID":1000002}`))
+	require.NoError(t, err)
+	body := `{"tousername":"` + testReceiveID + `","encrypt":"` + encrypt + `","agentid":1000002}`
+	q := url.Values{
+		"msg_signature": {crypto.Signature("1409659589", "nonce", encrypt)},
+		"timestamp":     {"1409659589"},
+		"nonce":         {"nonce"},
+	}
+
+	// JSON 格式不支持被动回复：处理函数只执行一次，以空包响应避免企业微信重试推送
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/callback?"+q.Encode(), strings.NewReader(body)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,54 @@
+package msgaudit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shuaidd/wecom-core/types/msgaudit"
+)
+
+// DecryptFunc 使用解密后的随机密钥解密消息内容
+// 对应会话存档SDK的 DecryptData(encrypt_key, encrypt_msg) 接口，由调用方接入官方库实现
+type DecryptFunc func(randomKey, encryptChatMsg string) ([]byte, error)
+
+// Decoder 会话记录解码器
+type Decoder struct {
+	keys    *Keyring
+	decrypt DecryptFunc
+}
+
+// NewDecoder 创建会话记录解码器
+func NewDecoder(keys *Keyring, decrypt DecryptFunc) *Decoder {
+	return &Decoder{
+		keys:    keys,
+		decrypt: decrypt,
+	}
+}
+
+// Decode 解密并解析一条会话记录
+func (d *Decoder) Decode(chat *msgaudit.ChatData) (*msgaudit.ChatMessage, error) {
+	randomKey, err := d.keys.DecryptRandomKey(chat.PublicKeyVer, chat.EncryptRandomKey)
+	if err != nil {
+		return nil, fmt.Errorf("msgid %s: %w", chat.MsgID, err)
+	}
+
+	plaintext, err := d.decrypt(randomKey, chat.EncryptChatMsg)
+	if err != nil {
+		return nil, fmt.Errorf("msgid %s: failed to decrypt chat msg: %w", chat.MsgID, err)
+	}
+
+	msg, err := ParseMessage(plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("msgid %s: %w", chat.MsgID, err)
+	}
+	return msg, nil
+}
+
+// ParseMessage 解析解密后的会话消息
+func ParseMessage(data []byte) (*msgaudit.ChatMessage, error) {
+	var msg msgaudit.ChatMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal chat message: %w", err)
+	}
+	return &msg, nil
+}
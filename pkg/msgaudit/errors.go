@@ -0,0 +1,14 @@
+package msgaudit
+
+import "errors"
+
+var (
+	// ErrInvalidPrivateKey 私钥格式错误（应为 PEM 格式的 PKCS#1 或 PKCS#8 RSA 私钥）
+	ErrInvalidPrivateKey = errors.New("msgaudit: invalid rsa private key")
+
+	// ErrUnknownKeyVersion 没有对应版本的私钥
+	ErrUnknownKeyVersion = errors.New("msgaudit: unknown publickey_ver")
+
+	// ErrInvalidRandomKey encrypt_random_key 解密失败
+	ErrInvalidRandomKey = errors.New("msgaudit: invalid encrypt_random_key")
+)
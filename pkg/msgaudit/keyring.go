@@ -0,0 +1,110 @@
+// Package msgaudit 提供会话内容存档的密钥管理与消息解码
+//
+// 会话存档SDK（官方 C 库）负责拉取加密记录并解密消息内容，本包负责其余纯 Go 的部分：
+// 使用按版本管理的 RSA 私钥解密 encrypt_random_key，并把解密后的消息解析为
+// types/msgaudit 中的结构体。
+//
+// 示例：
+//
+//	keys := msgaudit.NewKeyring()
+//	_ = keys.AddPEM(1, privateKeyV1)
+//	_ = keys.AddPEM(2, privateKeyV2)
+//
+//	dec := msgaudit.NewDecoder(keys, sdk.DecryptData)
+//	for _, chat := range resp.ChatData {
+//	    msg, err := dec.Decode(&chat)
+//	    ...
+//	}
+//
+// 文档: https://developer.work.weixin.qq.com/document/path/91360
+package msgaudit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"sync"
+)
+
+// ParsePrivateKey 解析 PEM 格式的 RSA 私钥，支持 PKCS#1 与 PKCS#8
+func ParsePrivateKey(pemData []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, ErrInvalidPrivateKey
+	}
+
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%w: not an rsa key", ErrInvalidPrivateKey)
+	}
+	return key, nil
+}
+
+// Keyring 按版本号管理的会话存档私钥
+// 管理端更新公钥后版本号递增，历史消息仍使用旧版本公钥加密，因此需要同时保留旧版本私钥
+type Keyring struct {
+	mu   sync.RWMutex
+	keys map[int]*rsa.PrivateKey
+}
+
+// NewKeyring 创建私钥集合
+func NewKeyring() *Keyring {
+	return &Keyring{
+		keys: make(map[int]*rsa.PrivateKey),
+	}
+}
+
+// Add 添加指定版本的私钥
+func (k *Keyring) Add(version int, key *rsa.PrivateKey) *Keyring {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[version] = key
+	return k
+}
+
+// AddPEM 添加指定版本的 PEM 格式私钥
+func (k *Keyring) AddPEM(version int, pemData []byte) error {
+	key, err := ParsePrivateKey(pemData)
+	if err != nil {
+		return err
+	}
+	k.Add(version, key)
+	return nil
+}
+
+// Key 返回指定版本的私钥
+func (k *Keyring) Key(version int) (*rsa.PrivateKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[version]
+	return key, ok
+}
+
+// DecryptRandomKey 使用对应版本的私钥解密 encrypt_random_key
+// encrypted 为会话存档SDK返回的 base64 字符串，使用 RSA PKCS#1 v1.5 加密
+func (k *Keyring) DecryptRandomKey(version int, encrypted string) (string, error) {
+	key, ok := k.Key(version)
+	if !ok {
+		return "", fmt.Errorf("%w: %d", ErrUnknownKeyVersion, version)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRandomKey, err)
+	}
+	plaintext, err := rsa.DecryptPKCS1v15(rand.Reader, key, ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidRandomKey, err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,205 @@
+package msgaudit
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/types/msgaudit"
+)
+
+func newTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return key
+}
+
+func encryptRandomKey(t *testing.T, key *rsa.PrivateKey, randomKey string) string {
+	t.Helper()
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte(randomKey))
+	require.NoError(t, err)
+	return base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	key := newTestKey(t)
+
+	pkcs1 := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	parsed, err := ParsePrivateKey(pkcs1)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	pkcs8 := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	parsed, err = ParsePrivateKey(pkcs8)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = ParsePrivateKey([]byte("not a pem"))
+	assert.ErrorIs(t, err, ErrInvalidPrivateKey)
+}
+
+func TestKeyring_DecryptRandomKey(t *testing.T) {
+	v1, v2 := newTestKey(t), newTestKey(t)
+	keys := NewKeyring().Add(1, v1).Add(2, v2)
+
+	got, err := keys.DecryptRandomKey(1, encryptRandomKey(t, v1, "random-key-v1"))
+	require.NoError(t, err)
+	assert.Equal(t, "random-key-v1", got)
+
+	got, err = keys.DecryptRandomKey(2, encryptRandomKey(t, v2, "random-key-v2"))
+	require.NoError(t, err)
+	assert.Equal(t, "random-key-v2", got)
+
+	// 版本不匹配
+	_, err = keys.DecryptRandomKey(2, encryptRandomKey(t, v1, "random-key-v1"))
+	assert.ErrorIs(t, err, ErrInvalidRandomKey)
+
+	_, err = keys.DecryptRandomKey(3, encryptRandomKey(t, v1, "random-key-v1"))
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	key := newTestKey(t)
+	dec := NewDecoder(NewKeyring().Add(1, key), func(randomKey, encryptChatMsg string) ([]byte, error) {
+		if randomKey != "random-key" {
+			return nil, errors.New("wrong key")
+		}
+		return []byte(encryptChatMsg), nil
+	})
+
+	msg, err := dec.Decode(&msgaudit.ChatData{
+		Seq:              1,
+		MsgID:            "msg-1",
+		PublicKeyVer:     1,
+		EncryptRandomKey: encryptRandomKey(t, key, "random-key"),
+		EncryptChatMsg:   `{"msgid":"msg-1","action":"send","from":"zhangsan","tolist":["lisi"],"roomid":"","msgtime":1547087894783,"msgtype":"text","text":{"content":"test"}}`,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, msgaudit.ChatActionSend, msg.Action)
+	assert.Equal(t, msgaudit.ChatMsgTypeText, msg.MsgType)
+	assert.Equal(t, []string{"lisi"}, msg.ToList)
+	require.NotNil(t, msg.Text)
+	assert.Equal(t, "test", msg.Text.Content)
+	assert.NotEmpty(t, msg.Raw)
+
+	_, err = dec.Decode(&msgaudit.ChatData{MsgID: "msg-2", PublicKeyVer: 9})
+	assert.ErrorIs(t, err, ErrUnknownKeyVersion)
+}
+
+func TestParseMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		data  string
+		check func(t *testing.T, msg *msgaudit.ChatMessage)
+	}{
+		{
+			name: "image",
+			data: `{"msgid":"1","action":"send","msgtype":"image","image":{"md5sum":"50de8e5ae8ffe4f1df7a93841f71993a","filesize":70961,"sdkfileid":"CtYBMzA2OTAy"}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Image)
+				assert.Equal(t, int64(70961), msg.Image.FileSize)
+				assert.Equal(t, "CtYBMzA2OTAy", msg.Image.SDKFileID)
+			},
+		},
+		{
+			name: "revoke",
+			data: `{"msgid":"2","action":"recall","msgtype":"revoke","revoke":{"pre_msgid":"14822339130656386894_1603875609"}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				assert.Equal(t, msgaudit.ChatActionRecall, msg.Action)
+				require.NotNil(t, msg.Revoke)
+				assert.Equal(t, "14822339130656386894_1603875609", msg.Revoke.PreMsgID)
+			},
+		},
+		{
+			name: "agree",
+			data: `{"msgid":"3","action":"send","msgtype":"agree","agree":{"userid":"wmeDKaCQAAGd9oGiQWxVsAKwV2HxNAAA","agree_time":1588116616000}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Agree)
+				assert.Equal(t, int64(1588116616000), msg.Agree.AgreeTime)
+			},
+		},
+		{
+			name: "card",
+			data: `{"msgid":"4","action":"send","msgtype":"card","card":{"corpname":"微信联系人","userid":"wmO6tnBwAAJJ5Ng"}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Card)
+				assert.Equal(t, "微信联系人", msg.Card.CorpName)
+			},
+		},
+		{
+			name: "markdown",
+			data: `{"msgid":"5","action":"send","msgtype":"markdown","info":{"content":"**告警**"}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Markdown)
+				assert.Equal(t, "**告警**", msg.Markdown.Content)
+				assert.Nil(t, msg.News)
+			},
+		},
+		{
+			name: "news",
+			data: `{"msgid":"6","action":"send","msgtype":"news","info":{"item":[{"title":"标题","description":"描述","url":"https://example.com","picurl":"https://example.com/a.png"}]}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.News)
+				require.Len(t, msg.News.Item, 1)
+				assert.Equal(t, "标题", msg.News.Item[0].Title)
+				assert.Nil(t, msg.Markdown)
+			},
+		},
+		{
+			name: "mixed",
+			data: `{"msgid":"7","action":"send","msgtype":"mixed","mixed":{"item":[{"type":"text","content":"{\"content\":\"你好\"}"},{"type":"image","content":"{\"md5sum\":\"abc\",\"filesize\":1,\"sdkfileid\":\"id\"}"}]}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Mixed)
+				require.Len(t, msg.Mixed.Item, 2)
+				var text msgaudit.ChatText
+				require.NoError(t, msg.Mixed.Item[0].Decode(&text))
+				assert.Equal(t, "你好", text.Content)
+				var image msgaudit.ChatImage
+				require.NoError(t, msg.Mixed.Item[1].Decode(&image))
+				assert.Equal(t, "id", image.SDKFileID)
+			},
+		},
+		{
+			name: "meeting",
+			data: `{"msgid":"8","action":"send","msgtype":"meeting","meeting":{"topic":"周会","starttime":1592956800,"endtime":1592960400,"address":"","remarks":"","meetingtype":102,"meetingid":1210342560,"status":1}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Meeting)
+				assert.Equal(t, int64(1210342560), msg.Meeting.MeetingID)
+			},
+		},
+		{
+			name: "docmsg",
+			data: `{"msgid":"9","action":"send","msgtype":"docmsg","doc":{"title":"测试&演示客户","doc_creator":"test","link_url":"https://doc.weixin.qq.com/txdoc/excel?docid=xxx"}}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				require.NotNil(t, msg.Doc)
+				assert.Equal(t, "test", msg.Doc.DocCreator)
+			},
+		},
+		{
+			name: "switch",
+			data: `{"msgid":"10","action":"switch","time":1554119421840,"user":"XuJinSheng"}`,
+			check: func(t *testing.T, msg *msgaudit.ChatMessage) {
+				assert.Equal(t, msgaudit.ChatActionSwitch, msg.Action)
+				assert.Equal(t, "XuJinSheng", msg.User)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseMessage([]byte(tt.data))
+			require.NoError(t, err)
+			tt.check(t, msg)
+		})
+	}
+}
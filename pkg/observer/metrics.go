@@ -0,0 +1,115 @@
+package observer
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/shuaidd/wecom-core/pkg/breaker"
+)
+
+// 指标名称
+const (
+	// MetricRequestsTotal 请求尝试次数，标签：path、agent、outcome、errcode、status
+	MetricRequestsTotal = "wecom_requests_total"
+	// MetricRequestDuration 请求耗时（秒），标签：path、agent
+	MetricRequestDuration = "wecom_request_duration_seconds"
+	// MetricRetriesTotal 重试次数，标签：path、agent
+	MetricRetriesTotal = "wecom_request_retries_total"
+	// MetricTokenFetchesTotal access_token 获取次数，标签：agent、outcome
+	MetricTokenFetchesTotal = "wecom_token_fetches_total"
+	// MetricTokenFetchDuration access_token 获取耗时（秒），标签：agent
+	MetricTokenFetchDuration = "wecom_token_fetch_duration_seconds"
+	// MetricTokenCacheTotal access_token 缓存读取次数，标签：agent、result（hit/miss）
+	MetricTokenCacheTotal = "wecom_token_cache_lookups_total"
+	// MetricCircuitStateChangesTotal 熔断器状态变化次数，标签：path、agent、state
+	MetricCircuitStateChangesTotal = "wecom_circuit_state_changes_total"
+)
+
+// outcome 标签的取值
+const (
+	// OutcomeSuccess 成功
+	OutcomeSuccess = "success"
+	// OutcomeAPIError 企业微信返回了错误码
+	OutcomeAPIError = "api_error"
+	// OutcomeError 网络错误、超时、熔断等未收到有效响应的错误
+	OutcomeError = "error"
+)
+
+// Labels 指标标签
+type Labels map[string]string
+
+// Recorder Prometheus 风格的指标记录接口
+// 同一指标的标签键固定，可以直接映射到 CounterVec/HistogramVec 的 label
+type Recorder interface {
+	// IncCounter 计数器加一
+	IncCounter(name string, labels Labels)
+	// ObserveHistogram 记录直方图观测值
+	ObserveHistogram(name string, value float64, labels Labels)
+}
+
+// metrics 将事件转换为指标的 Observer
+type metrics struct {
+	NoopObserver
+	r Recorder
+}
+
+// NewMetrics 创建记录指标的 Observer，指标名称与标签见 Metric* 常量
+func NewMetrics(r Recorder) Observer {
+	return &metrics{r: r}
+}
+
+// RequestStart 实现 Observer
+func (m *metrics) RequestStart(ctx context.Context, req *Request) context.Context {
+	if req.Attempt > 1 {
+		m.r.IncCounter(MetricRetriesTotal, Labels{"path": req.Path, "agent": req.AgentKey})
+	}
+	return ctx
+}
+
+// RequestFinish 实现 Observer
+func (m *metrics) RequestFinish(_ context.Context, req *Request, result *Result) {
+	m.r.IncCounter(MetricRequestsTotal, Labels{
+		"path":    req.Path,
+		"agent":   req.AgentKey,
+		"outcome": requestOutcome(result),
+		"errcode": strconv.Itoa(result.ErrCode),
+		"status":  strconv.Itoa(result.StatusCode),
+	})
+	m.r.ObserveHistogram(MetricRequestDuration, result.Duration.Seconds(), Labels{"path": req.Path, "agent": req.AgentKey})
+}
+
+// TokenFetched 实现 Observer
+func (m *metrics) TokenFetched(_ context.Context, fetch *TokenFetch) {
+	outcome := OutcomeSuccess
+	if fetch.Err != nil {
+		outcome = OutcomeError
+	}
+	m.r.IncCounter(MetricTokenFetchesTotal, Labels{"agent": fetch.AgentKey, "outcome": outcome})
+	m.r.ObserveHistogram(MetricTokenFetchDuration, fetch.Duration.Seconds(), Labels{"agent": fetch.AgentKey})
+}
+
+// TokenCacheLookup 实现 Observer
+func (m *metrics) TokenCacheLookup(_ context.Context, lookup *CacheLookup) {
+	result := "miss"
+	if lookup.Hit {
+		result = "hit"
+	}
+	m.r.IncCounter(MetricTokenCacheTotal, Labels{"agent": lookup.AgentKey, "result": result})
+}
+
+// CircuitStateChanged 实现 Observer
+func (m *metrics) CircuitStateChanged(key breaker.Key, _, to breaker.State) {
+	m.r.IncCounter(MetricCircuitStateChangesTotal, Labels{"path": key.Path, "agent": key.AgentKey, "state": to.String()})
+}
+
+// requestOutcome 请求结果分类
+func requestOutcome(result *Result) string {
+	switch {
+	case result.Err == nil:
+		return OutcomeSuccess
+	case result.ErrCode != 0:
+		return OutcomeAPIError
+	default:
+		return OutcomeError
+	}
+}
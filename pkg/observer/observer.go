@@ -0,0 +1,142 @@
+// Package observer 定义SDK的可观测性钩子
+//
+// HTTP 客户端在每次请求尝试（包括重试）的开始与结束、token 获取、token 缓存读取以及熔断器状态变化时调用 Observer，
+// 可以据此统计各接口的耗时、错误码与重试次数，或为每次请求创建追踪 span。
+// 包内提供了与具体监控库无关的指标（NewMetrics）与追踪（NewTracing）适配器，
+// 只需包装 Prometheus、OpenTelemetry 等库的对象即可接入。
+package observer
+
+import (
+	"context"
+	"time"
+
+	"github.com/shuaidd/wecom-core/pkg/breaker"
+)
+
+// Request 一次API请求尝试
+type Request struct {
+	// Method HTTP方法
+	Method string
+	// Path 接口路径，如 /cgi-bin/message/send
+	Path string
+	// AgentKey 应用名称或ID，默认应用为空
+	AgentKey string
+	// TraceID 通过 WithTraceID 设置的 TraceId
+	TraceID string
+	// Attempt 第几次尝试，从 1 开始，大于 1 表示重试
+	Attempt int
+	// Media 是否为素材下载等非 JSON 请求
+	Media bool
+}
+
+// Result 一次API请求尝试的结果
+type Result struct {
+	// StatusCode HTTP状态码，请求未收到响应时为 0
+	StatusCode int
+	// ErrCode 企业微信错误码，成功或非企业微信错误时为 0
+	ErrCode int
+	// Err 请求错误，成功时为 nil
+	Err error
+	// Duration 请求耗时
+	Duration time.Duration
+}
+
+// TokenFetch 一次 access_token 获取（调用 gettoken 或自定义 TokenFetcher）
+type TokenFetch struct {
+	// CorpID 企业ID（第三方应用凭证等场景为对应的缓存标识）
+	CorpID string
+	// AgentKey 应用名称或ID，默认应用为空
+	AgentKey string
+	// Err 获取错误，成功时为 nil
+	Err error
+	// Duration 耗时
+	Duration time.Duration
+}
+
+// CacheLookup 一次 access_token 缓存读取
+type CacheLookup struct {
+	// CorpID 企业ID
+	CorpID string
+	// AgentKey 应用名称或ID，默认应用为空
+	AgentKey string
+	// Hit 是否命中未过期的缓存
+	Hit bool
+}
+
+// Observer 可观测性钩子
+// 实现方可以嵌入 NoopObserver，只实现关心的方法；所有方法都会被同步调用，不应阻塞
+type Observer interface {
+	// RequestStart 请求尝试开始前调用，返回的 context 用于发送本次请求、执行拦截器、记录日志以及调用 RequestFinish
+	RequestStart(ctx context.Context, req *Request) context.Context
+	// RequestFinish 请求尝试结束后调用，ctx 为 RequestStart 返回的 context
+	RequestFinish(ctx context.Context, req *Request, result *Result)
+	// TokenFetched 获取 access_token 后调用（缓存未命中或 token 失效时）
+	TokenFetched(ctx context.Context, fetch *TokenFetch)
+	// TokenCacheLookup 读取 access_token 缓存后调用
+	TokenCacheLookup(ctx context.Context, lookup *CacheLookup)
+	// CircuitStateChanged 熔断器状态变化时调用
+	CircuitStateChanged(key breaker.Key, from, to breaker.State)
+}
+
+// NoopObserver 不做任何事的 Observer，也是默认实现
+type NoopObserver struct{}
+
+// RequestStart 实现 Observer
+func (NoopObserver) RequestStart(ctx context.Context, _ *Request) context.Context { return ctx }
+
+// RequestFinish 实现 Observer
+func (NoopObserver) RequestFinish(context.Context, *Request, *Result) {}
+
+// TokenFetched 实现 Observer
+func (NoopObserver) TokenFetched(context.Context, *TokenFetch) {}
+
+// TokenCacheLookup 实现 Observer
+func (NoopObserver) TokenCacheLookup(context.Context, *CacheLookup) {}
+
+// CircuitStateChanged 实现 Observer
+func (NoopObserver) CircuitStateChanged(breaker.Key, breaker.State, breaker.State) {}
+
+// multi 依次调用多个 Observer
+type multi []Observer
+
+// Multi 组合多个 Observer，例如同时接入指标与追踪
+// RequestStart 按顺序调用并传递 context，其余方法按相反顺序调用
+func Multi(observers ...Observer) Observer {
+	return multi(observers)
+}
+
+// RequestStart 实现 Observer
+func (m multi) RequestStart(ctx context.Context, req *Request) context.Context {
+	for _, o := range m {
+		ctx = o.RequestStart(ctx, req)
+	}
+	return ctx
+}
+
+// RequestFinish 实现 Observer
+func (m multi) RequestFinish(ctx context.Context, req *Request, result *Result) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].RequestFinish(ctx, req, result)
+	}
+}
+
+// TokenFetched 实现 Observer
+func (m multi) TokenFetched(ctx context.Context, fetch *TokenFetch) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].TokenFetched(ctx, fetch)
+	}
+}
+
+// TokenCacheLookup 实现 Observer
+func (m multi) TokenCacheLookup(ctx context.Context, lookup *CacheLookup) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].TokenCacheLookup(ctx, lookup)
+	}
+}
+
+// CircuitStateChanged 实现 Observer
+func (m multi) CircuitStateChanged(key breaker.Key, from, to breaker.State) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].CircuitStateChanged(key, from, to)
+	}
+}
@@ -0,0 +1,164 @@
+package observer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/pkg/breaker"
+)
+
+// fakeRecorder 记录指标
+type fakeRecorder struct {
+	counters   map[string]int
+	histograms map[string][]float64
+}
+
+func newFakeRecorder() *fakeRecorder {
+	return &fakeRecorder{counters: make(map[string]int), histograms: make(map[string][]float64)}
+}
+
+func (r *fakeRecorder) IncCounter(name string, labels Labels) {
+	r.counters[metricKey(name, labels)]++
+}
+
+func (r *fakeRecorder) ObserveHistogram(name string, value float64, labels Labels) {
+	key := metricKey(name, labels)
+	r.histograms[key] = append(r.histograms[key], value)
+}
+
+// metricKey 按固定顺序拼接标签
+func metricKey(name string, labels Labels) string {
+	key := name
+	for _, l := range []string{"path", "agent", "outcome", "errcode", "status", "result", "state"} {
+		if v, ok := labels[l]; ok {
+			key += fmt.Sprintf(",%s=%s", l, v)
+		}
+	}
+	return key
+}
+
+func TestMetrics(t *testing.T) {
+	r := newFakeRecorder()
+	o := NewMetrics(r)
+	ctx := context.Background()
+
+	req := &Request{Method: "GET", Path: "/cgi-bin/user/get", AgentKey: "hr", Attempt: 1}
+	o.RequestFinish(o.RequestStart(ctx, req), req, &Result{StatusCode: 200, ErrCode: 45009, Err: errors.New("freq"), Duration: time.Second})
+	req = &Request{Method: "GET", Path: "/cgi-bin/user/get", AgentKey: "hr", Attempt: 2}
+	o.RequestFinish(o.RequestStart(ctx, req), req, &Result{StatusCode: 200, Duration: time.Second})
+
+	o.TokenCacheLookup(ctx, &CacheLookup{AgentKey: "hr", Hit: false})
+	o.TokenFetched(ctx, &TokenFetch{AgentKey: "hr", Duration: time.Second})
+	o.CircuitStateChanged(breaker.Key{Path: "/cgi-bin/user/get"}, breaker.StateClosed, breaker.StateOpen)
+
+	assert.Equal(t, map[string]int{
+		"wecom_requests_total,path=/cgi-bin/user/get,agent=hr,outcome=api_error,errcode=45009,status=200": 1,
+		"wecom_requests_total,path=/cgi-bin/user/get,agent=hr,outcome=success,errcode=0,status=200":       1,
+		"wecom_request_retries_total,path=/cgi-bin/user/get,agent=hr":                                     1,
+		"wecom_token_cache_lookups_total,agent=hr,result=miss":                                            1,
+		"wecom_token_fetches_total,agent=hr,outcome=success":                                              1,
+		"wecom_circuit_state_changes_total,path=/cgi-bin/user/get,agent=,state=open":                      1,
+	}, r.counters)
+	assert.Equal(t, []float64{1, 1}, r.histograms["wecom_request_duration_seconds,path=/cgi-bin/user/get,agent=hr"])
+	assert.Equal(t, []float64{1}, r.histograms["wecom_token_fetch_duration_seconds,agent=hr"])
+}
+
+// fakeSpan 记录属性与错误的 span
+type fakeSpan struct {
+	name   string
+	parent *fakeSpan
+	attrs  map[string]any
+	err    error
+	ended  bool
+}
+
+func (s *fakeSpan) SetAttributes(attrs map[string]any) {
+	for k, v := range attrs {
+		s.attrs[k] = v
+	}
+}
+
+func (s *fakeSpan) RecordError(err error) { s.err = err }
+
+func (s *fakeSpan) End() { s.ended = true }
+
+type fakeSpanKey struct{}
+
+// fakeTracer 以 context 中的 span 为父 span
+type fakeTracer struct {
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(fakeSpanKey{}).(*fakeSpan)
+	span := &fakeSpan{name: name, parent: parent, attrs: make(map[string]any)}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, fakeSpanKey{}, span), span
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &fakeTracer{}
+	o := NewTracing(tracer)
+
+	root := &fakeSpan{name: "handler", attrs: make(map[string]any)}
+	ctx := context.WithValue(context.Background(), fakeSpanKey{}, root)
+
+	req := &Request{Method: "POST", Path: "/cgi-bin/message/send", AgentKey: "notify", TraceID: "trace-1", Attempt: 2}
+	reqCtx := o.RequestStart(ctx, req)
+	// 返回的 context 携带新的 span
+	assert.Same(t, tracer.spans[0], reqCtx.Value(fakeSpanKey{}))
+
+	errSend := errors.New("system busy")
+	o.RequestFinish(reqCtx, req, &Result{StatusCode: 200, ErrCode: 10001, Err: errSend})
+
+	require.Len(t, tracer.spans, 1)
+	span := tracer.spans[0]
+	assert.Equal(t, "wecom /cgi-bin/message/send", span.name)
+	assert.Same(t, root, span.parent)
+	assert.Equal(t, map[string]any{
+		AttrHTTPMethod:     "POST",
+		AttrPath:           "/cgi-bin/message/send",
+		AttrAgentKey:       "notify",
+		AttrAttempt:        2,
+		AttrTraceID:        "trace-1",
+		AttrHTTPStatusCode: 200,
+		AttrErrCode:        10001,
+	}, span.attrs)
+	assert.Equal(t, errSend, span.err)
+	assert.True(t, span.ended)
+}
+
+// orderObserver 记录调用顺序
+type orderObserver struct {
+	NoopObserver
+	name  string
+	calls *[]string
+}
+
+type orderKey string
+
+func (o *orderObserver) RequestStart(ctx context.Context, _ *Request) context.Context {
+	*o.calls = append(*o.calls, "start:"+o.name)
+	return context.WithValue(ctx, orderKey(o.name), true)
+}
+
+func (o *orderObserver) RequestFinish(ctx context.Context, _ *Request, _ *Result) {
+	*o.calls = append(*o.calls, fmt.Sprintf("finish:%s:%v", o.name, ctx.Value(orderKey("a")) != nil && ctx.Value(orderKey("b")) != nil))
+}
+
+func TestMulti(t *testing.T) {
+	var calls []string
+	o := Multi(&orderObserver{name: "a", calls: &calls}, &orderObserver{name: "b", calls: &calls})
+
+	req := &Request{Path: "/cgi-bin/user/get", Attempt: 1}
+	o.RequestFinish(o.RequestStart(context.Background(), req), req, &Result{})
+	o.TokenFetched(context.Background(), &TokenFetch{})
+
+	assert.Equal(t, []string{"start:a", "start:b", "finish:b:true", "finish:a:true"}, calls)
+}
@@ -0,0 +1,87 @@
+package observer
+
+import (
+	"context"
+)
+
+// 追踪 span 的属性名称
+const (
+	// AttrHTTPMethod HTTP方法
+	AttrHTTPMethod = "http.method"
+	// AttrHTTPStatusCode HTTP状态码
+	AttrHTTPStatusCode = "http.status_code"
+	// AttrPath 接口路径
+	AttrPath = "wecom.path"
+	// AttrAgentKey 应用名称或ID
+	AttrAgentKey = "wecom.agent_key"
+	// AttrAttempt 第几次尝试
+	AttrAttempt = "wecom.attempt"
+	// AttrTraceID 通过 WithTraceID 设置的 TraceId
+	AttrTraceID = "wecom.trace_id"
+	// AttrErrCode 企业微信错误码
+	AttrErrCode = "wecom.errcode"
+)
+
+// Span 追踪 span
+type Span interface {
+	// SetAttributes 设置属性
+	SetAttributes(attrs map[string]any)
+	// RecordError 记录错误并将 span 标记为失败
+	RecordError(err error)
+	// End 结束 span
+	End()
+}
+
+// Tracer 创建 span
+// 返回的 context 需携带新的 span，以便后续的 HTTP 请求与拦截器能够传播追踪上下文
+type Tracer interface {
+	// Start 以 ctx 中的 span 为父 span 创建新的 span
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// spanKey 在 context 中保存当前请求 span 的 key
+type spanKey struct{}
+
+// tracing 为每次请求尝试创建 span 的 Observer
+type tracing struct {
+	NoopObserver
+	t Tracer
+}
+
+// NewTracing 创建为每次请求尝试（包括重试）创建 span 的 Observer
+// span 名称为 "wecom " + 接口路径，WithTraceID 设置的 TraceId 记录在 wecom.trace_id 属性中
+func NewTracing(t Tracer) Observer {
+	return &tracing{t: t}
+}
+
+// RequestStart 实现 Observer
+func (o *tracing) RequestStart(ctx context.Context, req *Request) context.Context {
+	ctx, span := o.t.Start(ctx, "wecom "+req.Path)
+	attrs := map[string]any{
+		AttrHTTPMethod: req.Method,
+		AttrPath:       req.Path,
+		AttrAgentKey:   req.AgentKey,
+		AttrAttempt:    req.Attempt,
+	}
+	if req.TraceID != "" {
+		attrs[AttrTraceID] = req.TraceID
+	}
+	span.SetAttributes(attrs)
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// RequestFinish 实现 Observer
+func (o *tracing) RequestFinish(ctx context.Context, _ *Request, result *Result) {
+	span, ok := ctx.Value(spanKey{}).(Span)
+	if !ok {
+		return
+	}
+	span.SetAttributes(map[string]any{
+		AttrHTTPStatusCode: result.StatusCode,
+		AttrErrCode:        result.ErrCode,
+	})
+	if result.Err != nil {
+		span.RecordError(result.Err)
+	}
+	span.End()
+}
@@ -0,0 +1,316 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited 超出客户端限流配额（仅 ModeFailFast 下返回）
+var ErrRateLimited = errors.New("ratelimit: rate limit exceeded")
+
+// Mode 超出配额时的处理方式
+type Mode int
+
+const (
+	// ModeBlock 等待直到获得配额或 context 结束（默认）
+	ModeBlock Mode = iota
+	// ModeFailFast 立即返回 ErrRateLimited
+	ModeFailFast
+)
+
+const (
+	// minFactor 收紧后的最低速率比例
+	minFactor = 1.0 / 16
+	// sweepInterval 清理闲置令牌桶的间隔
+	sweepInterval = time.Minute
+)
+
+// Key 一次请求的限流维度
+type Key struct {
+	// CorpID 企业ID
+	CorpID string
+	// AgentKey 应用名称或ID
+	AgentKey string
+	// Path 接口路径，如 /cgi-bin/message/send
+	Path string
+}
+
+// Rule 限流规则（令牌桶）
+// 每个 Window 内最多 Limit 次请求，令牌按 Limit/Window 的速率匀速补充，桶容量为 Limit
+type Rule struct {
+	// Name 规则名称，用于错误信息
+	Name string
+	// PerCorp 按企业分别计数
+	PerCorp bool
+	// PerAgent 按应用分别计数
+	PerAgent bool
+	// PerPath 按接口分别计数
+	PerPath bool
+	// Paths 仅对这些接口生效（支持以 * 结尾的前缀匹配），为空时对所有接口生效
+	Paths []string
+	// Limit 窗口内允许的请求数
+	Limit int
+	// Window 窗口时长
+	Window time.Duration
+}
+
+// matches 判断规则是否适用于接口
+func (r *Rule) matches(path string) bool {
+	if len(r.Paths) == 0 {
+		return true
+	}
+	for _, p := range r.Paths {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// bucketKey 生成令牌桶的key
+func (r *Rule) bucketKey(index int, key Key) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d", index)
+	if r.PerCorp {
+		b.WriteString("|" + key.CorpID)
+	}
+	if r.PerAgent {
+		b.WriteString("|" + key.AgentKey)
+	}
+	if r.PerPath {
+		b.WriteString("|" + key.Path)
+	}
+	return b.String()
+}
+
+// DefaultRules 企业微信文档中的基础频率限制
+// 每企业调用单个接口不可超过 1万次/分、15万次/小时；每IP调用单个接口不可超过 2万次/分、60万次/小时
+// 文档: https://developer.work.weixin.qq.com/document/path/90312
+func DefaultRules() []Rule {
+	return []Rule{
+		{Name: "corp-api-minute", PerCorp: true, PerPath: true, Limit: 10000, Window: time.Minute},
+		{Name: "corp-api-hour", PerCorp: true, PerPath: true, Limit: 150000, Window: time.Hour},
+		{Name: "ip-api-minute", PerPath: true, Limit: 20000, Window: time.Minute},
+		{Name: "ip-api-hour", PerPath: true, Limit: 600000, Window: time.Hour},
+	}
+}
+
+// Option 限流器选项
+type Option func(*Limiter)
+
+// WithRules 追加限流规则
+func WithRules(rules ...Rule) Option {
+	return func(l *Limiter) {
+		l.rules = append(l.rules, rules...)
+	}
+}
+
+// WithoutDefaultRules 不使用默认规则，仅使用 WithRules 指定的规则
+func WithoutDefaultRules() Option {
+	return func(l *Limiter) {
+		l.noDefaults = true
+	}
+}
+
+// WithMode 设置超出配额时的处理方式
+func WithMode(mode Mode) Option {
+	return func(l *Limiter) {
+		l.mode = mode
+	}
+}
+
+// bucket 令牌桶
+type bucket struct {
+	// rule 所属规则
+	rule *Rule
+	// tokens 当前令牌数
+	tokens float64
+	// last 上次补充令牌的时间
+	last time.Time
+	// factor 速率比例，收到 45009 时减半，每个窗口恢复一倍
+	factor float64
+	// recoverAt 下一次恢复速率的时间
+	recoverAt time.Time
+}
+
+// rate 每秒补充的令牌数
+func (b *bucket) rate() float64 {
+	return float64(b.rule.Limit) / b.rule.Window.Seconds() * b.factor
+}
+
+// refill 补充令牌
+func (b *bucket) refill(now time.Time) {
+	if b.factor < 1 && !now.Before(b.recoverAt) {
+		b.factor = min(1, b.factor*2)
+		b.recoverAt = now.Add(b.rule.Window)
+	}
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = min(float64(b.rule.Limit), b.tokens+elapsed*b.rate())
+	}
+	b.last = now
+}
+
+// idle 令牌已补满且速率已恢复，与新建的令牌桶等价
+func (b *bucket) idle() bool {
+	return b.factor >= 1 && b.tokens >= float64(b.rule.Limit)
+}
+
+// wait 获得一个令牌需要等待的时间
+func (b *bucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate() * float64(time.Second))
+}
+
+// Limiter 客户端限流器
+// 按企业、应用、接口维度维护令牌桶，可在多个 goroutine、多个客户端之间共享同一份配额
+type Limiter struct {
+	mu      sync.Mutex
+	rules   []Rule
+	mode    Mode
+	buckets map[string]*bucket
+	// swept 上次清理闲置令牌桶的时间
+	swept time.Time
+	// noDefaults 不使用默认规则
+	noDefaults bool
+	// now 当前时间（便于测试）
+	now func() time.Time
+}
+
+// New 创建限流器，默认包含 DefaultRules
+func New(opts ...Option) *Limiter {
+	l := &Limiter{
+		mode:    ModeBlock,
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if !l.noDefaults {
+		l.rules = append(DefaultRules(), l.rules...)
+	}
+	return l
+}
+
+// Wait 获取一次请求的配额
+// ModeBlock 下等待直到所有适用的规则都有剩余配额；ModeFailFast 下配额不足时立即返回 ErrRateLimited
+func (l *Limiter) Wait(ctx context.Context, key Key) error {
+	for {
+		wait, rule := l.reserve(key)
+		if wait == 0 {
+			return nil
+		}
+		if l.mode == ModeFailFast {
+			return fmt.Errorf("%w: rule %s, retry after %s", ErrRateLimited, rule, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Allow 不等待地尝试获取一次请求的配额
+func (l *Limiter) Allow(key Key) bool {
+	wait, _ := l.reserve(key)
+	return wait == 0
+}
+
+// Penalize 收到频率限制错误（45009）时收紧配额
+// 清空相关令牌桶并将补充速率减半，之后每个窗口恢复一倍
+func (l *Limiter) Penalize(key Key) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	for _, b := range l.matching(key, now) {
+		b.refill(now)
+		b.tokens = 0
+		b.factor = max(minFactor, b.factor/2)
+		b.recoverAt = now.Add(b.rule.Window)
+	}
+}
+
+// reserve 所有适用的规则都有配额时扣减并返回 0，否则返回需要等待的最长时间及对应规则
+func (l *Limiter) reserve(key Key) (time.Duration, string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+	buckets := l.matching(key, now)
+
+	var (
+		longest time.Duration
+		rule    string
+	)
+	for _, b := range buckets {
+		b.refill(now)
+		if w := b.wait(); w > longest {
+			longest, rule = w, b.rule.Name
+		}
+	}
+	if longest > 0 {
+		return longest, rule
+	}
+
+	for _, b := range buckets {
+		b.tokens--
+	}
+	return 0, ""
+}
+
+// sweep 定期删除闲置的令牌桶，避免按企业、应用、接口计数时令牌桶持续增长，调用方需持有锁
+func (l *Limiter) sweep(now time.Time) {
+	if l.swept.IsZero() {
+		l.swept = now
+	}
+	if now.Sub(l.swept) < sweepInterval {
+		return
+	}
+	l.swept = now
+	for k, b := range l.buckets {
+		b.refill(now)
+		if b.idle() {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+// matching 返回适用于请求的令牌桶，不存在时创建
+func (l *Limiter) matching(key Key, now time.Time) []*bucket {
+	var buckets []*bucket
+	for i := range l.rules {
+		rule := &l.rules[i]
+		if rule.Limit <= 0 || rule.Window <= 0 || !rule.matches(key.Path) {
+			continue
+		}
+		bk := rule.bucketKey(i, key)
+		b, ok := l.buckets[bk]
+		if !ok {
+			b = &bucket{
+				rule:   rule,
+				tokens: float64(rule.Limit),
+				last:   now,
+				factor: 1,
+			}
+			l.buckets[bk] = b
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
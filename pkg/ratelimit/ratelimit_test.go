@@ -0,0 +1,215 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClock 可手动推进的时钟
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func newTestLimiter(clock *fakeClock, opts ...Option) *Limiter {
+	l := New(append([]Option{WithoutDefaultRules()}, opts...)...)
+	l.now = clock.Now
+	return l
+}
+
+func TestDefaultRules(t *testing.T) {
+	l := New()
+	assert.Len(t, l.rules, len(DefaultRules()))
+
+	l = New(WithRules(Rule{Name: "custom", Limit: 1, Window: time.Second}))
+	assert.Len(t, l.rules, len(DefaultRules())+1)
+
+	l = New(WithRules(Rule{Name: "custom", Limit: 1, Window: time.Second}), WithoutDefaultRules())
+	assert.Len(t, l.rules, 1)
+}
+
+func TestLimiter_PerPathBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock, WithRules(Rule{Name: "api", PerCorp: true, PerPath: true, Limit: 2, Window: time.Minute}))
+
+	a := Key{CorpID: "corp", Path: "/cgi-bin/a"}
+	b := Key{CorpID: "corp", Path: "/cgi-bin/b"}
+
+	assert.True(t, l.Allow(a))
+	assert.True(t, l.Allow(a))
+	assert.False(t, l.Allow(a))
+
+	// 不同接口、不同企业分别计数
+	assert.True(t, l.Allow(b))
+	assert.True(t, l.Allow(Key{CorpID: "other", Path: "/cgi-bin/a"}))
+
+	// 按速率补充：2次/分钟，30秒补充一个
+	clock.Advance(30 * time.Second)
+	assert.True(t, l.Allow(a))
+	assert.False(t, l.Allow(a))
+}
+
+func TestLimiter_PathMatching(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock, WithRules(
+		Rule{Name: "send", PerAgent: true, Paths: []string{"/cgi-bin/message/*"}, Limit: 1, Window: time.Minute},
+	))
+
+	send := Key{AgentKey: "app", Path: "/cgi-bin/message/send"}
+	assert.True(t, l.Allow(send))
+	assert.False(t, l.Allow(Key{AgentKey: "app", Path: "/cgi-bin/message/recall"}))
+	assert.True(t, l.Allow(Key{AgentKey: "other", Path: "/cgi-bin/message/send"}))
+
+	// 不匹配的接口不受限制
+	for i := 0; i < 10; i++ {
+		assert.True(t, l.Allow(Key{AgentKey: "app", Path: "/cgi-bin/user/get"}))
+	}
+}
+
+func TestLimiter_AllRulesMustAllow(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock, WithRules(
+		Rule{Name: "minute", Limit: 10, Window: time.Minute},
+		Rule{Name: "second", Limit: 1, Window: time.Second},
+	))
+
+	key := Key{Path: "/api"}
+	assert.True(t, l.Allow(key))
+	assert.False(t, l.Allow(key))
+
+	clock.Advance(time.Second)
+	assert.True(t, l.Allow(key))
+}
+
+func TestLimiter_FailFast(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock,
+		WithMode(ModeFailFast),
+		WithRules(Rule{Name: "api", Limit: 1, Window: time.Minute}),
+	)
+
+	ctx := context.Background()
+	require.NoError(t, l.Wait(ctx, Key{Path: "/api"}))
+	err := l.Wait(ctx, Key{Path: "/api"})
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestLimiter_Block(t *testing.T) {
+	l := New(WithoutDefaultRules(), WithRules(Rule{Name: "api", Limit: 1, Window: 50 * time.Millisecond}))
+	ctx := context.Background()
+
+	start := time.Now()
+	require.NoError(t, l.Wait(ctx, Key{Path: "/api"}))
+	require.NoError(t, l.Wait(ctx, Key{Path: "/api"}))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	err := l.Wait(ctx, Key{Path: "/api"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLimiter_Penalize(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock, WithRules(Rule{Name: "api", PerPath: true, Limit: 60, Window: time.Minute}))
+	key := Key{Path: "/api"}
+
+	assert.True(t, l.Allow(key))
+
+	// 收到 45009：清空令牌并将速率减半（1次/秒 -> 1次/2秒）
+	l.Penalize(key)
+	assert.False(t, l.Allow(key))
+
+	clock.Advance(time.Second)
+	assert.False(t, l.Allow(key))
+	clock.Advance(time.Second)
+	assert.True(t, l.Allow(key))
+
+	// 再次收紧
+	l.Penalize(key)
+	clock.Advance(2 * time.Second)
+	assert.False(t, l.Allow(key))
+	clock.Advance(2 * time.Second)
+	assert.True(t, l.Allow(key))
+
+	// 一个窗口后恢复一倍
+	clock.Advance(time.Minute)
+	l.Allow(key)
+	b := l.buckets[l.rules[0].bucketKey(0, key)]
+	assert.Equal(t, 0.5, b.factor)
+}
+
+func TestLimiter_EvictsIdleBuckets(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock, WithRules(Rule{Name: "api", PerCorp: true, PerPath: true, Limit: 2, Window: time.Minute}))
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, l.Allow(Key{CorpID: fmt.Sprintf("corp%d", i), Path: "/api"}))
+	}
+	active := Key{CorpID: "active", Path: "/api"}
+	assert.True(t, l.Allow(active))
+	assert.True(t, l.Allow(active))
+	penalized := Key{CorpID: "penalized", Path: "/api"}
+	l.Penalize(penalized)
+	l.Penalize(penalized)
+	assert.Len(t, l.buckets, 102)
+
+	// 令牌补满的闲置令牌桶被删除，速率尚未恢复的保留
+	clock.Advance(time.Minute)
+	assert.True(t, l.Allow(active))
+	assert.Len(t, l.buckets, 2)
+
+	// 删除后重新创建的令牌桶配额不变
+	assert.True(t, l.Allow(Key{CorpID: "corp0", Path: "/api"}))
+	assert.True(t, l.Allow(Key{CorpID: "corp0", Path: "/api"}))
+	assert.False(t, l.Allow(Key{CorpID: "corp0", Path: "/api"}))
+
+	// 速率恢复后删除
+	clock.Advance(time.Minute)
+	l.Allow(active)
+	assert.NotContains(t, l.buckets, l.rules[0].bucketKey(0, penalized))
+}
+
+func TestLimiter_Concurrent(t *testing.T) {
+	clock := &fakeClock{now: time.Now()}
+	l := newTestLimiter(clock, WithRules(Rule{Name: "api", Limit: 100, Window: time.Hour}))
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		allowed int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if l.Allow(Key{Path: "/api"}) {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 100, allowed)
+}
@@ -0,0 +1,250 @@
+// Package redact 对日志、调试输出与错误中的敏感信息脱敏
+//
+// 查询参数中的凭证（access_token、corpsecret、群机器人 key 等）总是被替换为 ***；
+// 请求与响应体中的凭证字段和个人信息字段（手机号、邮箱、证件号等）通过 Redactor 脱敏，字段可配置。
+package redact
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask 脱敏后的占位符
+const Mask = "***"
+
+// SensitiveParams 总是脱敏的查询参数
+var SensitiveParams = []string{
+	"access_token",
+	"corpsecret",
+	"suite_access_token",
+	"provider_access_token",
+	"suite_secret",
+	"provider_secret",
+	"key",
+}
+
+// SecretFields 请求与响应体中总是脱敏的凭证字段
+var SecretFields = []string{
+	"access_token",
+	"corpsecret",
+	"secret",
+	"suite_secret",
+	"provider_secret",
+	"suite_access_token",
+	"provider_access_token",
+	"suite_ticket",
+	"permanent_code",
+	"ticket",
+	"encoding_aes_key",
+}
+
+// PIIFields 默认脱敏的个人信息字段
+var PIIFields = []string{
+	"mobile",
+	"telephone",
+	"phone_number",
+	"remark_mobiles",
+	"mentioned_mobile_list",
+	"email",
+	"biz_mail",
+	"new_email",
+	"address",
+	"id_card",
+	"idcard",
+	"id_number",
+}
+
+// UserIDFields WithUserID 时脱敏的成员与客户标识字段
+var UserIDFields = []string{
+	"userid",
+	"userid_list",
+	"user_list",
+	"touser",
+	"external_userid",
+	"open_userid",
+}
+
+// paramPattern 匹配敏感查询参数
+var paramPattern = regexp.MustCompile(`\b(` + strings.Join(SensitiveParams, "|") + `)=([^&\s"']+)`)
+
+// String 替换文本中敏感查询参数的值
+func String(s string) string {
+	return paramPattern.ReplaceAllString(s, "${1}="+Mask)
+}
+
+// URL 返回脱敏后的 URL 字符串
+func URL(u *url.URL) string {
+	if u == nil {
+		return ""
+	}
+	return String(u.String())
+}
+
+// Error 返回错误信息经过脱敏的错误
+// *url.Error（HTTP 请求失败）会被复制并替换其中的 URL，保留 Timeout 等方法；
+// 其他错误的 Error() 经过脱敏，errors.Is/As 仍可通过 Unwrap 访问原始错误
+func Error(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	clean := String(msg)
+	if clean == msg {
+		return err
+	}
+
+	var ue *url.Error
+	if errors.As(err, &ue) && ue == err {
+		return &url.Error{Op: ue.Op, URL: String(ue.URL), Err: Error(ue.Err)}
+	}
+	return &redactedError{msg: clean, err: err}
+}
+
+// redactedError 错误信息经过脱敏的错误
+type redactedError struct {
+	msg string
+	err error
+}
+
+// Error 实现 error 接口
+func (e *redactedError) Error() string { return e.msg }
+
+// Unwrap 支持 errors.Is/As
+func (e *redactedError) Unwrap() error { return e.err }
+
+// Option Redactor 选项
+type Option func(*Redactor)
+
+// WithFields 追加需要脱敏的字段
+func WithFields(fields ...string) Option {
+	return func(r *Redactor) {
+		for _, f := range fields {
+			r.fields[f] = true
+		}
+	}
+}
+
+// WithoutFields 不脱敏这些字段（凭证字段除外）
+func WithoutFields(fields ...string) Option {
+	return func(r *Redactor) {
+		for _, f := range fields {
+			delete(r.fields, f)
+		}
+	}
+}
+
+// WithUserID 同时脱敏 userid、external_userid 等成员与客户标识
+func WithUserID() Option {
+	return WithFields(UserIDFields...)
+}
+
+// Redactor 请求与响应体脱敏器
+type Redactor struct {
+	// fields 需要脱敏的字段（不含凭证字段）
+	fields map[string]bool
+	// secrets 凭证字段
+	secrets map[string]bool
+}
+
+// New 创建脱敏器，默认脱敏凭证字段与 PIIFields
+func New(opts ...Option) *Redactor {
+	r := &Redactor{
+		fields:  make(map[string]bool),
+		secrets: make(map[string]bool),
+	}
+	for _, f := range SecretFields {
+		r.secrets[f] = true
+	}
+	for _, f := range PIIFields {
+		r.fields[f] = true
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// JSON 对 JSON 文本中的敏感字段脱敏（任意层级），不是 JSON 时仅替换敏感查询参数
+func (r *Redactor) JSON(data []byte) []byte {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return []byte(String(string(data)))
+	}
+	out, err := json.Marshal(r.redactValue(v))
+	if err != nil {
+		return []byte(String(string(data)))
+	}
+	return out
+}
+
+// Value 将 v 编码为 JSON 后脱敏，用于记录请求体
+func (r *Redactor) Value(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return r.JSON(data), nil
+}
+
+// redactValue 递归脱敏
+func (r *Redactor) redactValue(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, child := range val {
+			switch {
+			case r.secrets[k]:
+				val[k] = Mask
+			case r.fields[k]:
+				val[k] = maskValue(child)
+			default:
+				val[k] = r.redactValue(child)
+			}
+		}
+		return val
+	case []any:
+		for i, child := range val {
+			val[i] = r.redactValue(child)
+		}
+		return val
+	case string:
+		return String(val)
+	default:
+		return v
+	}
+}
+
+// maskValue 脱敏字段值，字符串保留少量首尾字符便于排查
+func maskValue(v any) any {
+	switch val := v.(type) {
+	case string:
+		return MaskString(val)
+	case []any:
+		for i, child := range val {
+			val[i] = maskValue(child)
+		}
+		return val
+	case nil:
+		return nil
+	default:
+		return Mask
+	}
+}
+
+// MaskString 脱敏字符串
+// 邮箱保留首字符与域名（z***@example.com），长度不小于 7 的字符串保留前 3 位与后 4 位（138***5678），其余替换为 ***
+func MaskString(s string) string {
+	if s == "" {
+		return s
+	}
+	r := []rune(s)
+	if at := strings.LastIndex(s, "@"); at > 0 {
+		return string(r[:1]) + Mask + s[at:]
+	}
+	if len(r) >= 7 {
+		return string(r[:3]) + Mask + string(r[len(r)-4:])
+	}
+	return Mask
+}
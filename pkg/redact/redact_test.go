@@ -0,0 +1,176 @@
+package redact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{
+			in:   "https://qyapi.weixin.qq.com/cgi-bin/user/get?access_token=abc123&userid=zhangsan",
+			want: "https://qyapi.weixin.qq.com/cgi-bin/user/get?access_token=***&userid=zhangsan",
+		},
+		{
+			in:   "/cgi-bin/gettoken?corpid=ww1&corpsecret=s3cr3t",
+			want: "/cgi-bin/gettoken?corpid=ww1&corpsecret=***",
+		},
+		{
+			in:   `Get "/cgi-bin/webhook/send?key=693a91f6": EOF`,
+			want: `Get "/cgi-bin/webhook/send?key=***": EOF`,
+		},
+		{
+			in:   "/cgi-bin/service/get_permanent_code?suite_access_token=t1",
+			want: "/cgi-bin/service/get_permanent_code?suite_access_token=***",
+		},
+		{
+			in:   "/cgi-bin/user/get?userid=zhangsan",
+			want: "/cgi-bin/user/get?userid=zhangsan",
+		},
+		{
+			// 只匹配完整的参数名
+			in:   "/path?monkey=1",
+			want: "/path?monkey=1",
+		},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, String(tt.in))
+	}
+}
+
+func TestURL(t *testing.T) {
+	u, err := url.Parse("https://qyapi.weixin.qq.com/cgi-bin/message/send?access_token=abc123&debug=1")
+	require.NoError(t, err)
+
+	assert.Equal(t, "https://qyapi.weixin.qq.com/cgi-bin/message/send?access_token=***&debug=1", URL(u))
+	assert.Equal(t, "", URL(nil))
+	// 原 URL 不被修改
+	assert.Equal(t, "abc123", u.Query().Get("access_token"))
+}
+
+// timeoutError 测试用的超时错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestError_URLError(t *testing.T) {
+	orig := &url.Error{
+		Op:  "Get",
+		URL: "https://qyapi.weixin.qq.com/cgi-bin/gettoken?corpid=ww1&corpsecret=s3cr3t",
+		Err: timeoutError{},
+	}
+
+	err := Error(orig)
+	assert.NotContains(t, err.Error(), "s3cr3t")
+	assert.Contains(t, err.Error(), "corpsecret=***")
+
+	var ue *url.Error
+	require.True(t, errors.As(err, &ue))
+	assert.True(t, ue.Timeout())
+	assert.ErrorIs(t, err, timeoutError{})
+}
+
+func TestError_Wrapped(t *testing.T) {
+	orig := fmt.Errorf("fetch: %w", &url.Error{
+		Op:  "Post",
+		URL: "/cgi-bin/message/send?access_token=abc123",
+		Err: context.DeadlineExceeded,
+	})
+
+	err := Error(orig)
+	assert.Equal(t, `fetch: Post "/cgi-bin/message/send?access_token=***": context deadline exceeded`, err.Error())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestError_Unchanged(t *testing.T) {
+	assert.Nil(t, Error(nil))
+
+	orig := errors.New("user not found")
+	assert.Same(t, orig, Error(orig))
+}
+
+func TestRedactor_JSON(t *testing.T) {
+	r := New()
+	body := []byte(`{
+		"userid": "zhangsan",
+		"mobile": "13800001234",
+		"email": "zhangsan@example.com",
+		"secret": "s3cr3t",
+		"extattr": {"attrs": [{"name": "id_card", "id_card": "110101199001011234"}]},
+		"mentioned_mobile_list": ["13800001234", "@all"],
+		"errcode": 0
+	}`)
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(r.JSON(body), &got))
+
+	assert.Equal(t, "zhangsan", got["userid"])
+	assert.Equal(t, "138***1234", got["mobile"])
+	assert.Equal(t, "z***@example.com", got["email"])
+	assert.Equal(t, Mask, got["secret"])
+	assert.Equal(t, []any{"138***1234", Mask}, got["mentioned_mobile_list"])
+	assert.Equal(t, float64(0), got["errcode"])
+
+	attrs := got["extattr"].(map[string]any)["attrs"].([]any)
+	attr := attrs[0].(map[string]any)
+	assert.Equal(t, "id_card", attr["name"])
+	assert.Equal(t, "110***1234", attr["id_card"])
+}
+
+func TestRedactor_JSONNotJSON(t *testing.T) {
+	r := New()
+	assert.Equal(t, "errmsg: access_token=***", string(r.JSON([]byte("errmsg: access_token=abc123"))))
+}
+
+func TestRedactor_Options(t *testing.T) {
+	r := New(WithUserID(), WithoutFields("email", "secret"), WithFields("name"))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(r.JSON([]byte(`{
+		"userid": "zhangsan",
+		"touser": "zhangsan|lisi",
+		"email": "zhangsan@example.com",
+		"secret": "s3cr3t",
+		"name": "张三"
+	}`)), &got))
+
+	assert.Equal(t, "zha***gsan", got["userid"])
+	assert.Equal(t, "zha***lisi", got["touser"])
+	assert.Equal(t, "zhangsan@example.com", got["email"])
+	// 凭证字段不能取消脱敏
+	assert.Equal(t, Mask, got["secret"])
+	assert.Equal(t, Mask, got["name"])
+}
+
+func TestRedactor_Value(t *testing.T) {
+	r := New()
+	data, err := r.Value(struct {
+		UserID string `json:"userid"`
+		Mobile string `json:"mobile"`
+	}{UserID: "zhangsan", Mobile: "13800001234"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"userid":"zhangsan","mobile":"138***1234"}`, string(data))
+
+	_, err = r.Value(make(chan int))
+	assert.Error(t, err)
+}
+
+func TestMaskString(t *testing.T) {
+	assert.Equal(t, "", MaskString(""))
+	assert.Equal(t, Mask, MaskString("123456"))
+	assert.Equal(t, "138***1234", MaskString("13800001234"))
+	assert.Equal(t, "张***@example.com", MaskString("张三@example.com"))
+	assert.Equal(t, "北京市***102号", MaskString("北京市海淀区某路102号"))
+}
@@ -0,0 +1,190 @@
+// Package retry 定义SDK的重试策略
+//
+// 每次请求尝试失败后，HTTP 客户端调用 Policy 决定是否重试以及重试前的等待时间。
+// 默认策略 Exponential 按错误分类判断是否可以重试，并使用带 full jitter 的指数退避：
+// token 失效、频率限制（45009）、系统繁忙（10001、-1）与请求发出前的网络错误（连接失败、DNS 解析失败）总是可以重试；
+// 请求已发出但未收到响应（超时、连接中断）时企业微信可能已经处理了请求，
+// 只有幂等请求（GET，或通过 wecom.WithIdempotent 声明的请求）才会重试，避免 message/send 等接口重复发送。
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"time"
+
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+)
+
+// Attempt 一次失败的请求尝试
+type Attempt struct {
+	// Method HTTP方法
+	Method string
+	// Path 接口路径，如 /cgi-bin/message/send
+	Path string
+	// AgentKey 应用名称或ID，默认应用为空
+	AgentKey string
+	// Idempotent 请求是否可以安全地重复发送
+	Idempotent bool
+	// Attempt 已完成的尝试次数，从 1 开始
+	Attempt int
+	// Err 本次尝试的错误
+	Err error
+}
+
+// Policy 重试策略
+type Policy interface {
+	// Retry 请求尝试失败后调用，返回是否重试以及重试前的等待时间
+	Retry(ctx context.Context, attempt *Attempt) (wait time.Duration, ok bool)
+}
+
+// Class 错误分类
+type Class int
+
+const (
+	// ClassPermanent 不可重试的错误，如参数错误、成员不存在、context 取消
+	ClassPermanent Class = iota
+	// ClassTokenExpired access_token 失效（40014、42001），刷新后重试
+	ClassTokenExpired
+	// ClassRateLimited 频率限制（45009）
+	ClassRateLimited
+	// ClassSystemBusy 系统繁忙（10001、-1）
+	ClassSystemBusy
+	// ClassNetworkBeforeSend 请求发出前的网络错误，企业微信未收到请求
+	ClassNetworkBeforeSend
+	// ClassNetworkAfterSend 请求已发出但未收到完整响应，企业微信可能已经处理了请求
+	ClassNetworkAfterSend
+)
+
+// String 返回分类名称
+func (c Class) String() string {
+	switch c {
+	case ClassPermanent:
+		return "permanent"
+	case ClassTokenExpired:
+		return "token_expired"
+	case ClassRateLimited:
+		return "rate_limited"
+	case ClassSystemBusy:
+		return "system_busy"
+	case ClassNetworkBeforeSend:
+		return "network_before_send"
+	case ClassNetworkAfterSend:
+		return "network_after_send"
+	default:
+		return fmt.Sprintf("class(%d)", int(c))
+	}
+}
+
+// Retriable 判断该分类的错误是否可以重试
+// 请求已发出的网络错误只有幂等请求可以重试
+func (c Class) Retriable(idempotent bool) bool {
+	switch c {
+	case ClassTokenExpired, ClassRateLimited, ClassSystemBusy, ClassNetworkBeforeSend:
+		return true
+	case ClassNetworkAfterSend:
+		return idempotent
+	default:
+		return false
+	}
+}
+
+// Classify 对请求错误分类
+func Classify(err error) Class {
+	switch {
+	case err == nil:
+		return ClassPermanent
+	case wecomerrors.IsTokenExpired(err):
+		return ClassTokenExpired
+	case wecomerrors.IsRateLimited(err):
+		return ClassRateLimited
+	case wecomerrors.IsSystemBusy(err):
+		return ClassSystemBusy
+	case wecomerrors.IsCircuitOpen(err), errors.Is(err, context.Canceled):
+		return ClassPermanent
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return ClassNetworkBeforeSend
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return ClassNetworkBeforeSend
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ClassNetworkAfterSend
+	}
+	return ClassPermanent
+}
+
+const (
+	// DefaultMaxRetries 默认最大重试次数
+	DefaultMaxRetries = 3
+	// DefaultInitialBackoff 默认初始退避时间
+	DefaultInitialBackoff = time.Second
+	// DefaultMaxBackoff 默认最大退避时间
+	DefaultMaxBackoff = 30 * time.Second
+)
+
+// Exponential 默认重试策略：按 Classify 判断是否重试，等待时间为 [0, min(MaxBackoff, InitialBackoff*2^n)] 内的随机值（full jitter）
+type Exponential struct {
+	// MaxRetries 最大重试次数
+	MaxRetries int
+	// InitialBackoff 初始退避时间
+	InitialBackoff time.Duration
+	// MaxBackoff 最大退避时间
+	MaxBackoff time.Duration
+	// Classify 错误分类函数，为空时使用 Classify
+	Classify func(err error) Class
+}
+
+// NewExponential 创建默认重试策略
+func NewExponential(maxRetries int, initialBackoff, maxBackoff time.Duration) *Exponential {
+	return &Exponential{
+		MaxRetries:     maxRetries,
+		InitialBackoff: initialBackoff,
+		MaxBackoff:     maxBackoff,
+	}
+}
+
+// Backoff 计算第 attempt 次重试（从 0 开始）的退避上限（指数退避算法）
+// backoff = InitialBackoff * 2^attempt
+func (p *Exponential) Backoff(attempt int) time.Duration {
+	if attempt > 30 {
+		return p.MaxBackoff
+	}
+	backoff := p.InitialBackoff * (1 << attempt)
+	if backoff > p.MaxBackoff || backoff <= 0 {
+		return p.MaxBackoff
+	}
+	return backoff
+}
+
+// Retry 实现 Policy
+func (p *Exponential) Retry(_ context.Context, a *Attempt) (time.Duration, bool) {
+	if a.Attempt > p.MaxRetries {
+		return 0, false
+	}
+	classify := p.Classify
+	if classify == nil {
+		classify = Classify
+	}
+	if !classify(a.Err).Retriable(a.Idempotent) {
+		return 0, false
+	}
+	return jitter(p.Backoff(a.Attempt - 1)), true
+}
+
+// jitter 返回 [0, d] 内的随机时间
+func jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
@@ -0,0 +1,139 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+)
+
+// timeoutError 测试用的超时错误
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func urlError(err error) error {
+	return fmt.Errorf("http request failed: %w", &url.Error{Op: "Post", URL: "/cgi-bin/message/send", Err: err})
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want Class
+	}{
+		{"nil", nil, ClassPermanent},
+		{"token expired", wecomerrors.New(wecomerrors.ErrCodeAccessTokenExpired, "expired"), ClassTokenExpired},
+		{"invalid token", wecomerrors.New(wecomerrors.ErrCodeInvalidAccessToken, "invalid"), ClassTokenExpired},
+		{"rate limited", wecomerrors.New(wecomerrors.ErrCodeAPIFreqLimit, "freq"), ClassRateLimited},
+		{"system busy", wecomerrors.New(wecomerrors.ErrCodeSystemBusy, "busy"), ClassSystemBusy},
+		{"system error", wecomerrors.New(wecomerrors.ErrCodeSystemError, "busy"), ClassSystemBusy},
+		{"invalid parameter", wecomerrors.New(wecomerrors.ErrCodeInvalidParameter, "invalid"), ClassPermanent},
+		{"circuit open", fmt.Errorf("wrapped: %w", wecomerrors.ErrCircuitOpen), ClassPermanent},
+		{"canceled", urlError(context.Canceled), ClassPermanent},
+		{"dns", urlError(&net.DNSError{Err: "no such host", Name: "qyapi.weixin.qq.com"}), ClassNetworkBeforeSend},
+		{"dial refused", urlError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}), ClassNetworkBeforeSend},
+		{"dial timeout", urlError(&net.OpError{Op: "dial", Net: "tcp", Err: timeoutError{}}), ClassNetworkBeforeSend},
+		{"read reset", urlError(&net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}), ClassNetworkAfterSend},
+		{"timeout", urlError(timeoutError{}), ClassNetworkAfterSend},
+		{"eof", urlError(io.EOF), ClassNetworkAfterSend},
+		{"unexpected eof", fmt.Errorf("read body: %w", io.ErrUnexpectedEOF), ClassNetworkAfterSend},
+		{"standard error", errors.New("standard error"), ClassPermanent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestClass_Retriable(t *testing.T) {
+	for _, c := range []Class{ClassTokenExpired, ClassRateLimited, ClassSystemBusy, ClassNetworkBeforeSend} {
+		assert.True(t, c.Retriable(false), c.String())
+		assert.True(t, c.Retriable(true), c.String())
+	}
+	assert.False(t, ClassNetworkAfterSend.Retriable(false))
+	assert.True(t, ClassNetworkAfterSend.Retriable(true))
+	assert.False(t, ClassPermanent.Retriable(true))
+	assert.Equal(t, "class(99)", Class(99).String())
+}
+
+func TestExponential_Backoff(t *testing.T) {
+	p := NewExponential(5, time.Second, 30*time.Second)
+
+	assert.Equal(t, time.Second, p.Backoff(0))
+	assert.Equal(t, 8*time.Second, p.Backoff(3))
+	assert.Equal(t, 30*time.Second, p.Backoff(5))
+	// 不会溢出
+	assert.Equal(t, 30*time.Second, p.Backoff(62))
+	assert.Equal(t, 30*time.Second, p.Backoff(100))
+}
+
+func TestExponential_RetryJitter(t *testing.T) {
+	p := NewExponential(5, 100*time.Millisecond, time.Second)
+	busy := wecomerrors.New(wecomerrors.ErrCodeSystemBusy, "busy")
+
+	for attempt := 1; attempt <= 5; attempt++ {
+		ceiling := p.Backoff(attempt - 1)
+		for i := 0; i < 100; i++ {
+			wait, ok := p.Retry(context.Background(), &Attempt{Attempt: attempt, Err: busy})
+			assert.True(t, ok)
+			assert.GreaterOrEqual(t, wait, time.Duration(0))
+			assert.LessOrEqual(t, wait, ceiling)
+		}
+	}
+}
+
+func TestExponential_RetryMaxRetries(t *testing.T) {
+	p := NewExponential(2, time.Millisecond, time.Millisecond)
+	busy := wecomerrors.New(wecomerrors.ErrCodeSystemBusy, "busy")
+
+	_, ok := p.Retry(context.Background(), &Attempt{Attempt: 2, Err: busy})
+	assert.True(t, ok)
+	_, ok = p.Retry(context.Background(), &Attempt{Attempt: 3, Err: busy})
+	assert.False(t, ok)
+
+	_, ok = NewExponential(0, time.Millisecond, time.Millisecond).Retry(context.Background(), &Attempt{Attempt: 1, Err: busy})
+	assert.False(t, ok)
+}
+
+func TestExponential_RetryIdempotency(t *testing.T) {
+	p := NewExponential(3, time.Millisecond, time.Millisecond)
+	timeout := urlError(timeoutError{})
+
+	_, ok := p.Retry(context.Background(), &Attempt{Method: "POST", Attempt: 1, Err: timeout})
+	assert.False(t, ok, "non-idempotent request must not be retried after it was sent")
+
+	_, ok = p.Retry(context.Background(), &Attempt{Method: "POST", Attempt: 1, Idempotent: true, Err: timeout})
+	assert.True(t, ok)
+
+	refused := urlError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})
+	_, ok = p.Retry(context.Background(), &Attempt{Method: "POST", Attempt: 1, Err: refused})
+	assert.True(t, ok, "request that was never sent can always be retried")
+}
+
+func TestExponential_CustomClassify(t *testing.T) {
+	p := NewExponential(3, time.Millisecond, time.Millisecond)
+	p.Classify = func(err error) Class {
+		if c := Classify(err); c != ClassRateLimited {
+			return c
+		}
+		return ClassPermanent
+	}
+
+	_, ok := p.Retry(context.Background(), &Attempt{Attempt: 1, Err: wecomerrors.New(wecomerrors.ErrCodeAPIFreqLimit, "freq")})
+	assert.False(t, ok)
+	_, ok = p.Retry(context.Background(), &Attempt{Attempt: 1, Err: wecomerrors.New(wecomerrors.ErrCodeSystemBusy, "busy")})
+	assert.True(t, ok)
+}
@@ -0,0 +1,140 @@
+// Package upload 定义文件上传的数据源
+//
+// 上传接口从 Source 流式读取文件内容，内存占用与文件大小无关；
+// 请求失败重试时重新调用 Open 从头读取，因此数据源必须可以重复打开。
+package upload
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Source 可重复打开、长度已知的上传数据源
+type Source interface {
+	// Name 文件名
+	Name() string
+	// Size 内容长度（字节）
+	Size() int64
+	// Open 打开数据源并从头读取，每次请求尝试调用一次，返回的 ReadCloser 由调用方关闭
+	Open() (io.ReadCloser, error)
+}
+
+// File 创建本地文件数据源，文件名取路径的最后一部分
+func File(path string) (Source, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat upload file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, fmt.Errorf("upload file %s is not a regular file", path)
+	}
+	return &fileSource{path: path, size: info.Size()}, nil
+}
+
+// ReaderAt 创建 io.ReaderAt 数据源（如 *os.File、*bytes.Reader），读取 [0, size) 范围内的内容
+// 每次打开返回独立的 SectionReader，Close 不会关闭 r
+func ReaderAt(name string, r io.ReaderAt, size int64) Source {
+	return &readerAtSource{name: name, r: r, size: size}
+}
+
+// Bytes 创建内存数据源
+func Bytes(name string, data []byte) Source {
+	return ReaderAt(name, bytes.NewReader(data), int64(len(data)))
+}
+
+// Func 创建由工厂函数提供内容的数据源，每次打开调用 open 获取新的 Reader
+// open 返回的 Reader 实现 io.Closer 时由调用方关闭
+func Func(name string, size int64, open func() (io.Reader, error)) Source {
+	return &funcSource{name: name, size: size, open: open}
+}
+
+// FromReader 将 io.Reader 转换为数据源
+// *os.File、*bytes.Reader、*strings.Reader 与 *io.SectionReader 从当前位置开始直接读取，不复制内容；
+// 其他 Reader 无法重复读取，内容会被读入内存
+func FromReader(name string, r io.Reader) (Source, error) {
+	switch v := r.(type) {
+	case *os.File:
+		info, err := v.Stat()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat upload file: %w", err)
+		}
+		if info.Mode().IsRegular() {
+			offset, err := v.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, fmt.Errorf("failed to seek upload file: %w", err)
+			}
+			return ReaderAt(name, io.NewSectionReader(v, offset, info.Size()-offset), info.Size()-offset), nil
+		}
+	case interface {
+		io.ReaderAt
+		io.Seeker
+		Size() int64
+	}:
+		offset, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to seek upload reader: %w", err)
+		}
+		return ReaderAt(name, io.NewSectionReader(v, offset, v.Size()-offset), v.Size()-offset), nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload content: %w", err)
+	}
+	return Bytes(name, data), nil
+}
+
+// fileSource 本地文件数据源
+type fileSource struct {
+	path string
+	size int64
+}
+
+func (s *fileSource) Name() string { return filepath.Base(s.path) }
+func (s *fileSource) Size() int64  { return s.size }
+
+func (s *fileSource) Open() (io.ReadCloser, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open upload file: %w", err)
+	}
+	return f, nil
+}
+
+// readerAtSource io.ReaderAt 数据源
+type readerAtSource struct {
+	name string
+	r    io.ReaderAt
+	size int64
+}
+
+func (s *readerAtSource) Name() string { return s.name }
+func (s *readerAtSource) Size() int64  { return s.size }
+
+func (s *readerAtSource) Open() (io.ReadCloser, error) {
+	return io.NopCloser(io.NewSectionReader(s.r, 0, s.size)), nil
+}
+
+// funcSource 工厂函数数据源
+type funcSource struct {
+	name string
+	size int64
+	open func() (io.Reader, error)
+}
+
+func (s *funcSource) Name() string { return s.name }
+func (s *funcSource) Size() int64  { return s.size }
+
+func (s *funcSource) Open() (io.ReadCloser, error) {
+	r, err := s.open()
+	if err != nil {
+		return nil, err
+	}
+	if rc, ok := r.(io.ReadCloser); ok {
+		return rc, nil
+	}
+	return io.NopCloser(r), nil
+}
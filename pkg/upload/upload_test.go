@@ -0,0 +1,89 @@
+package upload
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll 打开数据源并读取全部内容
+func readAll(t *testing.T, src Source) string {
+	t.Helper()
+	rc, err := src.Open()
+	require.NoError(t, err)
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.txt")
+	require.NoError(t, os.WriteFile(path, []byte("report"), 0o600))
+
+	src, err := File(path)
+	require.NoError(t, err)
+	assert.Equal(t, "report.txt", src.Name())
+	assert.Equal(t, int64(6), src.Size())
+	// 可以重复打开
+	assert.Equal(t, "report", readAll(t, src))
+	assert.Equal(t, "report", readAll(t, src))
+
+	_, err = File(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+	_, err = File(t.TempDir())
+	assert.Error(t, err)
+}
+
+func TestReaderAt(t *testing.T) {
+	src := ReaderAt("a.txt", strings.NewReader("hello world"), 5)
+	assert.Equal(t, int64(5), src.Size())
+	assert.Equal(t, "hello", readAll(t, src))
+	assert.Equal(t, "hello", readAll(t, src))
+
+	src = Bytes("b.txt", []byte("bytes"))
+	assert.Equal(t, "b.txt", src.Name())
+	assert.Equal(t, "bytes", readAll(t, src))
+}
+
+func TestFunc(t *testing.T) {
+	opened := 0
+	src := Func("c.txt", 3, func() (io.Reader, error) {
+		opened++
+		return strings.NewReader("abc"), nil
+	})
+	assert.Equal(t, "abc", readAll(t, src))
+	assert.Equal(t, "abc", readAll(t, src))
+	assert.Equal(t, 2, opened)
+}
+
+func TestFromReader(t *testing.T) {
+	// 从当前位置开始读取
+	r := strings.NewReader("skip:content")
+	_, _ = r.Seek(5, io.SeekStart)
+	src, err := FromReader("a.txt", r)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), src.Size())
+	assert.Equal(t, "content", readAll(t, src))
+	assert.Equal(t, "content", readAll(t, src))
+
+	path := filepath.Join(t.TempDir(), "b.txt")
+	require.NoError(t, os.WriteFile(path, []byte("file"), 0o600))
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+	src, err = FromReader("b.txt", f)
+	require.NoError(t, err)
+	assert.Equal(t, "file", readAll(t, src))
+
+	// 不可重复读取的 Reader 读入内存
+	src, err = FromReader("c.txt", io.MultiReader(strings.NewReader("multi")))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), src.Size())
+	assert.Equal(t, "multi", readAll(t, src))
+}
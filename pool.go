@@ -0,0 +1,328 @@
+package wecom
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/internal/auth"
+	"github.com/shuaidd/wecom-core/pkg/ratelimit"
+)
+
+// DefaultIdleTimeout 连接池中客户端默认的空闲淘汰时间
+const DefaultIdleTimeout = 30 * time.Minute
+
+// corpIDKey 企业ID的 context key
+type corpIDKey struct{}
+
+// WithCorpID 将企业ID添加到 context，配合 ClientPool.FromContext 使用
+// 可以与 WithAgentName/WithAgentID 组合，同时指定企业与应用
+func WithCorpID(ctx context.Context, corpID string) context.Context {
+	return context.WithValue(ctx, corpIDKey{}, corpID)
+}
+
+// CorpIDFromContext 从 context 中获取通过 WithCorpID 指定的企业ID
+func CorpIDFromContext(ctx context.Context) string {
+	corpID, _ := ctx.Value(corpIDKey{}).(string)
+	return corpID
+}
+
+// ConfigProvider 按企业ID提供客户端配置（凭证、应用、回调等）
+type ConfigProvider interface {
+	// CorpOptions 返回企业的配置选项，CorpID 由连接池设置；企业不存在时返回 config.ErrCorpNotFound
+	CorpOptions(ctx context.Context, corpID string) ([]config.Option, error)
+}
+
+// ConfigProviderFunc 函数形式的 ConfigProvider
+type ConfigProviderFunc func(ctx context.Context, corpID string) ([]config.Option, error)
+
+// CorpOptions 实现 ConfigProvider
+func (f ConfigProviderFunc) CorpOptions(ctx context.Context, corpID string) ([]config.Option, error) {
+	return f(ctx, corpID)
+}
+
+// PoolOption 连接池选项
+type PoolOption func(*ClientPool)
+
+// WithSharedOptions 设置所有企业客户端共用的配置，如日志、重试、拦截器与可观测性钩子
+// 企业配置在共用配置之后应用，可以覆盖共用配置
+func WithSharedOptions(opts ...config.Option) PoolOption {
+	return func(p *ClientPool) {
+		p.shared = append(p.shared, opts...)
+	}
+}
+
+// WithIdleTimeout 设置客户端空闲淘汰时间，超过该时间未通过连接池获取的客户端会被关闭并移出连接池
+// 默认 30 分钟，小于等于 0 时不淘汰
+func WithIdleTimeout(d time.Duration) PoolOption {
+	return func(p *ClientPool) {
+		p.idleTimeout = d
+	}
+}
+
+// pooledClient 连接池中的客户端
+type pooledClient struct {
+	client   *Client
+	lastUsed time.Time
+}
+
+// ClientPool 多企业客户端连接池
+// 按企业ID延迟创建并缓存客户端，所有客户端共享同一 HTTP 传输层、token 缓存与限流配额，可在多个 goroutine 中并发使用
+//
+// 共用配置中未设置 Transport、Cache 与 RateLimiter 时，连接池会各创建一个供所有企业共享；
+// 限流器默认使用 ratelimit.New() 的规则，每 IP 的配额在所有企业之间共享
+type ClientPool struct {
+	provider    ConfigProvider
+	shared      []config.Option
+	idleTimeout time.Duration
+
+	mu sync.Mutex
+	// corps 通过 Set 设置的企业配置，优先于 provider
+	corps map[string][]config.Option
+	// clients 已创建的客户端
+	clients map[string]*pooledClient
+	// versions 企业配置的版本，Set/Remove 时递增，用于丢弃基于旧配置创建的客户端
+	versions map[string]uint64
+	closed   bool
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewClientPool 创建多企业客户端连接池
+// provider 为空时只能获取通过 Set 添加的企业
+//
+// 示例：
+//
+//	pool := wecom.NewClientPool(
+//	    wecom.ConfigProviderFunc(func(ctx context.Context, corpID string) ([]config.Option, error) {
+//	        corp, err := db.LoadCorp(ctx, corpID)
+//	        if err != nil {
+//	            return nil, err
+//	        }
+//	        return []config.Option{
+//	            config.WithAgent("notify", corp.AgentID, corp.Secret),
+//	        }, nil
+//	    }),
+//	    wecom.WithSharedOptions(config.WithLogger(log)),
+//	)
+//	defer pool.Close()
+//
+//	ctx = wecom.WithAgentName(wecom.WithCorpID(ctx, corpID), "notify")
+//	client, err := pool.FromContext(ctx)
+//	resp, err := client.Message.Send(ctx, req)
+func NewClientPool(provider ConfigProvider, opts ...PoolOption) *ClientPool {
+	p := &ClientPool{
+		provider:    provider,
+		idleTimeout: DefaultIdleTimeout,
+		corps:       make(map[string][]config.Option),
+		clients:     make(map[string]*pooledClient),
+		versions:    make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	// 共享传输层、缓存与限流器
+	base := config.New(p.shared...)
+	if base.Transport == nil {
+		var transport http.RoundTripper = http.DefaultTransport
+		if t, ok := transport.(*http.Transport); ok {
+			transport = t.Clone()
+		}
+		p.shared = append(p.shared, config.WithTransport(transport))
+	}
+	if base.Cache == nil {
+		p.shared = append(p.shared, config.WithCache(auth.NewMemoryCache()))
+	}
+	if base.RateLimiter == nil {
+		p.shared = append(p.shared, config.WithRateLimit(ratelimit.New()))
+	}
+
+	if p.idleTimeout > 0 {
+		p.stop = make(chan struct{})
+		p.done = make(chan struct{})
+		go p.evictLoop()
+	}
+	return p
+}
+
+// Get 返回企业的客户端，首次获取时根据配置创建
+// 返回的客户端可以立即使用，但不应长期持有：配置更新、移除或空闲淘汰后连接池会关闭旧客户端（停止后台刷新）
+func (p *ClientPool) Get(ctx context.Context, corpID string) (*Client, error) {
+	if corpID == "" {
+		return nil, config.ErrMissingCorpID
+	}
+
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, config.ErrPoolClosed
+		}
+		if pc, ok := p.clients[corpID]; ok {
+			pc.lastUsed = time.Now()
+			p.mu.Unlock()
+			return pc.client, nil
+		}
+		version := p.versions[corpID]
+		opts, ok := p.corps[corpID]
+		p.mu.Unlock()
+
+		// 在锁外读取配置并创建客户端，避免阻塞其他企业
+		if !ok {
+			if p.provider == nil {
+				return nil, config.ErrCorpNotFound
+			}
+			var err error
+			if opts, err = p.provider.CorpOptions(ctx, corpID); err != nil {
+				return nil, err
+			}
+		}
+		c, err := p.build(corpID, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		p.mu.Lock()
+		existing, exists := p.clients[corpID]
+		switch {
+		case p.closed:
+			p.mu.Unlock()
+			_ = c.Close()
+			return nil, config.ErrPoolClosed
+		case exists:
+			// 其他 goroutine 已创建
+			existing.lastUsed = time.Now()
+			p.mu.Unlock()
+			_ = c.Close()
+			return existing.client, nil
+		case p.versions[corpID] != version:
+			// 创建期间配置被更新或移除，按新配置重新创建
+			p.mu.Unlock()
+			_ = c.Close()
+			continue
+		}
+		p.clients[corpID] = &pooledClient{client: c, lastUsed: time.Now()}
+		p.mu.Unlock()
+		return c, nil
+	}
+}
+
+// FromContext 返回 context 中通过 WithCorpID 指定的企业的客户端
+// 应用仍通过 context 中的 WithAgentName/WithAgentID 选择
+func (p *ClientPool) FromContext(ctx context.Context) (*Client, error) {
+	return p.Get(ctx, CorpIDFromContext(ctx))
+}
+
+// Set 添加或更新企业配置（如凭证轮换），已创建的客户端会被关闭，下次获取时按新配置创建
+// 通过 Set 添加的配置优先于 provider
+func (p *ClientPool) Set(corpID string, opts ...config.Option) {
+	p.mu.Lock()
+	p.corps[corpID] = opts
+	old := p.removeLocked(corpID)
+	p.mu.Unlock()
+
+	if old != nil {
+		_ = old.Close()
+	}
+}
+
+// Remove 移除企业配置并关闭其客户端
+// 配置来自 provider 的企业下次获取时会重新读取配置
+func (p *ClientPool) Remove(corpID string) {
+	p.mu.Lock()
+	delete(p.corps, corpID)
+	old := p.removeLocked(corpID)
+	p.mu.Unlock()
+
+	if old != nil {
+		_ = old.Close()
+	}
+}
+
+// Len 返回连接池中已创建的客户端数量
+func (p *ClientPool) Len() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.clients)
+}
+
+// Close 关闭连接池及其中所有客户端
+func (p *ClientPool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	clients := p.clients
+	p.clients = make(map[string]*pooledClient)
+	p.mu.Unlock()
+
+	if p.stop != nil {
+		close(p.stop)
+		<-p.done
+	}
+	for _, pc := range clients {
+		_ = pc.client.Close()
+	}
+	return nil
+}
+
+// build 使用共用配置与企业配置创建客户端
+func (p *ClientPool) build(corpID string, opts []config.Option) (*Client, error) {
+	all := make([]config.Option, 0, len(p.shared)+len(opts)+1)
+	all = append(all, p.shared...)
+	all = append(all, opts...)
+	all = append(all, config.WithCorpID(corpID))
+	return New(all...)
+}
+
+// removeLocked 移除已创建的客户端并递增配置版本，调用方需持有锁，返回的客户端需在释放锁后关闭
+func (p *ClientPool) removeLocked(corpID string) *Client {
+	p.versions[corpID]++
+	pc, ok := p.clients[corpID]
+	if !ok {
+		return nil
+	}
+	delete(p.clients, corpID)
+	return pc.client
+}
+
+// evictLoop 定期淘汰空闲客户端
+func (p *ClientPool) evictLoop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(max(p.idleTimeout/2, time.Millisecond))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.evictIdle()
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// evictIdle 关闭并移除空闲超时的客户端
+func (p *ClientPool) evictIdle() {
+	p.mu.Lock()
+	now := time.Now()
+	var idle []*Client
+	for corpID, pc := range p.clients {
+		if now.Sub(pc.lastUsed) >= p.idleTimeout {
+			delete(p.clients, corpID)
+			idle = append(idle, pc.client)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, c := range idle {
+		_ = c.Close()
+	}
+}
@@ -0,0 +1,179 @@
+package wecom_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+// corpTokens 按企业签发 token 的配置，记录每个企业获取 token 的次数
+type corpTokens struct {
+	srv     *wecomtest.Server
+	mu      sync.Mutex
+	fetches map[string]int
+}
+
+func (c *corpTokens) options(corpID string) []config.Option {
+	return []config.Option{
+		config.WithTokenFetcher(func(ctx context.Context, agentKey string) (string, int, error) {
+			c.mu.Lock()
+			c.fetches[corpID]++
+			c.mu.Unlock()
+			return c.srv.IssueToken(corpID), 7200, nil
+		}),
+	}
+}
+
+func (c *corpTokens) count(corpID string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.fetches[corpID]
+}
+
+func TestClientPool(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+
+	tokens := &corpTokens{srv: srv, fetches: make(map[string]int)}
+	var provided int32
+	pool := wecom.NewClientPool(
+		wecom.ConfigProviderFunc(func(ctx context.Context, corpID string) ([]config.Option, error) {
+			if corpID != "corp-a" && corpID != "corp-b" {
+				return nil, config.ErrCorpNotFound
+			}
+			atomic.AddInt32(&provided, 1)
+			return tokens.options(corpID), nil
+		}),
+		wecom.WithSharedOptions(srv.Options()...),
+	)
+	defer pool.Close()
+	ctx := context.Background()
+
+	a, err := pool.Get(ctx, "corp-a")
+	require.NoError(t, err)
+	same, err := pool.Get(ctx, "corp-a")
+	require.NoError(t, err)
+	assert.Same(t, a, same)
+	b, err := pool.Get(ctx, "corp-b")
+	require.NoError(t, err)
+	assert.NotSame(t, a, b)
+	assert.Equal(t, 2, pool.Len())
+	assert.Equal(t, int32(2), atomic.LoadInt32(&provided))
+
+	// 企业通过 context 选择
+	bctx := wecom.WithCorpID(ctx, "corp-b")
+	client, err := pool.FromContext(bctx)
+	require.NoError(t, err)
+	_, err = client.Contact.GetUser(bctx, "zhangsan")
+	require.NoError(t, err)
+	srv.AssertAgent(t, "/cgi-bin/user/get", "corp-b")
+
+	_, err = pool.Get(ctx, "corp-x")
+	assert.ErrorIs(t, err, config.ErrCorpNotFound)
+	_, err = pool.FromContext(ctx)
+	assert.ErrorIs(t, err, config.ErrMissingCorpID)
+
+	// 运行时添加与更新企业配置，token 缓存在客户端之间共享
+	pool.Set("corp-c", tokens.options("corp-c")...)
+	c, err := pool.Get(ctx, "corp-c")
+	require.NoError(t, err)
+	_, err = c.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	srv.AssertAgent(t, "/cgi-bin/user/get", "corp-c")
+
+	pool.Set("corp-c", tokens.options("corp-c")...)
+	updated, err := pool.Get(ctx, "corp-c")
+	require.NoError(t, err)
+	assert.NotSame(t, c, updated)
+	_, err = updated.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	assert.Equal(t, 1, tokens.count("corp-c"))
+
+	pool.Remove("corp-c")
+	_, err = pool.Get(ctx, "corp-c")
+	assert.ErrorIs(t, err, config.ErrCorpNotFound)
+
+	// 配置来自 provider 的企业移除后重新读取配置
+	pool.Remove("corp-a")
+	reloaded, err := pool.Get(ctx, "corp-a")
+	require.NoError(t, err)
+	assert.NotSame(t, a, reloaded)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&provided))
+
+	require.NoError(t, pool.Close())
+	_, err = pool.Get(ctx, "corp-a")
+	assert.ErrorIs(t, err, config.ErrPoolClosed)
+}
+
+func TestClientPool_Concurrent(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+
+	tokens := &corpTokens{srv: srv, fetches: make(map[string]int)}
+	pool := wecom.NewClientPool(
+		wecom.ConfigProviderFunc(func(ctx context.Context, corpID string) ([]config.Option, error) {
+			return tokens.options(corpID), nil
+		}),
+		wecom.WithSharedOptions(srv.Options()...),
+	)
+	defer pool.Close()
+
+	corps := []string{"corp-a", "corp-b", "corp-c"}
+	clients := make([]*wecom.Client, 30)
+	var wg sync.WaitGroup
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := wecom.WithCorpID(context.Background(), corps[i%len(corps)])
+			client, err := pool.FromContext(ctx)
+			if !assert.NoError(t, err) {
+				return
+			}
+			clients[i] = client
+			_, err = client.Contact.GetUser(ctx, "zhangsan")
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, len(corps), pool.Len())
+	for i := range clients {
+		assert.Same(t, clients[i%len(corps)], clients[i])
+	}
+	for _, corpID := range corps {
+		assert.Equal(t, 1, tokens.count(corpID), corpID)
+	}
+}
+
+func TestClientPool_Idle(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	tokens := &corpTokens{srv: srv, fetches: make(map[string]int)}
+	pool := wecom.NewClientPool(nil,
+		wecom.WithSharedOptions(srv.Options()...),
+		wecom.WithIdleTimeout(20*time.Millisecond),
+	)
+	defer pool.Close()
+
+	pool.Set("corp-a", tokens.options("corp-a")...)
+	_, err := pool.Get(context.Background(), "corp-a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, pool.Len())
+
+	assert.Eventually(t, func() bool { return pool.Len() == 0 }, time.Second, 5*time.Millisecond)
+
+	// 淘汰后再次获取时重新创建
+	_, err = pool.Get(context.Background(), "corp-a")
+	require.NoError(t, err)
+	_, err = pool.Get(context.Background(), "corp-b")
+	assert.ErrorIs(t, err, config.ErrCorpNotFound)
+}
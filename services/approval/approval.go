@@ -2,6 +2,7 @@ package approval
 
 import (
 	"context"
+	"iter"
 	"net/url"
 
 	"github.com/shuaidd/wecom-core/internal/client"
@@ -66,6 +67,20 @@ func (s *Service) GetApprovalInfo(ctx context.Context, req *approval.GetApproval
 	return client.PostAndUnmarshal[approval.GetApprovalInfoResponse](s.client, ctx, getApprovalInfoURL, req)
 }
 
+// AllApprovalInfo 遍历时间范围内的全部审批单号
+// 自动处理 new_next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllApprovalInfo(ctx context.Context, req *approval.GetApprovalInfoRequest) iter.Seq2[string, error] {
+	r := *req
+	return client.Paginate(ctx, r.NewCursor, func(ctx context.Context, cursor string) (*client.Page[string, string], error) {
+		r.NewCursor = cursor
+		resp, err := s.GetApprovalInfo(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return client.CursorPage(resp.SpNoList, resp.NewNextCursor), nil
+	})
+}
+
 // GetApprovalDetail 获取审批申请详情
 func (s *Service) GetApprovalDetail(ctx context.Context, req *approval.GetApprovalDetailRequest) (*approval.GetApprovalDetailResponse, error) {
 	return client.PostAndUnmarshal[approval.GetApprovalDetailResponse](s.client, ctx, getApprovalDetailURL, req)
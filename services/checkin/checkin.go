@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/pkg/upload"
 	"github.com/shuaidd/wecom-core/types/checkin"
 	"github.com/shuaidd/wecom-core/types/common"
 )
@@ -43,6 +44,17 @@ func (s *Service) AddCheckinUserFace(ctx context.Context, req *checkin.AddChecki
 	return err
 }
 
+// AddCheckinUserFaceFromSource 从数据源录入人脸信息
+// 照片内容在发送时流式编码为 base64，无需预先读入内存
+func (s *Service) AddCheckinUserFaceFromSource(ctx context.Context, userID string, src upload.Source) error {
+	body, err := client.NewBase64JSONBody(map[string]string{"userid": userID}, "userface", src)
+	if err != nil {
+		return err
+	}
+	_, err = client.PostStreamAndUnmarshal[common.Response](s.client, ctx, "/cgi-bin/checkin/addcheckinuserface", nil, body)
+	return err
+}
+
 // AddCheckinRecord 添加打卡记录
 func (s *Service) AddCheckinRecord(ctx context.Context, req *checkin.AddCheckinRecordRequest) error {
 	_, err := client.PostAndUnmarshal[common.Response](s.client, ctx, "/cgi-bin/checkin/add_checkin_record", req)
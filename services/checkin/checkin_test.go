@@ -0,0 +1,25 @@
+package checkin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestAddCheckinUserFaceFromSource(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/checkin/addcheckinuserface", map[string]any{"errcode": 0, "errmsg": "ok"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	// 人脸照片以 base64 字符串提交
+	err = client.Checkin.AddCheckinUserFaceFromSource(context.Background(), "zhangsan", upload.Bytes("face.jpg", []byte("hello")))
+	require.NoError(t, err)
+	srv.AssertBody(t, "/cgi-bin/checkin/addcheckinuserface", `{"userid": "zhangsan", "userface": "aGVsbG8="}`)
+}
@@ -3,6 +3,7 @@ package contact
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/url"
 
 	"github.com/shuaidd/wecom-core/internal/client"
@@ -90,6 +91,29 @@ func (s *Service) ListUserIDs(ctx context.Context, req *contact.ListUserIDsReque
 	return client.PostAndUnmarshal[contact.ListUserIDsResponse](s.client, ctx, "/cgi-bin/user/list_id", req)
 }
 
+// AllUserIDs 遍历全部成员ID
+// 自动处理 next_cursor 翻页，出现错误或 context 取消时迭代结束
+//
+// 示例：
+//
+//	for user, err := range client.Contact.AllUserIDs(ctx, &contact.ListUserIDsRequest{Limit: 10000}) {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    fmt.Println(user.UserID, user.Department)
+//	}
+func (s *Service) AllUserIDs(ctx context.Context, req *contact.ListUserIDsRequest) iter.Seq2[contact.DeptUser, error] {
+	r := *req
+	return client.Paginate(ctx, r.Cursor, func(ctx context.Context, cursor string) (*client.Page[string, contact.DeptUser], error) {
+		r.Cursor = cursor
+		resp, err := s.ListUserIDs(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return client.CursorPage(resp.DeptUser, resp.NextCursor), nil
+	})
+}
+
 // AuthSuccess 二次验证
 // 文档: https://developer.work.weixin.qq.com/document/path/90203
 func (s *Service) AuthSuccess(ctx context.Context, userID string) error {
@@ -2,6 +2,7 @@ package externalcontact
 
 import (
 	"context"
+	"iter"
 
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/types/externalcontact"
@@ -13,3 +14,17 @@ import (
 func (s *Service) GetContactList(ctx context.Context, req *externalcontact.GetContactListRequest) (*externalcontact.GetContactListResponse, error) {
 	return client.PostAndUnmarshal[externalcontact.GetContactListResponse](s.client, ctx, "/cgi-bin/externalcontact/contact_list", req)
 }
+
+// AllContacts 遍历全部已服务的外部联系人
+// 自动处理 next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllContacts(ctx context.Context, req *externalcontact.GetContactListRequest) iter.Seq2[externalcontact.ContactInfo, error] {
+	r := *req
+	return client.Paginate(ctx, r.Cursor, func(ctx context.Context, cursor string) (*client.Page[string, externalcontact.ContactInfo], error) {
+		r.Cursor = cursor
+		resp, err := s.GetContactList(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return client.CursorPage(resp.InfoList, resp.NextCursor), nil
+	})
+}
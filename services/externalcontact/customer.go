@@ -2,6 +2,7 @@ package externalcontact
 
 import (
 	"context"
+	"iter"
 	"net/url"
 
 	"github.com/shuaidd/wecom-core/internal/client"
@@ -44,3 +45,17 @@ func (s *Service) UpdateRemark(ctx context.Context, req *externalcontact.UpdateR
 func (s *Service) BatchGetByUser(ctx context.Context, req *externalcontact.BatchGetByUserRequest) (*externalcontact.BatchGetByUserResponse, error) {
 	return client.PostAndUnmarshal[externalcontact.BatchGetByUserResponse](s.client, ctx, "/cgi-bin/externalcontact/batch/get_by_user", req)
 }
+
+// AllByUser 遍历指定成员添加的全部客户详情
+// 自动处理 next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllByUser(ctx context.Context, req *externalcontact.BatchGetByUserRequest) iter.Seq2[externalcontact.ExternalContactItem, error] {
+	r := *req
+	return client.Paginate(ctx, r.Cursor, func(ctx context.Context, cursor string) (*client.Page[string, externalcontact.ExternalContactItem], error) {
+		r.Cursor = cursor
+		resp, err := s.BatchGetByUser(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return client.CursorPage(resp.ExternalContactList, resp.NextCursor), nil
+	})
+}
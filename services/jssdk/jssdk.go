@@ -0,0 +1,89 @@
+package jssdk
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/shuaidd/wecom-core/internal/auth"
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/types/jssdk"
+)
+
+// Service JS-SDK服务
+// 负责 jsapi_ticket 的缓存与 wx.config / wx.agentConfig 的签名
+type Service struct {
+	tickets *auth.TicketManager
+}
+
+// NewService 创建JS-SDK服务
+func NewService(tickets *auth.TicketManager) *Service {
+	return &Service{
+		tickets: tickets,
+	}
+}
+
+// SignCorpConfig 生成 wx.config 的注入配置
+// 使用 context 中通过 WithAgentName/WithAgentID 指定的应用获取企业的 jsapi_ticket
+// url 为调用 JS 接口页面的完整URL，# 及其后面部分会被忽略
+// 文档: https://developer.work.weixin.qq.com/document/path/90514
+func (s *Service) SignCorpConfig(ctx context.Context, url string) (*jssdk.ConfigSignature, error) {
+	ticket, err := s.tickets.GetJSAPITicket(ctx, client.AgentKeyFromContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	nonceStr, err := nonce()
+	if err != nil {
+		return nil, err
+	}
+	timestamp := time.Now().Unix()
+
+	return &jssdk.ConfigSignature{
+		AppID:     s.tickets.CorpID(),
+		Timestamp: timestamp,
+		NonceStr:  nonceStr,
+		Signature: auth.SignJSAPI(ticket, nonceStr, timestamp, url),
+	}, nil
+}
+
+// SignAgentConfig 生成 wx.agentConfig 的注入配置
+// agentKey 为应用名称或ID，只配置了一个应用时可为空
+// url 为调用 JS 接口页面的完整URL，# 及其后面部分会被忽略
+// 文档: https://developer.work.weixin.qq.com/document/path/94313
+func (s *Service) SignAgentConfig(ctx context.Context, agentKey, url string) (*jssdk.AgentConfigSignature, error) {
+	agentID, err := s.tickets.AgentID(agentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ticket, err := s.tickets.GetAgentConfigTicket(ctx, agentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	nonceStr, err := nonce()
+	if err != nil {
+		return nil, err
+	}
+	timestamp := time.Now().Unix()
+
+	return &jssdk.AgentConfigSignature{
+		CorpID:    s.tickets.CorpID(),
+		AgentID:   agentID,
+		Timestamp: timestamp,
+		NonceStr:  nonceStr,
+		Signature: auth.SignJSAPI(ticket, nonceStr, timestamp, url),
+	}, nil
+}
+
+// nonce 生成16位随机串
+func nonce() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,8 @@
+package kf
+
+// LockCount 返回拉取器当前保留的客服账号拉取锁数量，供 kf_test 包测试
+func (s *Syncer) LockCount() int {
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	return len(s.locks)
+}
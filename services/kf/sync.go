@@ -0,0 +1,202 @@
+package kf
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/types/kf"
+)
+
+// SyncMsg 读取消息
+// 微信客户发送的消息、接待人员在企业微信回复的消息、发送消息接口发送失败事件等,均可通过该接口获取
+// 收到 kf_msg_or_event 回调后,使用回调中的token调用该接口可获得更高的频率限制
+// 文档: https://developer.work.weixin.qq.com/document/path/94670
+func (s *Service) SyncMsg(ctx context.Context, req *kf.SyncMsgRequest) (*kf.SyncMsgResponse, error) {
+	return client.PostAndUnmarshal[kf.SyncMsgResponse](s.client, ctx, "/cgi-bin/kf/sync_msg", req)
+}
+
+// CursorStore 消息游标存储
+// 按客服账号保存 sync_msg 的 next_cursor，使服务重启后既不重复也不丢失消息
+type CursorStore interface {
+	// GetCursor 获取客服账号的游标，不存在时返回空字符串
+	GetCursor(ctx context.Context, openKfID string) (string, error)
+	// SetCursor 保存客服账号的游标
+	SetCursor(ctx context.Context, openKfID, cursor string) error
+}
+
+// MemoryCursorStore 内存游标存储（仅适用于单实例，重启后丢失）
+type MemoryCursorStore struct {
+	mu      sync.RWMutex
+	cursors map[string]string
+}
+
+// NewMemoryCursorStore 创建内存游标存储
+func NewMemoryCursorStore() *MemoryCursorStore {
+	return &MemoryCursorStore{
+		cursors: make(map[string]string),
+	}
+}
+
+// GetCursor 获取客服账号的游标
+func (s *MemoryCursorStore) GetCursor(ctx context.Context, openKfID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cursors[openKfID], nil
+}
+
+// SetCursor 保存客服账号的游标
+func (s *MemoryCursorStore) SetCursor(ctx context.Context, openKfID, cursor string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.cursors[openKfID] = cursor
+	return nil
+}
+
+// SyncHandler 消息处理函数，返回错误时停止拉取且不推进游标
+type SyncHandler func(ctx context.Context, msg *kf.SyncMessage) error
+
+// SyncerOption 消息拉取器选项
+type SyncerOption func(*Syncer)
+
+// WithSyncLimit 设置每次拉取的消息数量，默认且最大为1000
+func WithSyncLimit(limit int) SyncerOption {
+	return func(s *Syncer) {
+		s.limit = limit
+	}
+}
+
+// WithVoiceFormat 设置语音消息格式，0-Amr 1-Silk
+func WithVoiceFormat(format int) SyncerOption {
+	return func(s *Syncer) {
+		s.voiceFormat = format
+	}
+}
+
+// Syncer 微信客服消息拉取器
+// 自动翻页读取消息，并按客服账号持久化游标
+type Syncer struct {
+	service     *Service
+	store       CursorStore
+	limit       int
+	voiceFormat int
+
+	// locks 每个客服账号的拉取锁，避免并发回调重复处理同一批消息
+	locks   map[string]*syncLock
+	locksMu sync.Mutex
+}
+
+// syncLock 客服账号的拉取锁
+type syncLock struct {
+	sync.Mutex
+	// refs 持有或等待该锁的拉取数量，为 0 时从 locks 中删除
+	refs int
+}
+
+// NewSyncer 创建消息拉取器，store 为 nil 时使用内存游标存储
+func (s *Service) NewSyncer(store CursorStore, opts ...SyncerOption) *Syncer {
+	if store == nil {
+		store = NewMemoryCursorStore()
+	}
+	syncer := &Syncer{
+		service: s,
+		store:   store,
+		limit:   1000,
+		locks:   make(map[string]*syncLock),
+	}
+	for _, opt := range opts {
+		opt(syncer)
+	}
+	return syncer
+}
+
+// acquire 获取客服账号的拉取锁
+func (s *Syncer) acquire(openKfID string) *syncLock {
+	s.locksMu.Lock()
+	l, ok := s.locks[openKfID]
+	if !ok {
+		l = &syncLock{}
+		s.locks[openKfID] = l
+	}
+	l.refs++
+	s.locksMu.Unlock()
+
+	l.Lock()
+	return l
+}
+
+// release 释放客服账号的拉取锁，没有其他拉取等待时删除
+func (s *Syncer) release(openKfID string, l *syncLock) {
+	l.Unlock()
+
+	s.locksMu.Lock()
+	defer s.locksMu.Unlock()
+	l.refs--
+	if l.refs == 0 {
+		delete(s.locks, openKfID)
+	}
+}
+
+// Sync 拉取客服账号自上次游标以来的全部消息，并依次交给 fn 处理
+// token 为 kf_msg_or_event 回调中的 Token，返回成功处理的消息数量
+// 每页消息全部处理成功后才保存游标，处理失败时该页消息会在下次拉取时重新投递
+//
+// 示例：
+//
+//	syncer := client.KF.NewSyncer(redisCursorStore)
+//	h.HandleEvent(callback.EventKfMsgOrEvent, callback.Typed(func(ctx context.Context, e *callback.KfMsgOrEvent) (callback.Reply, error) {
+//	    _, err := syncer.Sync(ctx, e.Token, e.OpenKfID, func(ctx context.Context, msg *kf.SyncMessage) error {
+//	        return nil
+//	    })
+//	    return nil, err
+//	}))
+func (s *Syncer) Sync(ctx context.Context, token, openKfID string, fn SyncHandler) (int, error) {
+	l := s.acquire(openKfID)
+	defer s.release(openKfID, l)
+
+	cursor, err := s.store.GetCursor(ctx, openKfID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load kf cursor: %w", err)
+	}
+
+	handled := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+
+		resp, err := s.service.SyncMsg(ctx, &kf.SyncMsgRequest{
+			Cursor:      cursor,
+			Token:       token,
+			Limit:       s.limit,
+			VoiceFormat: s.voiceFormat,
+			OpenKfID:    openKfID,
+		})
+		if err != nil {
+			return handled, err
+		}
+
+		for i := range resp.MsgList {
+			if err := fn(ctx, &resp.MsgList[i]); err != nil {
+				return handled, err
+			}
+			handled++
+		}
+
+		advanced := resp.NextCursor != "" && resp.NextCursor != cursor
+		if advanced {
+			cursor = resp.NextCursor
+			if err := s.store.SetCursor(ctx, openKfID, cursor); err != nil {
+				return handled, fmt.Errorf("failed to save kf cursor: %w", err)
+			}
+		}
+
+		if resp.HasMore == 0 {
+			return handled, nil
+		}
+		if !advanced {
+			return handled, fmt.Errorf("kf sync_msg reported has_more without advancing cursor")
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package kf_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	kfsvc "github.com/shuaidd/wecom-core/services/kf"
+	"github.com/shuaidd/wecom-core/types/kf"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+// recordingStore 记录每次保存的游标
+type recordingStore struct {
+	*kfsvc.MemoryCursorStore
+	mu    sync.Mutex
+	saved []string
+}
+
+func (s *recordingStore) SetCursor(ctx context.Context, openKfID, cursor string) error {
+	s.mu.Lock()
+	s.saved = append(s.saved, cursor)
+	s.mu.Unlock()
+	return s.MemoryCursorStore.SetCursor(ctx, openKfID, cursor)
+}
+
+// page 构造 sync_msg 响应
+func page(nextCursor string, hasMore int, msgIDs ...string) map[string]any {
+	msgs := make([]map[string]any, 0, len(msgIDs))
+	for _, id := range msgIDs {
+		msgs = append(msgs, map[string]any{"msgid": id, "open_kfid": "wkAJ2GCAAAZSfhHCt7IFSvLKtMPxyJTw", "msgtype": "text"})
+	}
+	return map[string]any{"errcode": 0, "next_cursor": nextCursor, "has_more": hasMore, "msg_list": msgs}
+}
+
+func TestSyncer_Sync(t *testing.T) {
+	const openKfID = "wkAJ2GCAAAZSfhHCt7IFSvLKtMPxyJTw"
+	errHandler := errors.New("handler failed")
+
+	tests := []struct {
+		name          string
+		initialCursor string
+		pages         []any
+		failOn        string
+		wantHandled   []string
+		wantSaved     []string
+		wantRequested []string
+		wantCursor    string
+		wantErr       string
+	}{
+		{
+			name:          "cursor loaded from store and saved after page",
+			initialCursor: "c0",
+			pages:         []any{page("c1", 0, "m1", "m2")},
+			wantHandled:   []string{"m1", "m2"},
+			wantSaved:     []string{"c1"},
+			wantRequested: []string{"c0"},
+			wantCursor:    "c1",
+		},
+		{
+			name: "has_more pages across several requests",
+			pages: []any{
+				page("c1", 1, "m1"),
+				page("c2", 1, "m2", "m3"),
+				page("c3", 0, "m4"),
+			},
+			wantHandled:   []string{"m1", "m2", "m3", "m4"},
+			wantSaved:     []string{"c1", "c2", "c3"},
+			wantRequested: []string{"", "c1", "c2"},
+			wantCursor:    "c3",
+		},
+		{
+			name: "handler error stops without advancing cursor",
+			pages: []any{
+				page("c1", 1, "m1"),
+				page("c2", 0, "m2", "m3"),
+			},
+			failOn:        "m2",
+			wantHandled:   []string{"m1"},
+			wantSaved:     []string{"c1"},
+			wantRequested: []string{"", "c1"},
+			wantCursor:    "c1",
+			wantErr:       errHandler.Error(),
+		},
+		{
+			name: "has_more with unchanged cursor",
+			pages: []any{
+				page("c1", 1, "m1"),
+				page("c1", 1),
+			},
+			wantHandled:   []string{"m1"},
+			wantSaved:     []string{"c1"},
+			wantRequested: []string{"", "c1"},
+			wantCursor:    "c1",
+			wantErr:       "without advancing cursor",
+		},
+		{
+			name:          "no messages",
+			initialCursor: "c0",
+			pages:         []any{page("", 0)},
+			wantRequested: []string{"c0"},
+			wantCursor:    "c0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := wecomtest.NewServer(t)
+			srv.StubSequence("/cgi-bin/kf/sync_msg", tt.pages...)
+			client, err := wecom.New(srv.Options()...)
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			store := &recordingStore{MemoryCursorStore: kfsvc.NewMemoryCursorStore()}
+			if tt.initialCursor != "" {
+				require.NoError(t, store.MemoryCursorStore.SetCursor(ctx, openKfID, tt.initialCursor))
+			}
+			syncer := client.KF.NewSyncer(store, kfsvc.WithSyncLimit(100))
+
+			var handled []string
+			n, err := syncer.Sync(ctx, "callback-token", openKfID, func(ctx context.Context, msg *kf.SyncMessage) error {
+				if msg.MsgID == tt.failOn {
+					return errHandler
+				}
+				handled = append(handled, msg.MsgID)
+				return nil
+			})
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, len(tt.wantHandled), n)
+			assert.Equal(t, tt.wantHandled, handled)
+			assert.Equal(t, tt.wantSaved, store.saved)
+
+			cursor, err := store.GetCursor(ctx, openKfID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCursor, cursor)
+
+			reqs := srv.RequestsTo("/cgi-bin/kf/sync_msg")
+			require.Len(t, reqs, len(tt.wantRequested))
+			for i, req := range reqs {
+				var body kf.SyncMsgRequest
+				require.NoError(t, req.Decode(&body))
+				assert.Equal(t, tt.wantRequested[i], body.Cursor)
+				assert.Equal(t, "callback-token", body.Token)
+				assert.Equal(t, openKfID, body.OpenKfID)
+				assert.Equal(t, 100, body.Limit)
+			}
+		})
+	}
+}
+
+func TestSyncer_SyncSerialized(t *testing.T) {
+	const openKfID = "wkAJ2GCAAAZSfhHCt7IFSvLKtMPxyJTw"
+	srv := wecomtest.NewServer(t)
+
+	// 统计同时处理的请求数
+	var active, maxActive atomic.Int32
+	srv.Handle("/cgi-bin/kf/sync_msg", func(w http.ResponseWriter, r *http.Request) {
+		n := active.Add(1)
+		defer active.Add(-1)
+		for {
+			m := maxActive.Load()
+			if n <= m || maxActive.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":0,"next_cursor":"c","has_more":0,"msg_list":[{"msgid":"m"}]}`))
+	})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	syncer := client.KF.NewSyncer(nil)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := syncer.Sync(context.Background(), "", openKfID, func(ctx context.Context, msg *kf.SyncMessage) error {
+				time.Sleep(10 * time.Millisecond)
+				return nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	// 后一次拉取在前一次保存游标后才开始，全部完成后不再保留拉取锁
+	assert.Equal(t, int32(1), maxActive.Load())
+	assert.Zero(t, syncer.LockCount())
+	reqs := srv.RequestsTo("/cgi-bin/kf/sync_msg")
+	require.Len(t, reqs, 2)
+	cursors := make([]string, 0, len(reqs))
+	for _, req := range reqs {
+		var body kf.SyncMsgRequest
+		require.NoError(t, req.Decode(&body))
+		cursors = append(cursors, body.Cursor)
+	}
+	assert.Equal(t, []string{"", "c"}, cursors)
+}
@@ -0,0 +1,217 @@
+package media
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/shuaidd/wecom-core/internal/auth"
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/pkg/cache"
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	"github.com/shuaidd/wecom-core/types/media"
+)
+
+// ErrMediaTooLarge 文件超过直接上传的大小限制，且无法通过异步上传
+var ErrMediaTooLarge = errors.New("media: file exceeds the upload limit")
+
+const (
+	// DefaultMediaIDTTL media_id 默认缓存时长，略短于临时素材3天的有效期
+	DefaultMediaIDTTL = 3*24*time.Hour - time.Hour
+	// DefaultUploadPollInterval 异步上传任务的默认查询间隔
+	DefaultUploadPollInterval = 2 * time.Second
+)
+
+// URLResolver 返回企业微信可以下载文件的地址，用于超过直接上传限制的文件
+// 地址需要支持 Range 分块下载，md5 为文件内容的 md5（十六进制）
+type URLResolver func(ctx context.Context, src upload.Source, md5 string) (string, error)
+
+// MediaCacheOption 素材缓存选项
+type MediaCacheOption func(*MediaCache)
+
+// WithMediaIDTTL 设置 media_id 缓存时长，默认 DefaultMediaIDTTL，不应超过3天
+func WithMediaIDTTL(ttl time.Duration) MediaCacheOption {
+	return func(m *MediaCache) {
+		if ttl > 0 {
+			m.ttl = ttl
+		}
+	}
+}
+
+// WithMediaKeyPrefix 设置缓存key前缀，多个企业共享同一缓存时用于区分企业
+func WithMediaKeyPrefix(prefix string) MediaCacheOption {
+	return func(m *MediaCache) {
+		m.prefix = prefix
+	}
+}
+
+// WithLargeFileURL 设置异步上传的场景与大文件的下载地址解析函数
+// 视频、普通文件超过直接上传限制（200MB以内）时，通过异步上传接口由企业微信从该地址下载
+//
+// 异步上传得到的 media_id 只能用于 scene 对应的场景，目前仅支持 media.UploadSceneWelcomeMsg（客户联系入群欢迎语素材），
+// 不能用于发送应用消息，因此设置了该选项的 MediaCache 不应通过 Message.SetMediaUploader 用于 SendFile 等方法
+func WithLargeFileURL(scene int, resolve URLResolver) MediaCacheOption {
+	return func(m *MediaCache) {
+		m.scene = scene
+		m.resolveURL = resolve
+	}
+}
+
+// WithUploadPollInterval 设置异步上传任务的查询间隔，默认 DefaultUploadPollInterval
+func WithUploadPollInterval(interval time.Duration) MediaCacheOption {
+	return func(m *MediaCache) {
+		if interval > 0 {
+			m.pollInterval = interval
+		}
+	}
+}
+
+// MediaCache 按文件内容缓存临时素材的 media_id
+// 缓存key由文件内容的 sha256、素材类型与应用（WithAgentName/WithAgentID 指定）组成，
+// 同一文件在有效期内重复发送时不会重新上传
+type MediaCache struct {
+	svc          *Service
+	store        cache.Cache
+	prefix       string
+	ttl          time.Duration
+	scene        int
+	resolveURL   URLResolver
+	pollInterval time.Duration
+}
+
+// NewMediaCache 创建素材缓存，store 可复用 Token 缓存使用的 Redis 等共享存储，为 nil 时使用内存缓存
+func NewMediaCache(svc *Service, store cache.Cache, opts ...MediaCacheOption) *MediaCache {
+	if store == nil {
+		store = auth.NewMemoryCache()
+	}
+	m := &MediaCache{
+		svc:          svc,
+		store:        store,
+		prefix:       "wecom:media_id:",
+		ttl:          DefaultMediaIDTTL,
+		pollInterval: DefaultUploadPollInterval,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Upload 返回数据源对应的 media_id，缓存未命中时上传
+// 计算缓存key需要读取一遍文件内容（同时计算 sha256 与 md5），超过上传限制的文件在读取前返回 ErrMediaTooLarge
+func (m *MediaCache) Upload(ctx context.Context, mediaType media.MediaType, src upload.Source) (string, error) {
+	byURL, err := m.checkSize(mediaType, src)
+	if err != nil {
+		return "", err
+	}
+
+	sum, md5sum, err := digest(src)
+	if err != nil {
+		return "", err
+	}
+
+	key := m.cacheKey(client.AgentKeyFromContext(ctx), mediaType, sum)
+	if mediaID, expireAt, err := m.store.Get(ctx, key); err == nil && mediaID != "" && time.Now().Before(expireAt) {
+		return mediaID, nil
+	}
+
+	var mediaID string
+	if byURL {
+		mediaID, err = m.uploadByURL(ctx, mediaType, src, md5sum)
+	} else {
+		var resp *media.UploadMediaResponse
+		resp, err = m.svc.UploadMediaFromSource(ctx, mediaType, src)
+		if resp != nil {
+			mediaID = resp.MediaID
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// 缓存写入失败不影响本次上传结果
+	_ = m.store.Set(ctx, key, mediaID, time.Now().Add(m.ttl))
+	return mediaID, nil
+}
+
+// UploadFile 返回本地文件对应的 media_id，缓存未命中时上传
+func (m *MediaCache) UploadFile(ctx context.Context, mediaType media.MediaType, path string) (string, error) {
+	src, err := upload.File(path)
+	if err != nil {
+		return "", err
+	}
+	return m.Upload(ctx, mediaType, src)
+}
+
+// Forget 删除数据源对应的缓存，用于 media_id 提前失效的情况
+func (m *MediaCache) Forget(ctx context.Context, mediaType media.MediaType, src upload.Source) error {
+	sum, _, err := digest(src)
+	if err != nil {
+		return err
+	}
+	return m.store.Delete(ctx, m.cacheKey(client.AgentKeyFromContext(ctx), mediaType, sum))
+}
+
+// checkSize 检查数据源大小，返回是否需要通过异步上传接口上传
+// 只有设置了 WithLargeFileURL 时，视频与普通文件才可以超过直接上传的限制
+func (m *MediaCache) checkSize(mediaType media.MediaType, src upload.Source) (bool, error) {
+	limit := media.MaxUploadBytes(mediaType)
+	if limit <= 0 || src.Size() <= limit {
+		return false, nil
+	}
+	if m.resolveURL == nil || (mediaType != media.MediaTypeVideo && mediaType != media.MediaTypeFile) {
+		return false, fmt.Errorf("%w: %s size %d exceeds %d bytes", ErrMediaTooLarge, mediaType, src.Size(), limit)
+	}
+	if src.Size() > media.MaxUploadByURLBytes {
+		return false, fmt.Errorf("%w: %s size %d exceeds %d bytes", ErrMediaTooLarge, mediaType, src.Size(), media.MaxUploadByURLBytes)
+	}
+	return true, nil
+}
+
+// uploadByURL 通过异步上传接口上传大文件并等待任务完成，得到的 media_id 仅可用于 WithLargeFileURL 指定的场景
+func (m *MediaCache) uploadByURL(ctx context.Context, mediaType media.MediaType, src upload.Source, md5sum string) (string, error) {
+	fileURL, err := m.resolveURL(ctx, src, md5sum)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve file url: %w", err)
+	}
+	job, err := m.svc.UploadByURL(ctx, &media.UploadByURLRequest{
+		Scene:    m.scene,
+		Type:     string(mediaType),
+		Filename: src.Name(),
+		URL:      fileURL,
+		MD5:      md5sum,
+	})
+	if err != nil {
+		return "", err
+	}
+	detail, err := m.svc.WaitUploadByURL(ctx, job.JobID, m.pollInterval)
+	if err != nil {
+		return "", err
+	}
+	return detail.MediaID, nil
+}
+
+// cacheKey 获取 media_id 缓存key
+func (m *MediaCache) cacheKey(agentKey string, mediaType media.MediaType, sum string) string {
+	return fmt.Sprintf("%s%s:%s:%s", m.prefix, agentKey, mediaType, sum)
+}
+
+// digest 读取数据源，计算内容的 sha256 与 md5
+func digest(src upload.Source) (sha256sum, md5sum string, err error) {
+	rc, err := src.Open()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to open upload source: %w", err)
+	}
+	defer rc.Close()
+
+	s, h := sha256.New(), md5.New()
+	if _, err := io.Copy(io.MultiWriter(s, h), rc); err != nil {
+		return "", "", fmt.Errorf("failed to read upload source: %w", err)
+	}
+	return hex.EncodeToString(s.Sum(nil)), hex.EncodeToString(h.Sum(nil)), nil
+}
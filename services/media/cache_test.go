@@ -0,0 +1,218 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/internal/auth"
+	"github.com/shuaidd/wecom-core/pkg/cache"
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	mediasvc "github.com/shuaidd/wecom-core/services/media"
+	"github.com/shuaidd/wecom-core/types/media"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+// newMediaCache 创建使用模拟服务的素材缓存
+func newMediaCache(t *testing.T, opts ...mediasvc.MediaCacheOption) (*wecomtest.Server, cache.Cache, *mediasvc.MediaCache) {
+	t.Helper()
+	srv := wecomtest.NewServer(t)
+	srv.Agent("notify", 1000002)
+	srv.Agent("report", 1000003)
+	srv.Stub("/cgi-bin/media/upload", map[string]any{"errcode": 0, "type": "file", "media_id": "media-1"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	store := auth.NewMemoryCache()
+	return srv, store, mediasvc.NewMediaCache(client.Media, store, opts...)
+}
+
+// sha256Hex 返回内容的 sha256（十六进制）
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// sizedSource 返回声明大小的数据源，内容保持很小以避免测试分配大文件
+func sizedSource(name string, size int64, data []byte) upload.Source {
+	return upload.Func(name, size, func() (io.Reader, error) {
+		return bytes.NewReader(data), nil
+	})
+}
+
+func TestMediaCache_CacheKey(t *testing.T) {
+	data := []byte("%PDF-1.4 report")
+	srv, store, mc := newMediaCache(t, mediasvc.WithMediaKeyPrefix("app:media:wwcorp:"))
+	ctx := wecom.WithAgentName(context.Background(), "notify")
+
+	mediaID, err := mc.Upload(ctx, media.MediaTypeFile, upload.Bytes("report.pdf", data))
+	require.NoError(t, err)
+	assert.Equal(t, "media-1", mediaID)
+
+	// 缓存key由前缀、应用、素材类型与内容 sha256 组成
+	cached, _, err := store.Get(ctx, "app:media:wwcorp:notify:file:"+sha256Hex(data))
+	require.NoError(t, err)
+	assert.Equal(t, "media-1", cached)
+
+	// 文件名不同但内容相同时命中缓存
+	_, err = mc.Upload(ctx, media.MediaTypeFile, upload.Bytes("copy.pdf", data))
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 1)
+
+	// 素材类型、应用或内容不同时分别上传
+	_, err = mc.Upload(ctx, media.MediaTypeImage, upload.Bytes("report.pdf", data))
+	require.NoError(t, err)
+	_, err = mc.Upload(wecom.WithAgentName(context.Background(), "report"), media.MediaTypeFile, upload.Bytes("report.pdf", data))
+	require.NoError(t, err)
+	_, err = mc.Upload(ctx, media.MediaTypeFile, upload.Bytes("report.pdf", []byte("%PDF-1.4 report v2")))
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 4)
+
+	_, _, err = store.Get(ctx, "app:media:wwcorp:report:file:"+sha256Hex(data))
+	assert.NoError(t, err)
+}
+
+func TestMediaCache_TTL(t *testing.T) {
+	data := []byte("%PDF-1.4 report")
+	key := "wecom:media_id:notify:file:" + sha256Hex(data)
+	ctx := wecom.WithAgentName(context.Background(), "notify")
+
+	t.Run("default", func(t *testing.T) {
+		_, store, mc := newMediaCache(t)
+		before := time.Now()
+		_, err := mc.Upload(ctx, media.MediaTypeFile, upload.Bytes("report.pdf", data))
+		require.NoError(t, err)
+
+		_, expireAt, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.WithinDuration(t, before.Add(mediasvc.DefaultMediaIDTTL), expireAt, time.Second)
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		_, store, mc := newMediaCache(t, mediasvc.WithMediaIDTTL(time.Hour))
+		before := time.Now()
+		_, err := mc.Upload(ctx, media.MediaTypeFile, upload.Bytes("report.pdf", data))
+		require.NoError(t, err)
+
+		_, expireAt, err := store.Get(ctx, key)
+		require.NoError(t, err)
+		assert.WithinDuration(t, before.Add(time.Hour), expireAt, time.Second)
+	})
+
+	t.Run("expired entry is uploaded again", func(t *testing.T) {
+		srv, store, mc := newMediaCache(t)
+		require.NoError(t, store.Set(ctx, key, "media-expired", time.Now().Add(-time.Second)))
+
+		mediaID, err := mc.Upload(ctx, media.MediaTypeFile, upload.Bytes("report.pdf", data))
+		require.NoError(t, err)
+		assert.Equal(t, "media-1", mediaID)
+		srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 1)
+	})
+}
+
+func TestMediaCache_Forget(t *testing.T) {
+	data := []byte("%PDF-1.4 report")
+	srv, store, mc := newMediaCache(t)
+	ctx := wecom.WithAgentName(context.Background(), "notify")
+	src := upload.Bytes("report.pdf", data)
+
+	_, err := mc.Upload(ctx, media.MediaTypeFile, src)
+	require.NoError(t, err)
+
+	// 只删除当前应用与素材类型的缓存
+	require.NoError(t, mc.Forget(ctx, media.MediaTypeImage, src))
+	_, _, err = store.Get(ctx, "wecom:media_id:notify:file:"+sha256Hex(data))
+	require.NoError(t, err)
+
+	require.NoError(t, mc.Forget(ctx, media.MediaTypeFile, src))
+	_, _, err = store.Get(ctx, "wecom:media_id:notify:file:"+sha256Hex(data))
+	assert.Error(t, err)
+
+	_, err = mc.Upload(ctx, media.MediaTypeFile, src)
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 2)
+}
+
+func TestMediaCache_SizeRouting(t *testing.T) {
+	data := []byte("large file content")
+	md5sum := md5.Sum(data)
+
+	tests := []struct {
+		name      string
+		mediaType media.MediaType
+		size      int64
+		largeURL  bool
+		wantByURL bool
+		wantErr   error
+	}{
+		{name: "file within limit", mediaType: media.MediaTypeFile, size: int64(len(data)), largeURL: true},
+		{name: "file over limit without url", mediaType: media.MediaTypeFile, size: media.MaxFileBytes + 1, wantErr: mediasvc.ErrMediaTooLarge},
+		{name: "file over limit by url", mediaType: media.MediaTypeFile, size: media.MaxFileBytes + 1, largeURL: true, wantByURL: true},
+		{name: "video over limit by url", mediaType: media.MediaTypeVideo, size: media.MaxVideoBytes + 1, largeURL: true, wantByURL: true},
+		{name: "video over url limit", mediaType: media.MediaTypeVideo, size: media.MaxUploadByURLBytes + 1, largeURL: true, wantErr: mediasvc.ErrMediaTooLarge},
+		{name: "image over limit", mediaType: media.MediaTypeImage, size: media.MaxImageBytes + 1, largeURL: true, wantErr: mediasvc.ErrMediaTooLarge},
+		{name: "voice over limit", mediaType: media.MediaTypeVoice, size: media.MaxVoiceBytes + 1, largeURL: true, wantErr: mediasvc.ErrMediaTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resolved string
+			opts := []mediasvc.MediaCacheOption{mediasvc.WithUploadPollInterval(time.Millisecond)}
+			if tt.largeURL {
+				opts = append(opts, mediasvc.WithLargeFileURL(media.UploadSceneWelcomeMsg, func(ctx context.Context, src upload.Source, md5 string) (string, error) {
+					resolved = md5
+					return "https://cdn.example.com/" + src.Name(), nil
+				}))
+			}
+			srv, _, mc := newMediaCache(t, opts...)
+			srv.Stub("/cgi-bin/media/upload_by_url", map[string]any{"errcode": 0, "jobid": "job-1"})
+			srv.StubSequence("/cgi-bin/media/get_upload_by_url_result",
+				map[string]any{"errcode": 0, "status": 1},
+				map[string]any{"errcode": 0, "status": 2, "detail": map[string]any{"media_id": "media-big"}},
+			)
+			ctx := wecom.WithAgentName(context.Background(), "notify")
+			src := sizedSource("big.bin", tt.size, data)
+
+			mediaID, err := mc.Upload(ctx, tt.mediaType, src)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, srv.Requests())
+				return
+			}
+			require.NoError(t, err)
+
+			if !tt.wantByURL {
+				assert.Equal(t, "media-1", mediaID)
+				srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 1)
+				srv.AssertNotCalled(t, "/cgi-bin/media/upload_by_url")
+				return
+			}
+			assert.Equal(t, "media-big", mediaID)
+			assert.Equal(t, hex.EncodeToString(md5sum[:]), resolved)
+			srv.AssertNotCalled(t, "/cgi-bin/media/upload")
+			srv.AssertCalledTimes(t, "/cgi-bin/media/get_upload_by_url_result", 2)
+			srv.AssertBody(t, "/cgi-bin/media/upload_by_url", fmt.Sprintf(`{
+				"scene": 1,
+				"type": %q,
+				"filename": "big.bin",
+				"url": "https://cdn.example.com/big.bin",
+				"md5": %q
+			}`, tt.mediaType, resolved))
+
+			// 再次上传命中缓存
+			_, err = mc.Upload(ctx, tt.mediaType, src)
+			require.NoError(t, err)
+			srv.AssertCalledTimes(t, "/cgi-bin/media/upload_by_url", 1)
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package media
+
+import (
+	"context"
+	"io"
+	"net/url"
+
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/types/common"
+)
+
+// DownloadMedia 流式下载临时素材并写入 w
+// 大文件自动拆分为多个 Range 请求，网络错误时从已写入的位置继续下载，返回文件名与大小
+// 文档: https://developer.work.weixin.qq.com/document/path/90254
+//
+// 示例：
+//
+//	f, _ := os.Create("video.mp4")
+//	defer f.Close()
+//	info, err := client.Media.DownloadMedia(ctx, mediaID, f)
+func (s *Service) DownloadMedia(ctx context.Context, mediaID string, w io.Writer) (*common.MediaInfo, error) {
+	return s.client.DownloadMedia(ctx, mediaRequest("/cgi-bin/media/get", mediaID), w)
+}
+
+// OpenMedia 以 io.ReadCloser 的形式流式读取临时素材，读取完毕或提前放弃时需调用 Close
+// 分块与断点续传同 DownloadMedia
+func (s *Service) OpenMedia(ctx context.Context, mediaID string) (*common.MediaInfo, io.ReadCloser, error) {
+	return s.client.OpenMedia(ctx, mediaRequest("/cgi-bin/media/get", mediaID))
+}
+
+// DownloadJSSDKMedia 流式下载高清语音素材并写入 w
+// 文档: https://developer.work.weixin.qq.com/document/path/90255
+func (s *Service) DownloadJSSDKMedia(ctx context.Context, mediaID string, w io.Writer) (*common.MediaInfo, error) {
+	return s.client.DownloadMedia(ctx, mediaRequest("/cgi-bin/media/get/jssdk", mediaID), w)
+}
+
+// mediaRequest 创建按 media_id 下载的请求
+func mediaRequest(path, mediaID string) *client.MediaRequest {
+	query := url.Values{}
+	query.Set("media_id", mediaID)
+	return &client.MediaRequest{Path: path, Query: query}
+}
@@ -0,0 +1,106 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestDownloadMedia(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	data := make([]byte, 9<<20)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	srv.StubMedia("/cgi-bin/media/get", "视频.mp4", data)
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 按 4MB 分块下载
+	var buf bytes.Buffer
+	info, err := client.Media.DownloadMedia(ctx, "media-1", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "视频.mp4", info.Filename)
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.True(t, bytes.Equal(data, buf.Bytes()))
+
+	reqs := srv.RequestsTo("/cgi-bin/media/get")
+	require.Len(t, reqs, 3)
+	assert.Equal(t, "bytes=0-4194303", reqs[0].Header.Get("Range"))
+	assert.Equal(t, "bytes=4194304-8388607", reqs[1].Header.Get("Range"))
+	assert.Equal(t, "bytes=8388608-9437183", reqs[2].Header.Get("Range"))
+	assert.Equal(t, "media-1", reqs[0].Query.Get("media_id"))
+
+	// 以 io.ReadCloser 读取
+	info, rc, err := client.Media.OpenMedia(ctx, "media-1")
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.True(t, bytes.Equal(data, got))
+}
+
+func TestDownloadMedia_Resume(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	data := bytes.Repeat([]byte("0123456789"), 1<<19)
+	var calls atomic.Int32
+	srv.Handle("/cgi-bin/media/get", func(w http.ResponseWriter, r *http.Request) {
+		// 第二个分块传输到一半时断开连接
+		if calls.Add(1) == 2 {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 4194304-%d/%d", len(data)-1, len(data)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)-4194304))
+			w.WriteHeader(http.StatusPartialContent)
+			_, _ = w.Write(data[4194304 : 4194304+1000])
+			w.(http.Flusher).Flush()
+			panic(http.ErrAbortHandler)
+		}
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	info, err := client.Media.DownloadMedia(context.Background(), "media-1", &buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(data)), info.Size)
+	assert.True(t, bytes.Equal(data, buf.Bytes()))
+
+	// 从断开前已写入的位置继续
+	reqs := srv.RequestsTo("/cgi-bin/media/get")
+	require.Len(t, reqs, 3)
+	assert.Equal(t, "bytes=4195304-5242879", reqs[2].Header.Get("Range"))
+}
+
+func TestDownloadMedia_Error(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubError("/cgi-bin/media/get/jssdk", 40007, "invalid media_id")
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 企业微信以 HTTP 200 返回 JSON 错误
+	var buf bytes.Buffer
+	_, err = client.Media.DownloadJSSDKMedia(ctx, "media-1", &buf)
+	assert.ErrorContains(t, err, "40007")
+	assert.Zero(t, buf.Len())
+
+	_, err = client.Media.GetJSSDKMedia(ctx, "media-1")
+	assert.ErrorContains(t, err, "40007")
+}
@@ -1,16 +1,15 @@
 package media
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
-	"mime/multipart"
 	"net/url"
-	"os"
-	"path/filepath"
+	"time"
 
 	"github.com/shuaidd/wecom-core/internal/client"
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+	"github.com/shuaidd/wecom-core/pkg/upload"
 	"github.com/shuaidd/wecom-core/types/media"
 )
 
@@ -18,36 +17,27 @@ import (
 // 上传图片得到图片URL，该URL永久有效
 // 文档: https://developer.work.weixin.qq.com/document/path/90256
 func (s *Service) UploadImage(ctx context.Context, imagePath string) (*media.UploadImageResponse, error) {
-	file, err := os.Open(imagePath)
+	src, err := upload.File(imagePath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open image file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	return s.UploadImageFromReader(ctx, file, filepath.Base(imagePath))
+	return s.UploadImageFromSource(ctx, src)
 }
 
 // UploadImageFromReader 从 io.Reader 上传图片
+// *os.File、*bytes.Reader 等可重复读取的 Reader 直接流式上传，其他 Reader 的内容会先读入内存
 func (s *Service) UploadImageFromReader(ctx context.Context, reader io.Reader, filename string) (*media.UploadImageResponse, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	part, err := writer.CreateFormFile("media", filename)
+	src, err := upload.FromReader(filename, reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
-	}
-
-	if _, err := io.Copy(part, reader); err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
-	}
-
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+		return nil, err
 	}
+	return s.UploadImageFromSource(ctx, src)
+}
 
-	return client.PostMultipartAndUnmarshal[media.UploadImageResponse](
-		s.client, ctx, "/cgi-bin/media/uploadimg", body.Bytes(), contentType,
+// UploadImageFromSource 从数据源流式上传图片，重试时重新打开数据源
+func (s *Service) UploadImageFromSource(ctx context.Context, src upload.Source) (*media.UploadImageResponse, error) {
+	return client.PostUploadAndUnmarshal[media.UploadImageResponse](
+		s.client, ctx, "/cgi-bin/media/uploadimg", nil, "media", src,
 	)
 }
 
@@ -55,39 +45,30 @@ func (s *Service) UploadImageFromReader(ctx context.Context, reader io.Reader, f
 // 素材上传得到media_id，该media_id仅三天内有效
 // 文档: https://developer.work.weixin.qq.com/document/path/90253
 func (s *Service) UploadMedia(ctx context.Context, mediaType media.MediaType, mediaPath string) (*media.UploadMediaResponse, error) {
-	file, err := os.Open(mediaPath)
+	src, err := upload.File(mediaPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open media file: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	return s.UploadMediaFromReader(ctx, mediaType, file, filepath.Base(mediaPath))
+	return s.UploadMediaFromSource(ctx, mediaType, src)
 }
 
 // UploadMediaFromReader 从 io.Reader 上传临时素材
+// *os.File、*bytes.Reader 等可重复读取的 Reader 直接流式上传，其他 Reader 的内容会先读入内存
 func (s *Service) UploadMediaFromReader(ctx context.Context, mediaType media.MediaType, reader io.Reader, filename string) (*media.UploadMediaResponse, error) {
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	part, err := writer.CreateFormFile("media", filename)
+	src, err := upload.FromReader(filename, reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create form file: %w", err)
-	}
-
-	if _, err := io.Copy(part, reader); err != nil {
-		return nil, fmt.Errorf("failed to copy file content: %w", err)
-	}
-
-	contentType := writer.FormDataContentType()
-	if err := writer.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close multipart writer: %w", err)
+		return nil, err
 	}
+	return s.UploadMediaFromSource(ctx, mediaType, src)
+}
 
+// UploadMediaFromSource 从数据源流式上传临时素材，重试时重新打开数据源
+func (s *Service) UploadMediaFromSource(ctx context.Context, mediaType media.MediaType, src upload.Source) (*media.UploadMediaResponse, error) {
 	query := url.Values{}
 	query.Set("type", string(mediaType))
 
-	return client.PostMultipartAndUnmarshalWithQuery[media.UploadMediaResponse](
-		s.client, ctx, "/cgi-bin/media/upload", query, body.Bytes(), contentType,
+	return client.PostUploadAndUnmarshal[media.UploadMediaResponse](
+		s.client, ctx, "/cgi-bin/media/upload", query, "media", src,
 	)
 }
 
@@ -139,3 +120,30 @@ func (s *Service) GetUploadByURLResult(ctx context.Context, jobID string) (*medi
 	}
 	return client.PostAndUnmarshal[media.GetUploadByURLResultResponse](s.client, ctx, "/cgi-bin/media/get_upload_by_url_result", req)
 }
+
+// WaitUploadByURL 轮询异步上传任务直到完成，返回任务结果明细
+// 任务失败时返回包含任务错误码的错误，interval 为查询间隔
+func (s *Service) WaitUploadByURL(ctx context.Context, jobID string, interval time.Duration) (*media.UploadTaskDetail, error) {
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+
+		result, err := s.GetUploadByURLResult(ctx, jobID)
+		if err != nil {
+			return nil, err
+		}
+		switch result.Status {
+		case media.UploadTaskStatusCompleted:
+			return &result.Detail, nil
+		case media.UploadTaskStatusFailed:
+			return nil, fmt.Errorf("upload by url job %s failed: %w", jobID, wecomerrors.New(result.Detail.ErrCode, result.Detail.ErrMsg))
+		}
+		timer.Reset(interval)
+	}
+}
@@ -0,0 +1,58 @@
+package media_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	mediatypes "github.com/shuaidd/wecom-core/types/media"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestUploadMediaFromSource(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubSequence("/cgi-bin/media/upload",
+		map[string]any{"errcode": 45009, "errmsg": "api freq out of limit"},
+		map[string]any{"errcode": 0, "type": "file", "media_id": "media-1"},
+	)
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 重试时重新打开数据源，请求体完整
+	data := bytes.Repeat([]byte("report"), 1<<16)
+	var opens atomic.Int32
+	src := upload.Func("report.txt", int64(len(data)), func() (io.Reader, error) {
+		opens.Add(1)
+		return bytes.NewReader(data), nil
+	})
+	resp, err := client.Media.UploadMediaFromSource(ctx, mediatypes.MediaTypeFile, src)
+	require.NoError(t, err)
+	assert.Equal(t, "media-1", resp.MediaID)
+	assert.Equal(t, int32(2), opens.Load())
+
+	reqs := srv.RequestsTo("/cgi-bin/media/upload")
+	require.Len(t, reqs, 2)
+	for _, req := range reqs {
+		assert.Equal(t, "file", req.Query.Get("type"))
+		_, params, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		require.NoError(t, err)
+		part, err := multipart.NewReader(bytes.NewReader(req.Body), params["boundary"]).NextPart()
+		require.NoError(t, err)
+		assert.Equal(t, "media", part.FormName())
+		assert.Equal(t, "report.txt", part.FileName())
+		got, err := io.ReadAll(part)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(data, got))
+	}
+}
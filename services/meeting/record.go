@@ -2,6 +2,7 @@ package meeting
 
 import (
 	"context"
+	"io"
 
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/types/common"
@@ -77,6 +78,12 @@ func (s *Service) GetRecordFile(ctx context.Context, req *meeting.GetRecordFileR
 	return client.PostAndUnmarshal[meeting.GetRecordFileResponse](s.client, ctx, "/cgi-bin/meeting/record/get_file", req)
 }
 
+// DownloadRecordFile 将录制文件流式写入 w
+// downloadAddress 为 GetFileList 或 GetRecordFile 返回的 download_address，大文件自动拆分为多个 Range 请求并在网络错误时断点续传
+func (s *Service) DownloadRecordFile(ctx context.Context, downloadAddress string, w io.Writer) (*common.MediaInfo, error) {
+	return s.client.DownloadMedia(ctx, &client.MediaRequest{URL: downloadAddress}, w)
+}
+
 // GetStatistics 获取录制文件访问统计
 // 文档: docs/录制管理/获取录制文件访问统计.md
 func (s *Service) GetStatistics(ctx context.Context, req *meeting.GetStatisticsRequest) (*meeting.GetStatisticsResponse, error) {
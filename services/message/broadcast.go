@@ -0,0 +1,329 @@
+package message
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/shuaidd/wecom-core/types/message"
+)
+
+// ErrCheckpointMismatch 保存的发送进度与本次广播的接收者或分批方式不一致
+var ErrCheckpointMismatch = errors.New("message: broadcast checkpoint does not match recipients")
+
+const (
+	// DefaultBroadcastConcurrency 默认同时发送的批次数
+	DefaultBroadcastConcurrency = 4
+	// maxBroadcastParties 每批部门数
+	maxBroadcastParties = message.MaxToParties
+	// maxBroadcastTags 每批标签数
+	maxBroadcastTags = message.MaxToTags
+)
+
+// BroadcastStore 广播进度存储
+// 按广播ID保存已发送的批次，使进程崩溃或重启后可以从断点继续发送，不会重复发送已成功的批次
+type BroadcastStore interface {
+	// LoadBroadcast 获取广播进度，不存在时返回 nil
+	LoadBroadcast(ctx context.Context, id string) (*message.BroadcastResult, error)
+	// SaveBroadcast 保存广播进度，每批发送完成后调用
+	SaveBroadcast(ctx context.Context, id string, result *message.BroadcastResult) error
+}
+
+// MemoryBroadcastStore 内存广播进度存储（仅适用于单实例，重启后丢失）
+type MemoryBroadcastStore struct {
+	mu      sync.RWMutex
+	results map[string]*message.BroadcastResult
+}
+
+// NewMemoryBroadcastStore 创建内存广播进度存储
+func NewMemoryBroadcastStore() *MemoryBroadcastStore {
+	return &MemoryBroadcastStore{
+		results: make(map[string]*message.BroadcastResult),
+	}
+}
+
+// LoadBroadcast 获取广播进度
+func (s *MemoryBroadcastStore) LoadBroadcast(ctx context.Context, id string) (*message.BroadcastResult, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if r, ok := s.results[id]; ok {
+		return cloneResult(r), nil
+	}
+	return nil, nil
+}
+
+// SaveBroadcast 保存广播进度
+func (s *MemoryBroadcastStore) SaveBroadcast(ctx context.Context, id string, result *message.BroadcastResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.results[id] = cloneResult(result)
+	return nil
+}
+
+// BroadcastOption 大批量发送选项
+type BroadcastOption func(*broadcastOptions)
+
+// broadcastOptions 大批量发送配置
+type broadcastOptions struct {
+	concurrency int
+	chunkSize   int
+	store       BroadcastStore
+}
+
+// WithBroadcastConcurrency 设置同时发送的批次数，默认 4
+// 请求仍受客户端限流器约束
+func WithBroadcastConcurrency(n int) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.concurrency = n
+	}
+}
+
+// WithChunkSize 设置每批成员数，默认且最大为 1000
+func WithChunkSize(n int) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.chunkSize = n
+	}
+}
+
+// WithBroadcastStore 设置广播进度存储，配合 BroadcastRequest.ID 实现断点续发
+func WithBroadcastStore(store BroadcastStore) BroadcastOption {
+	return func(o *broadcastOptions) {
+		o.store = store
+	}
+}
+
+// Broadcast 向大量接收者发送应用消息
+// 接收者去重后按接口限制分批，以有限并发调用 Send；某一批失败不影响其他批次，
+// 返回的结果汇总所有成功批次的 msgid 与不合法、无许可的接收者，失败的批次记录在 Failed 中并通过 error 返回。
+// 设置了 BroadcastRequest.ID 与进度存储时，每批完成后保存进度，再次调用会跳过已成功的批次
+//
+// 示例：
+//
+//	result, err := client.Message.Broadcast(ctx, &message.BroadcastRequest{
+//	    ID: "upgrade-notice-20240101",
+//	    Message: &message.SendMessageRequest{
+//	        MsgType: message.MessageTypeText,
+//	        AgentID: 1000002,
+//	        Text:    &message.TextMessage{Content: "系统将于今晚 22:00 升级"},
+//	    },
+//	    UserIDs: userIDs,
+//	}, messagesvc.WithBroadcastStore(store))
+//	log.Println(result.InvalidUsers(), result.UnlicensedUsers())
+//
+//	// 撤回全部消息
+//	err = client.Message.RecallBroadcast(ctx, result)
+func (s *Service) Broadcast(ctx context.Context, req *message.BroadcastRequest, opts ...BroadcastOption) (*message.BroadcastResult, error) {
+	o := broadcastOptions{concurrency: DefaultBroadcastConcurrency, chunkSize: message.MaxToUsers}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.chunkSize <= 0 || o.chunkSize > message.MaxToUsers {
+		o.chunkSize = message.MaxToUsers
+	}
+	if o.concurrency <= 0 {
+		o.concurrency = 1
+	}
+
+	chunks, err := splitBroadcast(req, o.chunkSize)
+	if err != nil {
+		return nil, err
+	}
+	fingerprint := broadcastFingerprint(req, o.chunkSize)
+
+	// 恢复进度
+	result := &message.BroadcastResult{ID: req.ID, Fingerprint: fingerprint, Total: len(chunks)}
+	store := o.store
+	if req.ID == "" {
+		store = nil
+	}
+	if store != nil {
+		saved, err := store.LoadBroadcast(ctx, req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load broadcast checkpoint: %w", err)
+		}
+		if saved != nil {
+			if saved.Fingerprint != fingerprint || saved.Total != len(chunks) {
+				return nil, ErrCheckpointMismatch
+			}
+			result.Chunks = saved.Chunks
+		}
+	}
+	done := make(map[int]bool, len(result.Chunks))
+	for _, c := range result.Chunks {
+		done[c.Index] = true
+	}
+	result.Failed = nil
+
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		errs []error
+		sem  = make(chan struct{}, o.concurrency)
+	)
+	fail := func(index int, err error) {
+		result.Failed = append(result.Failed, index)
+		errs = append(errs, fmt.Errorf("broadcast chunk %d: %w", index, err))
+	}
+
+	for i, chunk := range chunks {
+		if done[i] {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			// 未发送的批次视为失败，下次调用时重新发送
+			mu.Lock()
+			fail(i, ctx.Err())
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		go func(index int, chunk *message.SendMessageRequest) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			resp, err := s.Send(ctx, chunk)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fail(index, err)
+				return
+			}
+			result.Chunks = append(result.Chunks, message.BroadcastChunk{
+				Index:          index,
+				MsgID:          resp.MsgID,
+				ResponseCode:   resp.ResponseCode,
+				InvalidUser:    resp.InvalidUser,
+				InvalidParty:   resp.InvalidParty,
+				InvalidTag:     resp.InvalidTag,
+				UnlicensedUser: resp.UnlicensedUser,
+			})
+			slices.SortFunc(result.Chunks, compareChunks)
+			if store != nil {
+				if err := store.SaveBroadcast(ctx, req.ID, result); err != nil {
+					errs = append(errs, fmt.Errorf("failed to save broadcast checkpoint: %w", err))
+				}
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	slices.Sort(result.Failed)
+	return result, errors.Join(errs...)
+}
+
+// RecallBroadcast 撤回广播已发送的全部消息
+// 某条消息撤回失败时继续撤回其余消息，返回所有失败的错误
+func (s *Service) RecallBroadcast(ctx context.Context, result *message.BroadcastResult) error {
+	var errs []error
+	for _, msgID := range result.MsgIDs() {
+		if err := s.Recall(ctx, &message.RecallMessageRequest{MsgID: msgID}); err != nil {
+			errs = append(errs, fmt.Errorf("recall %s: %w", msgID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// splitBroadcast 接收者去重后分批，并校验每批的发送请求
+func splitBroadcast(req *message.BroadcastRequest, chunkSize int) ([]*message.SendMessageRequest, error) {
+	if req == nil || req.Message == nil {
+		return nil, fmt.Errorf("%w: broadcast message is required", message.ErrInvalidMessage)
+	}
+	if req.Message.ToUser != "" || req.Message.ToParty != "" || req.Message.ToTag != "" {
+		return nil, fmt.Errorf("%w: broadcast recipients must be set on the request, not the message", message.ErrInvalidMessage)
+	}
+
+	users := dedup(req.UserIDs)
+	parties := dedup(req.PartyIDs)
+	tags := dedup(req.TagIDs)
+	for _, id := range users {
+		if id == "" || strings.Contains(id, "|") || id == "@all" {
+			return nil, fmt.Errorf("%w: invalid broadcast userid %q", message.ErrInvalidMessage, id)
+		}
+	}
+
+	n := max(chunkCount(len(users), chunkSize), chunkCount(len(parties), maxBroadcastParties), chunkCount(len(tags), maxBroadcastTags))
+	if n == 0 {
+		return nil, fmt.Errorf("%w: broadcast has no recipients", message.ErrInvalidMessage)
+	}
+
+	chunks := make([]*message.SendMessageRequest, n)
+	for i := range chunks {
+		chunk := *req.Message
+		chunk.ToUser = strings.Join(chunkOf(users, i, chunkSize), "|")
+		chunk.ToParty = joinInts(chunkOf(parties, i, maxBroadcastParties))
+		chunk.ToTag = joinInts(chunkOf(tags, i, maxBroadcastTags))
+		if err := chunk.Validate(); err != nil {
+			return nil, err
+		}
+		chunks[i] = &chunk
+	}
+	return chunks, nil
+}
+
+// broadcastFingerprint 计算接收者与分批方式的摘要
+func broadcastFingerprint(req *message.BroadcastRequest, chunkSize int) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%d\n%s\n", chunkSize, strings.Join(dedup(req.UserIDs), "|"))
+	fmt.Fprintf(h, "%s\n%s\n", joinInts(dedup(req.PartyIDs)), joinInts(dedup(req.TagIDs)))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// compareChunks 按批次序号排序
+func compareChunks(a, b message.BroadcastChunk) int {
+	return a.Index - b.Index
+}
+
+// chunkCount 计算分批数
+func chunkCount(n, size int) int {
+	return (n + size - 1) / size
+}
+
+// chunkOf 返回第 i 批，超出范围时返回空
+func chunkOf[T any](ids []T, i, size int) []T {
+	start := i * size
+	if start >= len(ids) {
+		return nil
+	}
+	return ids[start:min(start+size, len(ids))]
+}
+
+// dedup 按出现顺序去重
+func dedup[T comparable](ids []T) []T {
+	seen := make(map[T]struct{}, len(ids))
+	out := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// joinInts 以'|'拼接ID
+func joinInts(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, "|")
+}
+
+// cloneResult 复制广播进度
+func cloneResult(r *message.BroadcastResult) *message.BroadcastResult {
+	c := *r
+	c.Chunks = slices.Clone(r.Chunks)
+	c.Failed = slices.Clone(r.Failed)
+	return &c
+}
@@ -0,0 +1,371 @@
+package message_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	messagesvc "github.com/shuaidd/wecom-core/services/message"
+	"github.com/shuaidd/wecom-core/types/message"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestBroadcast(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	var failed atomic.Bool
+	srv.Handle("/cgi-bin/message/send", func(w http.ResponseWriter, r *http.Request) {
+		var req message.SendMessageRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		users := strings.Split(req.ToUser, "|")
+		w.Header().Set("Content-Type", "application/json")
+		// 第三批第一次发送失败
+		if users[0] == "user2000" && failed.CompareAndSwap(false, true) {
+			_, _ = w.Write([]byte(`{"errcode":40003,"errmsg":"invalid parameter"}`))
+			return
+		}
+		resp := map[string]any{"errcode": 0, "msgid": "msg-" + users[0]}
+		if slices.Contains(users, "ghost") {
+			resp["invaliduser"] = "ghost"
+		}
+		if req.ToParty != "" {
+			resp["unlicenseduser"] = "nolicense"
+		}
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+	srv.Stub("/cgi-bin/message/recall", map[string]any{"errcode": 0})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	users := make([]string, 0, 2501)
+	for i := range 2500 {
+		users = append(users, fmt.Sprintf("user%d", i))
+	}
+	users = append(users, "user0", "ghost")
+	req := &message.BroadcastRequest{
+		ID: "notice-1",
+		Message: &message.SendMessageRequest{
+			MsgType: message.MessageTypeText,
+			AgentID: 1000002,
+			Text:    &message.TextMessage{Content: "hello"},
+		},
+		UserIDs:  users,
+		PartyIDs: []int{2},
+	}
+	store := messagesvc.NewMemoryBroadcastStore()
+
+	// 一批失败不影响其他批次
+	result, err := client.Message.Broadcast(ctx, req, messagesvc.WithBroadcastStore(store), messagesvc.WithBroadcastConcurrency(2))
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "broadcast chunk 2")
+	assert.False(t, result.Done())
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, []int{2}, result.Failed)
+	assert.Equal(t, []string{"msg-user0", "msg-user1000"}, result.MsgIDs())
+	assert.Equal(t, []string{"nolicense"}, result.UnlicensedUsers())
+	srv.AssertCalledTimes(t, "/cgi-bin/message/send", 3)
+
+	// 从断点继续，只发送失败的批次
+	result, err = client.Message.Broadcast(ctx, req, messagesvc.WithBroadcastStore(store))
+	require.NoError(t, err)
+	assert.True(t, result.Done())
+	assert.Empty(t, result.Failed)
+	assert.Equal(t, []string{"msg-user0", "msg-user1000", "msg-user2000"}, result.MsgIDs())
+	assert.Equal(t, []string{"ghost"}, result.InvalidUsers())
+	srv.AssertCalledTimes(t, "/cgi-bin/message/send", 4)
+
+	// 接收者变化时拒绝恢复
+	changed := *req
+	changed.UserIDs = users[:10]
+	_, err = client.Message.Broadcast(ctx, &changed, messagesvc.WithBroadcastStore(store))
+	assert.ErrorIs(t, err, messagesvc.ErrCheckpointMismatch)
+
+	// 本地校验失败时不发送
+	invalid := *req
+	invalid.ID = ""
+	invalid.Message = &message.SendMessageRequest{MsgType: message.MessageTypeText, AgentID: 1000002, Text: &message.TextMessage{}}
+	_, err = client.Message.Broadcast(ctx, &invalid)
+	assert.ErrorIs(t, err, message.ErrInvalidMessage)
+	srv.AssertCalledTimes(t, "/cgi-bin/message/send", 4)
+
+	require.NoError(t, client.Message.RecallBroadcast(ctx, result))
+	srv.AssertCalledTimes(t, "/cgi-bin/message/recall", 3)
+}
+
+// textBroadcast 构造文本消息广播请求
+func textBroadcast(users []string, parties, tags []int) *message.BroadcastRequest {
+	return &message.BroadcastRequest{
+		ID: "notice-1",
+		Message: &message.SendMessageRequest{
+			MsgType: message.MessageTypeText,
+			AgentID: 1000002,
+			Text:    &message.TextMessage{Content: "hello"},
+		},
+		UserIDs:  users,
+		PartyIDs: parties,
+		TagIDs:   tags,
+	}
+}
+
+// seq 返回 1 到 n 的整数
+func seq(n int) []int {
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = i + 1
+	}
+	return ids
+}
+
+// userIDs 返回 n 个成员ID
+func userIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("user%d", i)
+	}
+	return ids
+}
+
+func TestSplitBroadcast(t *testing.T) {
+	tests := []struct {
+		name      string
+		req       *message.BroadcastRequest
+		chunkSize int
+		// wantUsers/wantParties/wantTags 每批的接收者数量
+		wantUsers   []int
+		wantParties []int
+		wantTags    []int
+		wantErr     string
+	}{
+		{
+			name:      "users split by chunk size",
+			req:       textBroadcast(userIDs(2500), nil, nil),
+			chunkSize: message.MaxToUsers,
+			wantUsers: []int{1000, 1000, 500}, wantParties: []int{0, 0, 0}, wantTags: []int{0, 0, 0},
+		},
+		{
+			name:      "duplicates removed before splitting",
+			req:       textBroadcast(append(userIDs(3), userIDs(3)...), []int{2, 2}, []int{1, 1}),
+			chunkSize: 2,
+			wantUsers: []int{2, 1}, wantParties: []int{1, 0}, wantTags: []int{1, 0},
+		},
+		{
+			name:      "parties and tags split by their own limits",
+			req:       textBroadcast(userIDs(10), seq(message.MaxToParties+1), seq(2*message.MaxToTags+1)),
+			chunkSize: message.MaxToUsers,
+			wantUsers: []int{10, 0, 0}, wantParties: []int{100, 1, 0}, wantTags: []int{100, 100, 1},
+		},
+		{name: "nil request", chunkSize: message.MaxToUsers, wantErr: "broadcast message is required"},
+		{name: "no recipients", req: textBroadcast(nil, nil, nil), chunkSize: message.MaxToUsers, wantErr: "no recipients"},
+		{name: "separator in userid", req: textBroadcast([]string{"a|b"}, nil, nil), chunkSize: message.MaxToUsers, wantErr: `invalid broadcast userid "a|b"`},
+		{name: "@all userid", req: textBroadcast([]string{"@all"}, nil, nil), chunkSize: message.MaxToUsers, wantErr: `invalid broadcast userid "@all"`},
+		{name: "empty userid", req: textBroadcast([]string{""}, nil, nil), chunkSize: message.MaxToUsers, wantErr: `invalid broadcast userid ""`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chunks, err := messagesvc.SplitBroadcast(tt.req, tt.chunkSize)
+			if tt.wantErr != "" {
+				assert.ErrorIs(t, err, message.ErrInvalidMessage)
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			count := func(s string) int {
+				if s == "" {
+					return 0
+				}
+				return len(strings.Split(s, "|"))
+			}
+			var users, parties, tags []int
+			for _, c := range chunks {
+				users = append(users, count(c.ToUser))
+				parties = append(parties, count(c.ToParty))
+				tags = append(tags, count(c.ToTag))
+				// 每批复制消息内容，不修改原请求
+				assert.Equal(t, "hello", c.Text.Content)
+				assert.NotSame(t, tt.req.Message, c)
+			}
+			assert.Equal(t, tt.wantUsers, users)
+			assert.Equal(t, tt.wantParties, parties)
+			assert.Equal(t, tt.wantTags, tags)
+			assert.Empty(t, tt.req.Message.ToUser)
+		})
+	}
+
+	t.Run("recipients set on message", func(t *testing.T) {
+		req := textBroadcast(userIDs(1), nil, nil)
+		req.Message.ToUser = "zhangsan"
+		_, err := messagesvc.SplitBroadcast(req, message.MaxToUsers)
+		assert.ErrorIs(t, err, message.ErrInvalidMessage)
+		assert.ErrorContains(t, err, "must be set on the request")
+	})
+}
+
+func TestBroadcastFingerprint(t *testing.T) {
+	base := messagesvc.BroadcastFingerprint(textBroadcast(userIDs(3), []int{2}, []int{1}), message.MaxToUsers)
+
+	// 消息内容、广播ID与重复的接收者不影响摘要
+	same := textBroadcast(append(userIDs(3), "user0"), []int{2, 2}, []int{1})
+	same.ID = "notice-2"
+	same.Message.Text.Content = "changed"
+	assert.Equal(t, base, messagesvc.BroadcastFingerprint(same, message.MaxToUsers))
+
+	// 接收者、顺序或分批方式变化时摘要不同
+	reordered := userIDs(3)
+	slices.Reverse(reordered)
+	for name, fingerprint := range map[string]string{
+		"users":      messagesvc.BroadcastFingerprint(textBroadcast(userIDs(4), []int{2}, []int{1}), message.MaxToUsers),
+		"order":      messagesvc.BroadcastFingerprint(textBroadcast(reordered, []int{2}, []int{1}), message.MaxToUsers),
+		"parties":    messagesvc.BroadcastFingerprint(textBroadcast(userIDs(3), []int{3}, []int{1}), message.MaxToUsers),
+		"tags":       messagesvc.BroadcastFingerprint(textBroadcast(userIDs(3), []int{2}, nil), message.MaxToUsers),
+		"chunk size": messagesvc.BroadcastFingerprint(textBroadcast(userIDs(3), []int{2}, []int{1}), 2),
+	} {
+		assert.NotEqual(t, base, fingerprint, name)
+	}
+}
+
+// failingStore 读取进度失败的存储
+type failingStore struct {
+	*messagesvc.MemoryBroadcastStore
+}
+
+func (failingStore) LoadBroadcast(ctx context.Context, id string) (*message.BroadcastResult, error) {
+	return nil, fmt.Errorf("store unavailable")
+}
+
+func TestBroadcast_Resume(t *testing.T) {
+	req := textBroadcast(userIDs(5), nil, nil)
+	fingerprint := messagesvc.BroadcastFingerprint(req, 2)
+	chunk := func(index int) message.BroadcastChunk {
+		return message.BroadcastChunk{Index: index, MsgID: fmt.Sprintf("saved-%d", index)}
+	}
+
+	tests := []struct {
+		name      string
+		id        string
+		saved     *message.BroadcastResult
+		store     messagesvc.BroadcastStore
+		wantSent  []string
+		wantMsgs  []string
+		wantSaved bool
+		wantErr   error
+		errText   string
+	}{
+		{
+			name:      "no checkpoint sends all chunks",
+			id:        "notice-1",
+			wantSent:  []string{"user0|user1", "user2|user3", "user4"},
+			wantMsgs:  []string{"msg-user0", "msg-user2", "msg-user4"},
+			wantSaved: true,
+		},
+		{
+			name:      "skips saved chunks",
+			id:        "notice-1",
+			saved:     &message.BroadcastResult{ID: "notice-1", Fingerprint: fingerprint, Total: 3, Chunks: []message.BroadcastChunk{chunk(0), chunk(2)}},
+			wantSent:  []string{"user2|user3"},
+			wantMsgs:  []string{"saved-0", "msg-user2", "saved-2"},
+			wantSaved: true,
+		},
+		{
+			name:      "completed checkpoint sends nothing",
+			id:        "notice-1",
+			saved:     &message.BroadcastResult{ID: "notice-1", Fingerprint: fingerprint, Total: 3, Chunks: []message.BroadcastChunk{chunk(0), chunk(1), chunk(2)}},
+			wantMsgs:  []string{"saved-0", "saved-1", "saved-2"},
+			wantSaved: true,
+		},
+		{
+			name:    "fingerprint mismatch",
+			id:      "notice-1",
+			saved:   &message.BroadcastResult{ID: "notice-1", Fingerprint: "other", Total: 3, Chunks: []message.BroadcastChunk{chunk(0)}},
+			wantErr: messagesvc.ErrCheckpointMismatch,
+		},
+		{
+			name:    "total mismatch",
+			id:      "notice-1",
+			saved:   &message.BroadcastResult{ID: "notice-1", Fingerprint: fingerprint, Total: 2, Chunks: []message.BroadcastChunk{chunk(0)}},
+			wantErr: messagesvc.ErrCheckpointMismatch,
+		},
+		{
+			name:     "without id progress is not saved",
+			saved:    &message.BroadcastResult{Fingerprint: fingerprint, Total: 3, Chunks: []message.BroadcastChunk{chunk(0)}},
+			wantSent: []string{"user0|user1", "user2|user3", "user4"},
+			wantMsgs: []string{"msg-user0", "msg-user2", "msg-user4"},
+		},
+		{
+			name:    "store load error",
+			id:      "notice-1",
+			store:   failingStore{messagesvc.NewMemoryBroadcastStore()},
+			errText: "failed to load broadcast checkpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := wecomtest.NewServer(t)
+			srv.Handle("/cgi-bin/message/send", func(w http.ResponseWriter, r *http.Request) {
+				var req message.SendMessageRequest
+				_ = json.NewDecoder(r.Body).Decode(&req)
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{"errcode": 0, "msgid": "msg-" + strings.Split(req.ToUser, "|")[0]})
+			})
+			client, err := wecom.New(srv.Options()...)
+			require.NoError(t, err)
+			ctx := context.Background()
+
+			memory := messagesvc.NewMemoryBroadcastStore()
+			if tt.saved != nil {
+				require.NoError(t, memory.SaveBroadcast(ctx, tt.saved.ID, tt.saved))
+			}
+			store := tt.store
+			if store == nil {
+				store = memory
+			}
+
+			r := *req
+			r.ID = tt.id
+			result, err := client.Message.Broadcast(ctx, &r, messagesvc.WithChunkSize(2), messagesvc.WithBroadcastConcurrency(1), messagesvc.WithBroadcastStore(store))
+			switch {
+			case tt.wantErr != nil:
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Empty(t, srv.Requests())
+				return
+			case tt.errText != "":
+				assert.ErrorContains(t, err, tt.errText)
+				assert.Empty(t, srv.Requests())
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, result.Done())
+			assert.Equal(t, tt.wantMsgs, result.MsgIDs())
+
+			var sent []string
+			for _, req := range srv.RequestsTo("/cgi-bin/message/send") {
+				var body message.SendMessageRequest
+				require.NoError(t, req.Decode(&body))
+				sent = append(sent, body.ToUser)
+			}
+			assert.Equal(t, tt.wantSent, sent)
+
+			saved, err := memory.LoadBroadcast(ctx, tt.id)
+			require.NoError(t, err)
+			if tt.wantSaved {
+				require.NotNil(t, saved)
+				assert.Equal(t, tt.wantMsgs, saved.MsgIDs())
+			} else if tt.id == "" && tt.saved != nil {
+				// 未设置ID时不读取也不更新进度
+				assert.Equal(t, []string{"saved-0"}, saved.MsgIDs())
+			}
+		})
+	}
+}
@@ -0,0 +1,7 @@
+package message
+
+// 导出未导出的函数供 message_test 包测试
+var (
+	SplitBroadcast       = splitBroadcast
+	BroadcastFingerprint = broadcastFingerprint
+)
@@ -0,0 +1,80 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	"github.com/shuaidd/wecom-core/types/media"
+	"github.com/shuaidd/wecom-core/types/message"
+)
+
+// ErrNoMediaUploader 未设置素材上传器，无法使用 SendFile 等方法
+var ErrNoMediaUploader = errors.New("message: media uploader is not configured")
+
+// MediaUploader 素材上传器，返回数据源对应的 media_id
+// media.MediaCache 实现了该接口，按文件内容缓存 media_id，避免重复上传
+type MediaUploader interface {
+	Upload(ctx context.Context, mediaType media.MediaType, src upload.Source) (string, error)
+}
+
+// SetMediaUploader 设置 SendFile、SendImage 与 SendMedia 使用的素材上传器
+func (s *Service) SetMediaUploader(u MediaUploader) *Service {
+	s.uploader = u
+	return s
+}
+
+// SendFile 发送本地文件，文件按需上传为临时素材
+// 使用客户端默认的素材缓存时，文件超过 20MB 返回 services/media 的 ErrMediaTooLarge（异步上传得到的 media_id 不能用于应用消息）
+func (s *Service) SendFile(ctx context.Context, to message.Recipients, path string) (*message.SendMessageResponse, error) {
+	src, err := upload.File(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendMedia(ctx, to, media.MediaTypeFile, src)
+}
+
+// SendImage 发送本地图片，图片按需上传为临时素材
+// 使用客户端默认的素材缓存时，图片超过 10MB 返回 services/media 的 ErrMediaTooLarge
+func (s *Service) SendImage(ctx context.Context, to message.Recipients, path string) (*message.SendMessageResponse, error) {
+	src, err := upload.File(path)
+	if err != nil {
+		return nil, err
+	}
+	return s.SendMedia(ctx, to, media.MediaTypeImage, src)
+}
+
+// SendMedia 发送图片、语音、视频或文件消息，数据源按需上传为临时素材
+// 视频消息的标题为文件名，数据源超过素材类型的直接上传限制时由上传器返回错误
+func (s *Service) SendMedia(ctx context.Context, to message.Recipients, mediaType media.MediaType, src upload.Source) (*message.SendMessageResponse, error) {
+	if s.uploader == nil {
+		return nil, ErrNoMediaUploader
+	}
+
+	var newMessage func(mediaID string) *message.Builder
+	switch mediaType {
+	case media.MediaTypeImage:
+		newMessage = message.NewImage
+	case media.MediaTypeVoice:
+		newMessage = message.NewVoice
+	case media.MediaTypeVideo:
+		newMessage = func(mediaID string) *message.Builder {
+			return message.NewVideo(mediaID, src.Name(), "")
+		}
+	case media.MediaTypeFile:
+		newMessage = message.NewFile
+	default:
+		return nil, fmt.Errorf("%w: unsupported media type %q", message.ErrInvalidMessage, mediaType)
+	}
+
+	mediaID, err := s.uploader.Upload(ctx, mediaType, src)
+	if err != nil {
+		return nil, err
+	}
+	req, err := newMessage(mediaID).To(to).Build()
+	if err != nil {
+		return nil, err
+	}
+	return s.Send(ctx, req)
+}
@@ -0,0 +1,89 @@
+package message_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	mediasvc "github.com/shuaidd/wecom-core/services/media"
+	"github.com/shuaidd/wecom-core/types/media"
+	"github.com/shuaidd/wecom-core/types/message"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestSendFile(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Agent("notify", 1000002)
+	srv.Agent("report", 1000003)
+	srv.Stub("/cgi-bin/media/upload", map[string]any{"errcode": 0, "type": "file", "media_id": "media-1"})
+	srv.Stub("/cgi-bin/message/send", map[string]any{"errcode": 0, "msgid": "msg-1"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := wecom.WithAgentName(context.Background(), "notify")
+
+	path := filepath.Join(t.TempDir(), "report.pdf")
+	require.NoError(t, os.WriteFile(path, []byte("%PDF-1.4 report"), 0o644))
+	to := message.Recipients{AgentID: 1000002, UserIDs: []string{"zhangsan", "lisi"}}
+
+	// 同一文件只上传一次
+	for i := 0; i < 2; i++ {
+		resp, err := client.Message.SendFile(ctx, to, path)
+		require.NoError(t, err)
+		assert.Equal(t, "msg-1", resp.MsgID)
+	}
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 1)
+	srv.AssertCalledTimes(t, "/cgi-bin/message/send", 2)
+	srv.AssertBody(t, "/cgi-bin/message/send", `{
+		"touser": "zhangsan|lisi",
+		"msgtype": "file",
+		"agentid": 1000002,
+		"file": {"media_id": "media-1"}
+	}`)
+
+	// 不同素材类型与不同应用分别缓存
+	_, err = client.Message.SendImage(ctx, to, path)
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 2)
+	assert.Equal(t, "image", srv.LastRequest("/cgi-bin/media/upload").Query.Get("type"))
+
+	ctx = wecom.WithAgentName(context.Background(), "report")
+	_, err = client.Message.SendFile(ctx, message.Recipients{AgentID: 1000003, ToAll: true}, path)
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 3)
+	assert.Equal(t, "report", srv.LastRequest("/cgi-bin/media/upload").Agent)
+
+	// 内容变化后重新上传
+	require.NoError(t, os.WriteFile(path, []byte("%PDF-1.4 report v2"), 0o644))
+	_, err = client.Message.SendFile(ctx, message.Recipients{AgentID: 1000003, ToAll: true}, path)
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/media/upload", 4)
+}
+
+func TestSendFile_TooLarge(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/media/upload", map[string]any{"errcode": 0, "type": "file", "media_id": "media-1"})
+	srv.Stub("/cgi-bin/message/send", map[string]any{"errcode": 0, "msgid": "msg-1"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	// 稀疏文件，不实际占用磁盘空间
+	path := filepath.Join(t.TempDir(), "large.zip")
+	f, err := os.Create(path)
+	require.NoError(t, err)
+	require.NoError(t, f.Truncate(media.MaxFileBytes+1))
+	require.NoError(t, f.Close())
+
+	// 超过 20MB 的文件不能通过应用消息发送，不上传也不发送消息
+	_, err = client.Message.SendFile(context.Background(), message.Recipients{ToAll: true}, path)
+	assert.ErrorIs(t, err, mediasvc.ErrMediaTooLarge)
+	srv.AssertNotCalled(t, "/cgi-bin/media/upload")
+	srv.AssertNotCalled(t, "/cgi-bin/message/send")
+	srv.AssertNotCalled(t, "/cgi-bin/media/upload_by_url")
+}
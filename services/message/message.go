@@ -6,7 +6,8 @@ import (
 
 // Service 消息服务
 type Service struct {
-	client *client.Client
+	client   *client.Client
+	uploader MediaUploader
 }
 
 // NewService 创建消息服务
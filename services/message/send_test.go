@@ -0,0 +1,51 @@
+package message_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/types/message"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestSend_Builder(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/message/send", map[string]any{"errcode": 0, "msgid": "msg-1"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 校验失败时不发起请求
+	_, err = message.NewText("hello").AgentID(1000002).Build()
+	assert.ErrorIs(t, err, message.ErrInvalidMessage)
+	assert.Empty(t, srv.Requests())
+
+	req, err := message.NewText("hello").
+		ToUsers("zhangsan", "lisi", "zhangsan").
+		ToParties(2).
+		AgentID(1000002).
+		Safe().
+		DedupWithin(10 * time.Minute).
+		Build()
+	require.NoError(t, err)
+
+	resp, err := client.Message.Send(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", resp.MsgID)
+	srv.AssertBody(t, "/cgi-bin/message/send", `{
+		"touser": "zhangsan|lisi",
+		"toparty": "2",
+		"msgtype": "text",
+		"agentid": 1000002,
+		"safe": 1,
+		"enable_duplicate_check": 1,
+		"duplicate_check_interval": 600,
+		"text": {"content": "hello"}
+	}`)
+}
@@ -0,0 +1,60 @@
+package msgaudit
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/types/msgaudit"
+)
+
+// Service 会话内容存档服务
+type Service struct {
+	client *client.Client
+}
+
+// NewService 创建会话内容存档服务
+func NewService(c *client.Client) *Service {
+	return &Service{
+		client: c,
+	}
+}
+
+// GetPermitUserList 获取会话内容存档开启成员列表
+// 文档: https://developer.work.weixin.qq.com/document/path/91614
+func (s *Service) GetPermitUserList(ctx context.Context, req *msgaudit.GetPermitUserListRequest) (*msgaudit.GetPermitUserListResponse, error) {
+	return client.PostAndUnmarshal[msgaudit.GetPermitUserListResponse](s.client, ctx, "/cgi-bin/msgaudit/get_permit_user_list", req)
+}
+
+// CheckSingleAgree 获取单聊会话同意情况
+// 文档: https://developer.work.weixin.qq.com/document/path/91782
+func (s *Service) CheckSingleAgree(ctx context.Context, req *msgaudit.CheckSingleAgreeRequest) (*msgaudit.CheckSingleAgreeResponse, error) {
+	return client.PostAndUnmarshal[msgaudit.CheckSingleAgreeResponse](s.client, ctx, "/cgi-bin/msgaudit/check_single_agree", req)
+}
+
+// CheckRoomAgree 获取群聊会话同意情况
+// 文档: https://developer.work.weixin.qq.com/document/path/91782
+func (s *Service) CheckRoomAgree(ctx context.Context, roomID string) (*msgaudit.CheckRoomAgreeResponse, error) {
+	req := &msgaudit.CheckRoomAgreeRequest{
+		RoomID: roomID,
+	}
+	return client.PostAndUnmarshal[msgaudit.CheckRoomAgreeResponse](s.client, ctx, "/cgi-bin/msgaudit/check_room_agree", req)
+}
+
+// GetGroupChat 获取会话内容存档内部群信息
+// 文档: https://developer.work.weixin.qq.com/document/path/92951
+func (s *Service) GetGroupChat(ctx context.Context, roomID string) (*msgaudit.GetGroupChatResponse, error) {
+	req := &msgaudit.GetGroupChatRequest{
+		RoomID: roomID,
+	}
+	return client.PostAndUnmarshal[msgaudit.GetGroupChatResponse](s.client, ctx, "/cgi-bin/msgaudit/groupchat/get", req)
+}
+
+// GetRobotInfo 获取机器人信息
+// 文档: https://developer.work.weixin.qq.com/document/path/91360
+func (s *Service) GetRobotInfo(ctx context.Context, robotID string) (*msgaudit.GetRobotInfoResponse, error) {
+	query := url.Values{}
+	query.Set("robot_id", robotID)
+
+	return client.GetAndUnmarshal[msgaudit.GetRobotInfoResponse](s.client, ctx, "/cgi-bin/msgaudit/get_robot_info", query)
+}
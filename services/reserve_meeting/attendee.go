@@ -2,6 +2,7 @@ package reserve_meeting
 
 import (
 	"context"
+	"iter"
 
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/types/common"
@@ -27,6 +28,18 @@ func (s *Service) GetInviteesWithCursor(ctx context.Context, meetingID, cursor s
 	return client.PostAndUnmarshal[reserve_meeting.GetInviteesResponse](s.client, ctx, "/cgi-bin/meeting/get_invitees", req)
 }
 
+// AllInvitees 遍历会议的全部受邀成员
+// 自动处理 has_more/next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllInvitees(ctx context.Context, meetingID string) iter.Seq2[reserve_meeting.Invitee, error] {
+	return client.Paginate(ctx, "", func(ctx context.Context, cursor string) (*client.Page[string, reserve_meeting.Invitee], error) {
+		resp, err := s.GetInviteesWithCursor(ctx, meetingID, cursor)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[string, reserve_meeting.Invitee]{Items: resp.Invitees, Next: resp.NextCursor, HasMore: resp.HasMore}, nil
+	})
+}
+
 // SetInvitees 更新会议受邀成员列表
 // 文档: https://developer.work.weixin.qq.com/document/path/94050
 func (s *Service) SetInvitees(ctx context.Context, req *reserve_meeting.SetInviteesRequest) error {
@@ -65,6 +78,20 @@ func (s *Service) GetRealtimeAttendeeListWithCursor(ctx context.Context, req *re
 	return client.PostAndUnmarshal[reserve_meeting.GetRealtimeAttendeeListResponse](s.client, ctx, "/cgi-bin/meeting/get_realtime_attendee_list", req)
 }
 
+// AllRealtimeAttendees 遍历全部实时会中成员
+// 自动处理 has_more/next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllRealtimeAttendees(ctx context.Context, req *reserve_meeting.GetRealtimeAttendeeListRequest) iter.Seq2[reserve_meeting.MeetingAttendee, error] {
+	r := *req
+	return client.Paginate(ctx, r.Cursor, func(ctx context.Context, cursor string) (*client.Page[string, reserve_meeting.MeetingAttendee], error) {
+		r.Cursor = cursor
+		resp, err := s.GetRealtimeAttendeeListWithCursor(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[string, reserve_meeting.MeetingAttendee]{Items: resp.Attendees, Next: resp.NextCursor, HasMore: resp.HasMore}, nil
+	})
+}
+
 // GetAttendeeList 获取已参会成员列表
 // 文档: https://developer.work.weixin.qq.com/document/path/93736
 func (s *Service) GetAttendeeList(ctx context.Context, meetingID string) (*reserve_meeting.GetAttendeeListResponse, error) {
@@ -80,6 +107,20 @@ func (s *Service) GetAttendeeListWithCursor(ctx context.Context, req *reserve_me
 	return client.PostAndUnmarshal[reserve_meeting.GetAttendeeListResponse](s.client, ctx, "/cgi-bin/meeting/get_attendee_list", req)
 }
 
+// AllAttendees 遍历全部已参会成员
+// 自动处理 has_more/next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllAttendees(ctx context.Context, req *reserve_meeting.GetAttendeeListRequest) iter.Seq2[reserve_meeting.HistoryAttendee, error] {
+	r := *req
+	return client.Paginate(ctx, r.Cursor, func(ctx context.Context, cursor string) (*client.Page[string, reserve_meeting.HistoryAttendee], error) {
+		r.Cursor = cursor
+		resp, err := s.GetAttendeeListWithCursor(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[string, reserve_meeting.HistoryAttendee]{Items: resp.Attendees, Next: resp.NextCursor, HasMore: resp.HasMore}, nil
+	})
+}
+
 // WaitingRoomGetCurrentUserList 获取实时等候室成员列表
 // 文档: https://developer.work.weixin.qq.com/document/path/93695
 func (s *Service) WaitingRoomGetCurrentUserList(ctx context.Context, meetingID string) (*reserve_meeting.WaitingRoomGetCurrentUserListResponse, error) {
@@ -100,6 +141,18 @@ func (s *Service) WaitingRoomGetCurrentUserListWithCursor(ctx context.Context, m
 	return client.PostAndUnmarshal[reserve_meeting.WaitingRoomGetCurrentUserListResponse](s.client, ctx, "/cgi-bin/meeting/waitingroom/get_current_user_list", req)
 }
 
+// AllWaitingRoomCurrentUsers 遍历全部实时等候室成员
+// 自动处理 has_more/next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllWaitingRoomCurrentUsers(ctx context.Context, meetingID string, limit int32) iter.Seq2[reserve_meeting.WaitingRoomUser, error] {
+	return client.Paginate(ctx, "", func(ctx context.Context, cursor string) (*client.Page[string, reserve_meeting.WaitingRoomUser], error) {
+		resp, err := s.WaitingRoomGetCurrentUserListWithCursor(ctx, meetingID, limit, cursor)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[string, reserve_meeting.WaitingRoomUser]{Items: resp.UserList, Next: resp.NextCursor, HasMore: resp.HasMore}, nil
+	})
+}
+
 // WaitingRoomGetUserList 获取等候室成员记录
 // 文档: https://developer.work.weixin.qq.com/document/path/94103
 func (s *Service) WaitingRoomGetUserList(ctx context.Context, meetingID string) (*reserve_meeting.WaitingRoomGetUserListResponse, error) {
@@ -120,6 +173,18 @@ func (s *Service) WaitingRoomGetUserListWithCursor(ctx context.Context, meetingI
 	return client.PostAndUnmarshal[reserve_meeting.WaitingRoomGetUserListResponse](s.client, ctx, "/cgi-bin/meeting/waitingroom/get_user_list", req)
 }
 
+// AllWaitingRoomUsers 遍历全部等候室成员记录
+// 自动处理 has_more/next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllWaitingRoomUsers(ctx context.Context, meetingID string, limit int32) iter.Seq2[reserve_meeting.WaitingRoomHistoryUser, error] {
+	return client.Paginate(ctx, "", func(ctx context.Context, cursor string) (*client.Page[string, reserve_meeting.WaitingRoomHistoryUser], error) {
+		resp, err := s.WaitingRoomGetUserListWithCursor(ctx, meetingID, limit, cursor)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[string, reserve_meeting.WaitingRoomHistoryUser]{Items: resp.UserList, Next: resp.NextCursor, HasMore: resp.HasMore}, nil
+	})
+}
+
 // CreateCustomerShortURL 创建用户专属参会链接
 // 文档: https://developer.work.weixin.qq.com/document/path/93994
 func (s *Service) CreateCustomerShortURL(ctx context.Context, req *reserve_meeting.CreateCustomerShortURLRequest) (*reserve_meeting.CreateCustomerShortURLResponse, error) {
@@ -2,6 +2,7 @@ package reserve_meeting
 
 import (
 	"context"
+	"iter"
 
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/types/reserve_meeting"
@@ -28,6 +29,20 @@ func (s *Service) EnrollListWithCursor(ctx context.Context, req *reserve_meeting
 	return client.PostAndUnmarshal[reserve_meeting.EnrollListResponse](s.client, ctx, "/cgi-bin/meeting/enroll/list", req)
 }
 
+// AllEnrolls 遍历会议的全部报名信息
+// 自动处理 has_more/next_cursor 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllEnrolls(ctx context.Context, req *reserve_meeting.EnrollListRequest) iter.Seq2[reserve_meeting.Enroll, error] {
+	r := *req
+	return client.Paginate(ctx, r.Cursor, func(ctx context.Context, cursor string) (*client.Page[string, reserve_meeting.Enroll], error) {
+		r.Cursor = cursor
+		resp, err := s.EnrollListWithCursor(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[string, reserve_meeting.Enroll]{Items: resp.EnrollList, Next: resp.NextCursor, HasMore: resp.HasMore}, nil
+	})
+}
+
 // EnrollListByStatus 按状态获取会议报名信息
 // 文档: https://developer.work.weixin.qq.com/document/path/93853
 func (s *Service) EnrollListByStatus(ctx context.Context, meetingID string, status int32) (*reserve_meeting.EnrollListResponse, error) {
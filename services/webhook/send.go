@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/types/common"
+	"github.com/shuaidd/wecom-core/types/message"
+	"github.com/shuaidd/wecom-core/types/webhook"
+)
+
+// ErrInvalidMessage 消息不符合群机器人接口的限制
+var ErrInvalidMessage = errors.New("webhook: invalid message")
+
+// Send 发送群机器人消息
+// 发送前按文档校验消息类型与大小限制，不合法时返回 ErrInvalidMessage
+// 文档: https://developer.work.weixin.qq.com/document/path/91770
+func (s *Service) Send(ctx context.Context, req *webhook.SendRequest) (*common.Response, error) {
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+	r := client.NewRequest(client.MethodPost, "/cgi-bin/webhook/send").
+		SetQuery("key", s.key).
+		SetBody(req)
+	return client.DoAndUnmarshal[common.Response](s.client, ctx, r)
+}
+
+// SendText 发送文本消息
+// mentionedList 为需要@的成员userid列表，@all 表示提醒所有人
+func (s *Service) SendText(ctx context.Context, content string, mentionedList ...string) (*common.Response, error) {
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType: webhook.MessageTypeText,
+		Text: &webhook.TextMessage{
+			Content:       content,
+			MentionedList: mentionedList,
+		},
+	})
+}
+
+// SendMarkdown 发送markdown消息
+func (s *Service) SendMarkdown(ctx context.Context, content string) (*common.Response, error) {
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType:  webhook.MessageTypeMarkdown,
+		Markdown: &webhook.MarkdownMessage{Content: content},
+	})
+}
+
+// SendImage 发送图片消息
+// data 为图片原始内容（JPG、PNG），base64 编码和 md5 由SDK计算
+func (s *Service) SendImage(ctx context.Context, data []byte) (*common.Response, error) {
+	if len(data) > webhook.MaxImageBytes {
+		return nil, fmt.Errorf("%w: image size %d exceeds %d bytes", ErrInvalidMessage, len(data), webhook.MaxImageBytes)
+	}
+	sum := md5.Sum(data)
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType: webhook.MessageTypeImage,
+		Image: &webhook.ImageMessage{
+			Base64: base64.StdEncoding.EncodeToString(data),
+			MD5:    hex.EncodeToString(sum[:]),
+		},
+	})
+}
+
+// SendNews 发送图文消息
+func (s *Service) SendNews(ctx context.Context, articles ...webhook.NewsArticle) (*common.Response, error) {
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType: webhook.MessageTypeNews,
+		News:    &webhook.NewsMessage{Articles: articles},
+	})
+}
+
+// SendFile 发送文件消息
+// mediaID 通过 UploadMedia 上传普通文件获取
+func (s *Service) SendFile(ctx context.Context, mediaID string) (*common.Response, error) {
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType: webhook.MessageTypeFile,
+		File:    &webhook.MediaMessage{MediaID: mediaID},
+	})
+}
+
+// SendVoice 发送语音消息
+// mediaID 通过 UploadMedia 上传语音文件获取
+func (s *Service) SendVoice(ctx context.Context, mediaID string) (*common.Response, error) {
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType: webhook.MessageTypeVoice,
+		Voice:   &webhook.MediaMessage{MediaID: mediaID},
+	})
+}
+
+// SendTemplateCard 发送模板卡片消息
+// 群机器人仅支持文本通知型和图文展示型模板卡片
+func (s *Service) SendTemplateCard(ctx context.Context, card *message.TemplateCardMessage) (*common.Response, error) {
+	return s.Send(ctx, &webhook.SendRequest{
+		MsgType:      webhook.MessageTypeTemplateCard,
+		TemplateCard: card,
+	})
+}
+
+// Validate 按文档校验群机器人消息
+func Validate(req *webhook.SendRequest) error {
+	if req == nil {
+		return fmt.Errorf("%w: request is nil", ErrInvalidMessage)
+	}
+
+	switch req.MsgType {
+	case webhook.MessageTypeText:
+		if req.Text == nil || req.Text.Content == "" {
+			return fmt.Errorf("%w: text content is required", ErrInvalidMessage)
+		}
+		if n := len(req.Text.Content); n > webhook.MaxTextBytes {
+			return fmt.Errorf("%w: text content is %d bytes, exceeds %d", ErrInvalidMessage, n, webhook.MaxTextBytes)
+		}
+	case webhook.MessageTypeMarkdown:
+		if req.Markdown == nil || req.Markdown.Content == "" {
+			return fmt.Errorf("%w: markdown content is required", ErrInvalidMessage)
+		}
+		if n := len(req.Markdown.Content); n > webhook.MaxMarkdownBytes {
+			return fmt.Errorf("%w: markdown content is %d bytes, exceeds %d", ErrInvalidMessage, n, webhook.MaxMarkdownBytes)
+		}
+	case webhook.MessageTypeImage:
+		if req.Image == nil || req.Image.Base64 == "" || req.Image.MD5 == "" {
+			return fmt.Errorf("%w: image base64 and md5 are required", ErrInvalidMessage)
+		}
+		data, err := base64.StdEncoding.DecodeString(req.Image.Base64)
+		if err != nil {
+			return fmt.Errorf("%w: image base64 is invalid: %v", ErrInvalidMessage, err)
+		}
+		if len(data) > webhook.MaxImageBytes {
+			return fmt.Errorf("%w: image size %d exceeds %d bytes", ErrInvalidMessage, len(data), webhook.MaxImageBytes)
+		}
+		if sum := md5.Sum(data); !strings.EqualFold(hex.EncodeToString(sum[:]), req.Image.MD5) {
+			return fmt.Errorf("%w: image md5 does not match content", ErrInvalidMessage)
+		}
+	case webhook.MessageTypeNews:
+		if req.News == nil || len(req.News.Articles) == 0 {
+			return fmt.Errorf("%w: news requires at least one article", ErrInvalidMessage)
+		}
+		if n := len(req.News.Articles); n > webhook.MaxNewsArticles {
+			return fmt.Errorf("%w: news has %d articles, exceeds %d", ErrInvalidMessage, n, webhook.MaxNewsArticles)
+		}
+		for i, a := range req.News.Articles {
+			if a.Title == "" || a.URL == "" {
+				return fmt.Errorf("%w: news article %d requires title and url", ErrInvalidMessage, i)
+			}
+		}
+	case webhook.MessageTypeFile:
+		if req.File == nil || req.File.MediaID == "" {
+			return fmt.Errorf("%w: file media_id is required", ErrInvalidMessage)
+		}
+	case webhook.MessageTypeVoice:
+		if req.Voice == nil || req.Voice.MediaID == "" {
+			return fmt.Errorf("%w: voice media_id is required", ErrInvalidMessage)
+		}
+	case webhook.MessageTypeTemplateCard:
+		if req.TemplateCard == nil {
+			return fmt.Errorf("%w: template_card is required", ErrInvalidMessage)
+		}
+		switch req.TemplateCard.CardType {
+		case message.TemplateCardTypeTextNotice, message.TemplateCardTypeNewsNotice:
+		default:
+			return fmt.Errorf("%w: unsupported template card type %q", ErrInvalidMessage, req.TemplateCard.CardType)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported msgtype %q", ErrInvalidMessage, req.MsgType)
+	}
+	return nil
+}
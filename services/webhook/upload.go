@@ -0,0 +1,56 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/pkg/upload"
+	"github.com/shuaidd/wecom-core/types/webhook"
+)
+
+// UploadMedia 上传文件
+// 上传后获取的 media_id 仅三天内有效，仅限同一个群机器人使用
+// 文档: https://developer.work.weixin.qq.com/document/path/91770
+func (s *Service) UploadMedia(ctx context.Context, mediaType webhook.MediaType, mediaPath string) (*webhook.UploadMediaResponse, error) {
+	src, err := upload.File(mediaPath)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadMediaFromSource(ctx, mediaType, src)
+}
+
+// UploadMediaFromReader 从 io.Reader 上传文件
+// 普通文件 5B~20MB，语音 5B~2MB 且仅支持AMR格式，超出限制时返回 ErrInvalidMessage
+func (s *Service) UploadMediaFromReader(ctx context.Context, mediaType webhook.MediaType, reader io.Reader, filename string) (*webhook.UploadMediaResponse, error) {
+	src, err := upload.FromReader(filename, reader)
+	if err != nil {
+		return nil, err
+	}
+	return s.UploadMediaFromSource(ctx, mediaType, src)
+}
+
+// UploadMediaFromSource 从可重复打开的数据源流式上传文件
+// 发送前按 src.Size() 校验大小限制，超出限制时返回 ErrInvalidMessage
+func (s *Service) UploadMediaFromSource(ctx context.Context, mediaType webhook.MediaType, src upload.Source) (*webhook.UploadMediaResponse, error) {
+	var limit int64
+	switch mediaType {
+	case webhook.MediaTypeFile:
+		limit = webhook.MaxFileBytes
+	case webhook.MediaTypeVoice:
+		limit = webhook.MaxVoiceBytes
+	default:
+		return nil, fmt.Errorf("%w: unsupported media type %q", ErrInvalidMessage, mediaType)
+	}
+	if n := src.Size(); n < webhook.MinMediaBytes || n > limit {
+		return nil, fmt.Errorf("%w: %s size must be between %d and %d bytes", ErrInvalidMessage, mediaType, webhook.MinMediaBytes, limit)
+	}
+
+	query := url.Values{}
+	query.Set("key", s.key)
+	query.Set("type", string(mediaType))
+
+	return client.PostUploadAndUnmarshal[webhook.UploadMediaResponse](s.client, ctx, "/cgi-bin/webhook/upload_media", query, "media", src)
+}
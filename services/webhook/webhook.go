@@ -0,0 +1,26 @@
+package webhook
+
+import (
+	"github.com/shuaidd/wecom-core/internal/client"
+)
+
+// Service 群机器人服务
+// 群机器人通过 webhook key 鉴权，不需要 access_token
+type Service struct {
+	client *client.Client
+	// key 群机器人 webhook 地址中的 key
+	key string
+}
+
+// NewService 创建群机器人服务
+func NewService(c *client.Client, key string) *Service {
+	return &Service{
+		client: c,
+		key:    key,
+	}
+}
+
+// Key 返回群机器人的 webhook key
+func (s *Service) Key() string {
+	return s.key
+}
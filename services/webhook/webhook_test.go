@@ -0,0 +1,92 @@
+package webhook_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/services/webhook"
+	"github.com/shuaidd/wecom-core/types/message"
+	webhooktypes "github.com/shuaidd/wecom-core/types/webhook"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestWebhook_Send(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/webhook/send", map[string]any{"errcode": 0, "errmsg": "ok"})
+	srv.Stub("/cgi-bin/webhook/upload_media", map[string]any{"errcode": 0, "type": "file", "media_id": "media-1"})
+
+	// 不需要 CorpID 与应用密钥，支持完整的 webhook 地址
+	bot, err := wecom.NewWebhook(srv.URL+"/cgi-bin/webhook/send?key=robot-key", config.WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = bot.SendText(ctx, "hello", "@all")
+	require.NoError(t, err)
+	srv.AssertQuery(t, "/cgi-bin/webhook/send", "key", "robot-key")
+	srv.AssertBody(t, "/cgi-bin/webhook/send", `{
+		"msgtype": "text",
+		"text": {"content": "hello", "mentioned_list": ["@all"]}
+	}`)
+	assert.Empty(t, srv.LastRequest("/cgi-bin/webhook/send").AccessToken)
+	assert.Equal(t, 0, srv.TokenCalls())
+
+	// 图片的 base64 与 md5 由SDK计算
+	_, err = bot.SendImage(ctx, []byte("hello"))
+	require.NoError(t, err)
+	srv.AssertBody(t, "/cgi-bin/webhook/send", `{
+		"msgtype": "image",
+		"image": {"base64": "aGVsbG8=", "md5": "5d41402abc4b2a76b9719d911017c592"}
+	}`)
+
+	resp, err := bot.UploadMediaFromReader(ctx, webhooktypes.MediaTypeFile, bytes.NewReader([]byte("report")), "report.txt")
+	require.NoError(t, err)
+	assert.Equal(t, "media-1", resp.MediaID)
+	srv.AssertQuery(t, "/cgi-bin/webhook/upload_media", "type", "file")
+	srv.AssertQuery(t, "/cgi-bin/webhook/upload_media", "key", "robot-key")
+}
+
+func TestWebhook_Validation(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+
+	_, err := wecom.NewWebhook("")
+	assert.ErrorIs(t, err, config.ErrMissingWebhookKey)
+
+	bot, err := wecom.NewWebhook("robot-key", config.WithBaseURL(srv.URL))
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = bot.SendText(ctx, strings.Repeat("a", webhooktypes.MaxTextBytes+1))
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	_, err = bot.SendMarkdown(ctx, strings.Repeat("a", webhooktypes.MaxMarkdownBytes+1))
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	_, err = bot.SendImage(ctx, make([]byte, webhooktypes.MaxImageBytes+1))
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	articles := make([]webhooktypes.NewsArticle, webhooktypes.MaxNewsArticles+1)
+	for i := range articles {
+		articles[i] = webhooktypes.NewsArticle{Title: "title", URL: "https://example.com"}
+	}
+	_, err = bot.SendNews(ctx, articles...)
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	_, err = bot.SendTemplateCard(ctx, &message.TemplateCardMessage{CardType: message.TemplateCardTypeButtonInteraction})
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	_, err = bot.UploadMediaFromReader(ctx, webhooktypes.MediaTypeVoice, bytes.NewReader(make([]byte, webhooktypes.MaxVoiceBytes+1)), "voice.amr")
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	_, err = bot.UploadMediaFromReader(ctx, webhooktypes.MediaTypeFile, bytes.NewReader([]byte("tiny")), "tiny.txt")
+	assert.ErrorIs(t, err, webhook.ErrInvalidMessage)
+
+	// 校验失败时不发起请求
+	assert.Empty(t, srv.Requests())
+}
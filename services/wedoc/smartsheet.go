@@ -2,6 +2,7 @@ package wedoc
 
 import (
 	"context"
+	"iter"
 
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/types/common"
@@ -55,6 +56,24 @@ func (s *Service) GetRecords(ctx context.Context, req *wedoc.GetRecordsRequest)
 	return client.PostAndUnmarshal[wedoc.GetRecordsResponse](s.client, ctx, getRecordsURL, req)
 }
 
+// AllRecords 遍历子表中符合条件的全部记录
+// 自动处理 offset/has_more/next 翻页，出现错误或 context 取消时迭代结束
+func (s *Service) AllRecords(ctx context.Context, req *wedoc.GetRecordsRequest) iter.Seq2[wedoc.Record, error] {
+	r := *req
+	return client.Paginate(ctx, r.Offset, func(ctx context.Context, offset uint32) (*client.Page[uint32, wedoc.Record], error) {
+		r.Offset = offset
+		resp, err := s.GetRecords(ctx, &r)
+		if err != nil {
+			return nil, err
+		}
+		return &client.Page[uint32, wedoc.Record]{
+			Items:   resp.Records,
+			Next:    resp.Next,
+			HasMore: resp.HasMore,
+		}, nil
+	})
+}
+
 // UpdateRecords 更新记录
 // 本接口用于更新 Smartsheet 中的某个子表里的一行或多行记录
 func (s *Service) UpdateRecords(ctx context.Context, req *wedoc.UpdateRecordsRequest) (*wedoc.UpdateRecordsResponse, error) {
@@ -2,6 +2,7 @@ package wedrive
 
 import (
 	"context"
+	"io"
 
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/types/common"
@@ -28,6 +29,21 @@ func (s *Service) DownloadFile(ctx context.Context, req *wedrive.FileDownloadReq
 	return client.PostAndUnmarshal[wedrive.FileDownloadResponse](s.client, ctx, "/cgi-bin/wedrive/file_download", req)
 }
 
+// DownloadFileTo 获取文件下载地址并将文件流式写入 w
+// 使用 DownloadFile 返回的地址与 Cookie 下载，大文件自动拆分为多个 Range 请求并在网络错误时断点续传
+func (s *Service) DownloadFileTo(ctx context.Context, req *wedrive.FileDownloadRequest, w io.Writer) (*common.MediaInfo, error) {
+	resp, err := s.DownloadFile(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	mr := &client.MediaRequest{URL: resp.DownloadURL}
+	if resp.CookieName != "" {
+		mr.Headers = map[string]string{"Cookie": resp.CookieName + "=" + resp.CookieValue}
+	}
+	return s.client.DownloadMedia(ctx, mr, w)
+}
+
 // UploadInit 分块上传初始化
 func (s *Service) UploadInit(ctx context.Context, req *wedrive.FileUploadInitRequest) (*wedrive.FileUploadInitResponse, error) {
 	return client.PostAndUnmarshal[wedrive.FileUploadInitResponse](s.client, ctx, "/cgi-bin/wedrive/file_upload_init", req)
@@ -0,0 +1,38 @@
+package wedrive_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	wedrivetypes "github.com/shuaidd/wecom-core/types/wedrive"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestDownloadFileTo(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/wedrive/file_download", map[string]any{
+		"errcode":      0,
+		"download_url": srv.URL + "/wedrive/download?sign=abc",
+		"cookie_name":  "wedrive_ticket",
+		"cookie_value": "ticket-1",
+	})
+	srv.StubMedia("/wedrive/download", "report.xlsx", []byte("report content"))
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	info, err := client.Wedrive.DownloadFileTo(context.Background(), &wedrivetypes.FileDownloadRequest{FileID: "file-1"}, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, "report.xlsx", info.Filename)
+	assert.Equal(t, "report content", buf.String())
+
+	req := srv.LastRequest("/wedrive/download")
+	assert.Equal(t, "wedrive_ticket=ticket-1", req.Header.Get("Cookie"))
+	assert.Empty(t, req.AccessToken)
+}
@@ -0,0 +1,80 @@
+package suite
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/shuaidd/wecom-core/types/common"
+	"github.com/shuaidd/wecom-core/types/suite"
+)
+
+// installURL 第三方应用授权安装页
+const installURL = "https://open.work.weixin.qq.com/3rdapp/install"
+
+// GetPreAuthCode 获取预授权码
+// 文档: https://developer.work.weixin.qq.com/document/path/90601
+func (s *Suite) GetPreAuthCode(ctx context.Context) (*suite.GetPreAuthCodeResponse, error) {
+	return SuiteGetAndUnmarshal[suite.GetPreAuthCodeResponse](s, ctx, "/cgi-bin/service/get_pre_auth_code", nil)
+}
+
+// SetSessionInfo 设置授权配置
+// 文档: https://developer.work.weixin.qq.com/document/path/90602
+func (s *Suite) SetSessionInfo(ctx context.Context, req *suite.SetSessionInfoRequest) error {
+	_, err := SuitePostAndUnmarshal[common.Response](s, ctx, "/cgi-bin/service/set_session_info", req)
+	return err
+}
+
+// InstallURL 生成引导企业管理员授权安装应用的链接
+func (s *Suite) InstallURL(preAuthCode, redirectURI, state string) string {
+	query := url.Values{}
+	query.Set("suite_id", s.suiteID)
+	query.Set("pre_auth_code", preAuthCode)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("state", state)
+	return installURL + "?" + query.Encode()
+}
+
+// GetPermanentCode 获取企业永久授权码
+// 获取成功后永久授权码会保存到 PermanentCodeStore
+// 文档: https://developer.work.weixin.qq.com/document/path/90603
+func (s *Suite) GetPermanentCode(ctx context.Context, authCode string) (*suite.GetPermanentCodeResponse, error) {
+	req := &suite.GetPermanentCodeRequest{
+		AuthCode: authCode,
+	}
+	resp, err := SuitePostAndUnmarshal[suite.GetPermanentCodeResponse](s, ctx, "/cgi-bin/service/get_permanent_code", req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.codes.SetPermanentCode(ctx, s.suiteID, resp.AuthCorpInfo.CorpID, resp.PermanentCode); err != nil {
+		return nil, err
+	}
+	// 重新授权时丢弃旧的企业客户端
+	s.removeCorp(resp.AuthCorpInfo.CorpID)
+	return resp, nil
+}
+
+// GetAuthInfo 获取企业授权信息
+// 使用 PermanentCodeStore 中保存的永久授权码
+// 文档: https://developer.work.weixin.qq.com/document/path/90604
+func (s *Suite) GetAuthInfo(ctx context.Context, corpID string) (*suite.GetAuthInfoResponse, error) {
+	code, err := s.codes.GetPermanentCode(ctx, s.suiteID, corpID)
+	if err != nil {
+		return nil, err
+	}
+	req := &suite.GetAuthInfoRequest{
+		AuthCorpID:    corpID,
+		PermanentCode: code,
+	}
+	return SuitePostAndUnmarshal[suite.GetAuthInfoResponse](s, ctx, "/cgi-bin/service/get_auth_info", req)
+}
+
+// GetCorpToken 获取企业凭证
+// 一般无需直接调用，Corp 返回的企业客户端会自动获取并缓存
+// 文档: https://developer.work.weixin.qq.com/document/path/90605
+func (s *Suite) GetCorpToken(ctx context.Context, corpID, permanentCode string) (*suite.GetCorpTokenResponse, error) {
+	req := &suite.GetCorpTokenRequest{
+		AuthCorpID:    corpID,
+		PermanentCode: permanentCode,
+	}
+	return SuitePostAndUnmarshal[suite.GetCorpTokenResponse](s, ctx, "/cgi-bin/service/get_corp_token", req)
+}
@@ -0,0 +1,198 @@
+package suite
+
+import (
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/shuaidd/wecom-core/pkg/callback"
+	"github.com/shuaidd/wecom-core/pkg/logger"
+)
+
+// maxBodySize 回调请求体的最大长度
+const maxBodySize = 1 << 20
+
+// 指令回调类型
+const (
+	// InfoTypeSuiteTicket 推送suite_ticket
+	InfoTypeSuiteTicket = "suite_ticket"
+	// InfoTypeCreateAuth 授权成功通知
+	InfoTypeCreateAuth = "create_auth"
+	// InfoTypeChangeAuth 变更授权通知
+	InfoTypeChangeAuth = "change_auth"
+	// InfoTypeCancelAuth 取消授权通知
+	InfoTypeCancelAuth = "cancel_auth"
+	// InfoTypeResetPermanentCode 重置永久授权码通知
+	InfoTypeResetPermanentCode = "reset_permanent_code"
+)
+
+// Event 指令回调事件
+type Event struct {
+	// SuiteID 第三方应用的SuiteId
+	SuiteID string `xml:"SuiteId"`
+	// InfoType 指令类型
+	InfoType string `xml:"InfoType"`
+	// TimeStamp 时间戳
+	TimeStamp int64 `xml:"TimeStamp"`
+	// SuiteTicket Ticket内容（仅 suite_ticket）
+	SuiteTicket string `xml:"SuiteTicket"`
+	// AuthCode 临时授权码（create_auth、reset_permanent_code）
+	AuthCode string `xml:"AuthCode"`
+	// AuthCorpID 授权方的corpid（change_auth、cancel_auth、reset_permanent_code）
+	AuthCorpID string `xml:"AuthCorpId"`
+	// State 构造授权链接指定的state参数（仅 create_auth）
+	State string `xml:"State"`
+
+	// Raw 解密后的原始消息明文
+	Raw []byte `xml:"-"`
+}
+
+// Decode 将原始消息解析到 v，用于读取 Event 未包含的字段
+func (e *Event) Decode(v any) error {
+	return xml.Unmarshal(e.Raw, v)
+}
+
+// EventHandler 指令回调处理函数
+// 返回错误时响应非 200，企业微信会重试推送
+type EventHandler func(ctx context.Context, event *Event) error
+
+// CallbackHandler 第三方应用指令回调 http.Handler
+// 内置处理：suite_ticket 保存到 TicketStore；create_auth、reset_permanent_code 使用临时授权码换取永久授权码
+// 并保存到 PermanentCodeStore；cancel_auth 删除永久授权码。内置处理完成后再分发到注册的处理函数。
+type CallbackHandler struct {
+	suite  *Suite
+	crypto *callback.Crypto
+	logger logger.Logger
+
+	// mu 保护路由表
+	mu sync.RWMutex
+	// handlers 按 InfoType 注册的处理函数
+	handlers map[string]EventHandler
+	// fallback 未匹配路由时的处理函数
+	fallback EventHandler
+}
+
+// NewCallbackHandler 创建指令回调处理器，需要通过 WithCallback 配置 Token 与 EncodingAESKey
+func (s *Suite) NewCallbackHandler() (*CallbackHandler, error) {
+	// 验证URL时 ReceiveID 为服务商 CorpID，推送指令时为 SuiteID，因此不校验 ReceiveID
+	crypto, err := callback.NewCrypto(s.token, s.encodingAESKey, "")
+	if err != nil {
+		return nil, err
+	}
+	h := &CallbackHandler{
+		suite:    s,
+		crypto:   crypto,
+		logger:   s.cfg.Logger,
+		handlers: make(map[string]EventHandler),
+	}
+	if h.logger == nil {
+		h.logger = logger.NewNoopLogger()
+	}
+	return h, nil
+}
+
+// Handle 注册指令回调处理函数，如 InfoTypeCreateAuth
+func (h *CallbackHandler) Handle(infoType string, fn EventHandler) *CallbackHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.handlers[infoType] = fn
+	return h
+}
+
+// HandleDefault 注册未匹配任何路由时的处理函数
+func (h *CallbackHandler) HandleDefault(fn EventHandler) *CallbackHandler {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.fallback = fn
+	return h
+}
+
+// ServeHTTP 实现 http.Handler
+// GET 请求用于验证回调URL，POST 请求用于接收指令，处理成功时响应 success
+func (h *CallbackHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	signature, timestamp, nonce := q.Get("msg_signature"), q.Get("timestamp"), q.Get("nonce")
+
+	switch r.Method {
+	case http.MethodGet:
+		plain, err := h.crypto.VerifyURL(signature, timestamp, nonce, q.Get("echostr"))
+		if err != nil {
+			h.logger.Warn("Suite callback URL verification failed",
+				logger.F("error", err))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write(plain)
+	case http.MethodPost:
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		plain, err := h.crypto.DecryptMsg(signature, timestamp, nonce, body)
+		if err != nil {
+			h.logger.Warn("Failed to decrypt suite callback",
+				logger.F("error", err))
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		event := &Event{Raw: plain}
+		if err := xml.Unmarshal(plain, event); err != nil {
+			h.logger.Warn("Failed to parse suite callback",
+				logger.F("error", err))
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if err := h.dispatch(r.Context(), event); err != nil {
+			h.logger.Error("Suite callback handler failed",
+				logger.F("info_type", event.InfoType),
+				logger.F("auth_corp_id", event.AuthCorpID),
+				logger.F("error", err))
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte("success"))
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// dispatch 执行内置处理后分发到注册的处理函数
+func (h *CallbackHandler) dispatch(ctx context.Context, event *Event) error {
+	s := h.suite
+	switch event.InfoType {
+	case InfoTypeSuiteTicket:
+		if err := s.tickets.SetTicket(ctx, s.suiteID, event.SuiteTicket); err != nil {
+			return err
+		}
+	case InfoTypeCreateAuth, InfoTypeResetPermanentCode:
+		resp, err := s.GetPermanentCode(ctx, event.AuthCode)
+		if err != nil {
+			return err
+		}
+		if event.AuthCorpID == "" {
+			event.AuthCorpID = resp.AuthCorpInfo.CorpID
+		}
+	case InfoTypeCancelAuth:
+		if err := s.codes.DeletePermanentCode(ctx, s.suiteID, event.AuthCorpID); err != nil {
+			return err
+		}
+		s.removeCorp(event.AuthCorpID)
+	}
+
+	h.mu.RLock()
+	fn, ok := h.handlers[event.InfoType]
+	if !ok {
+		fn = h.fallback
+	}
+	h.mu.RUnlock()
+
+	if fn == nil {
+		return nil
+	}
+	return fn(ctx, event)
+}
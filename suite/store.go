@@ -0,0 +1,134 @@
+package suite
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/shuaidd/wecom-core/pkg/cache"
+)
+
+var (
+	// ErrTicketNotFound 尚未收到 suite_ticket 推送
+	ErrTicketNotFound = errors.New("suite: suite_ticket not found")
+
+	// ErrPermanentCodeNotFound 企业未授权或永久授权码未保存
+	ErrPermanentCodeNotFound = errors.New("suite: permanent_code not found")
+)
+
+const (
+	// TicketTTL suite_ticket 有效期，企业微信每十分钟推送一次，每个 ticket 有效期 30 分钟
+	TicketTTL = 30 * time.Minute
+)
+
+// TicketStore suite_ticket 存储
+// 多实例部署时应使用共享存储，保证接收回调的实例与调用接口的实例读取到同一个 ticket
+type TicketStore interface {
+	// GetTicket 获取应用最新的 suite_ticket，不存在时返回 ErrTicketNotFound
+	GetTicket(ctx context.Context, suiteID string) (string, error)
+	// SetTicket 保存应用最新的 suite_ticket
+	SetTicket(ctx context.Context, suiteID, ticket string) error
+}
+
+// PermanentCodeStore 永久授权码存储
+// 永久授权码只在授权时返回一次，应持久化保存
+type PermanentCodeStore interface {
+	// GetPermanentCode 获取企业的永久授权码，不存在时返回 ErrPermanentCodeNotFound
+	GetPermanentCode(ctx context.Context, suiteID, corpID string) (string, error)
+	// SetPermanentCode 保存企业的永久授权码
+	SetPermanentCode(ctx context.Context, suiteID, corpID, code string) error
+	// DeletePermanentCode 删除企业的永久授权码（取消授权时调用）
+	DeletePermanentCode(ctx context.Context, suiteID, corpID string) error
+}
+
+// MemoryStore 基于内存的 suite_ticket 与永久授权码存储，仅适用于单实例或测试
+type MemoryStore struct {
+	mu      sync.RWMutex
+	tickets map[string]string
+	codes   map[string]string
+}
+
+// NewMemoryStore 创建内存存储
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tickets: make(map[string]string),
+		codes:   make(map[string]string),
+	}
+}
+
+// GetTicket 获取 suite_ticket
+func (s *MemoryStore) GetTicket(ctx context.Context, suiteID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ticket, ok := s.tickets[suiteID]
+	if !ok {
+		return "", ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+// SetTicket 保存 suite_ticket
+func (s *MemoryStore) SetTicket(ctx context.Context, suiteID, ticket string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tickets[suiteID] = ticket
+	return nil
+}
+
+// GetPermanentCode 获取永久授权码
+func (s *MemoryStore) GetPermanentCode(ctx context.Context, suiteID, corpID string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	code, ok := s.codes[suiteID+":"+corpID]
+	if !ok {
+		return "", ErrPermanentCodeNotFound
+	}
+	return code, nil
+}
+
+// SetPermanentCode 保存永久授权码
+func (s *MemoryStore) SetPermanentCode(ctx context.Context, suiteID, corpID, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.codes[suiteID+":"+corpID] = code
+	return nil
+}
+
+// DeletePermanentCode 删除永久授权码
+func (s *MemoryStore) DeletePermanentCode(ctx context.Context, suiteID, corpID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.codes, suiteID+":"+corpID)
+	return nil
+}
+
+// CacheStore 基于 cache.Cache 的 suite_ticket 存储，可复用 Token 缓存使用的 Redis 等共享存储
+// 永久授权码需要持久化保存，不建议存放在缓存中
+type CacheStore struct {
+	cache cache.Cache
+}
+
+// NewCacheStore 创建基于缓存的 suite_ticket 存储
+func NewCacheStore(c cache.Cache) *CacheStore {
+	return &CacheStore{cache: c}
+}
+
+// GetTicket 获取 suite_ticket
+func (s *CacheStore) GetTicket(ctx context.Context, suiteID string) (string, error) {
+	ticket, expireAt, err := s.cache.Get(ctx, ticketCacheKey(suiteID))
+	if err != nil || ticket == "" || !time.Now().Before(expireAt) {
+		return "", ErrTicketNotFound
+	}
+	return ticket, nil
+}
+
+// SetTicket 保存 suite_ticket
+func (s *CacheStore) SetTicket(ctx context.Context, suiteID, ticket string) error {
+	return s.cache.Set(ctx, ticketCacheKey(suiteID), ticket, time.Now().Add(TicketTTL))
+}
+
+// ticketCacheKey suite_ticket 的缓存key
+func ticketCacheKey(suiteID string) string {
+	return "wecom:suite_ticket:" + suiteID
+}
@@ -0,0 +1,428 @@
+// Package suite 提供第三方应用（服务商）模式的客户端
+//
+// Suite 负责 suite_access_token、provider_access_token 的获取与缓存，处理 suite_ticket、
+// 授权成功、取消授权等指令回调，并通过可插拔的存储保存 suite_ticket 与永久授权码。
+// 对已授权的企业，Corp 返回一个普通的 *wecom.Client，其 access_token 通过 get_corp_token 获取，
+// 因此所有业务服务均可直接用于第三方应用。
+//
+// 示例：
+//
+//	s, err := suite.New("wwddddccc7775555aaa", "suite-secret",
+//	    suite.WithCallback(token, encodingAESKey),
+//	    suite.WithTicketStore(suite.NewCacheStore(redisCache)),
+//	    suite.WithPermanentCodeStore(dbStore),
+//	    suite.WithConfig(config.WithLogger(log), config.WithCache(redisCache)),
+//	)
+//	h, _ := s.NewCallbackHandler()
+//	http.Handle("/wecom/suite/callback", h)
+//
+//	corp, err := s.Corp(ctx, "wxf8b4f85f3a794e77")
+//	user, err := corp.Contact.GetUser(ctx, "zhangsan")
+package suite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/internal/auth"
+	"github.com/shuaidd/wecom-core/internal/client"
+	wecomerrors "github.com/shuaidd/wecom-core/internal/errors"
+	"github.com/shuaidd/wecom-core/internal/retry"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
+	"github.com/shuaidd/wecom-core/types/suite"
+)
+
+// 第三方应用凭证相关错误码
+const (
+	// errCodeInvalidSuiteToken 不合法的 suite_access_token
+	errCodeInvalidSuiteToken = 40082
+	// errCodeSuiteTokenExpired suite_access_token 已过期
+	errCodeSuiteTokenExpired = 42009
+)
+
+var (
+	// ErrMissingSuite 缺少 SuiteID 或 SuiteSecret
+	ErrMissingSuite = errors.New("suite: suiteID and suiteSecret are required")
+
+	// ErrMissingProvider 未配置服务商 CorpID 与 ProviderSecret
+	ErrMissingProvider = errors.New("suite: provider corpID and providerSecret are required")
+
+	// ErrSuiteClosed 第三方应用客户端已关闭
+	ErrSuiteClosed = errors.New("suite: closed")
+)
+
+// Option 第三方应用客户端选项
+type Option func(*Suite)
+
+// WithTicketStore 设置 suite_ticket 存储，默认为内存存储
+func WithTicketStore(store TicketStore) Option {
+	return func(s *Suite) {
+		s.tickets = store
+	}
+}
+
+// WithPermanentCodeStore 设置永久授权码存储，默认为内存存储
+func WithPermanentCodeStore(store PermanentCodeStore) Option {
+	return func(s *Suite) {
+		s.codes = store
+	}
+}
+
+// WithProvider 设置服务商 CorpID 与 ProviderSecret，用于获取 provider_access_token
+func WithProvider(corpID, providerSecret string) Option {
+	return func(s *Suite) {
+		s.providerCorpID = corpID
+		s.providerSecret = providerSecret
+	}
+}
+
+// WithCallback 设置指令回调的 Token 与 EncodingAESKey
+func WithCallback(token, encodingAESKey string) Option {
+	return func(s *Suite) {
+		s.token = token
+		s.encodingAESKey = encodingAESKey
+	}
+}
+
+// WithConfig 设置SDK配置（BaseURL、日志、缓存、重试、拦截器等）
+// 这些配置同时用于 Corp 返回的企业客户端
+func WithConfig(opts ...config.Option) Option {
+	return func(s *Suite) {
+		s.opts = append(s.opts, opts...)
+	}
+}
+
+// Suite 第三方应用客户端
+type Suite struct {
+	suiteID     string
+	suiteSecret string
+	// providerCorpID 服务商的 CorpID
+	providerCorpID string
+	// providerSecret 服务商的 secret
+	providerSecret string
+	// token 指令回调 Token
+	token string
+	// encodingAESKey 指令回调 EncodingAESKey
+	encodingAESKey string
+
+	tickets TicketStore
+	codes   PermanentCodeStore
+
+	// opts SDK配置选项
+	opts []config.Option
+	// cfg SDK配置
+	cfg *config.Config
+	// httpClient 不携带 access_token 的HTTP客户端
+	httpClient *client.Client
+	// suiteTokens suite_access_token 管理器
+	suiteTokens *auth.TokenManager
+	// providerTokens provider_access_token 管理器
+	providerTokens *auth.TokenManager
+
+	mu sync.Mutex
+	// corps 已创建的企业客户端
+	corps map[string]*wecom.Client
+	// versions 企业授权的版本，取消授权时递增，用于丢弃创建期间已取消授权的企业客户端
+	versions map[string]uint64
+	closed   bool
+}
+
+// New 创建第三方应用客户端
+func New(suiteID, suiteSecret string, opts ...Option) (*Suite, error) {
+	if suiteID == "" || suiteSecret == "" {
+		return nil, ErrMissingSuite
+	}
+
+	s := &Suite{
+		suiteID:     suiteID,
+		suiteSecret: suiteSecret,
+		corps:       make(map[string]*wecom.Client),
+		versions:    make(map[string]uint64),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.tickets == nil || s.codes == nil {
+		store := NewMemoryStore()
+		if s.tickets == nil {
+			s.tickets = store
+		}
+		if s.codes == nil {
+			s.codes = store
+		}
+	}
+
+	s.cfg = config.New(s.opts...)
+	if s.cfg.Timeout <= 0 {
+		return nil, config.ErrInvalidTimeout
+	}
+	if s.cfg.MaxRetries < 0 {
+		return nil, config.ErrInvalidMaxRetries
+	}
+
+	var retryPolicy wecomretry.Policy = retry.NewPolicy(s.cfg.MaxRetries, s.cfg.InitialBackoff, s.cfg.MaxBackoff)
+	if s.cfg.RetryPolicy != nil {
+		retryPolicy = s.cfg.RetryPolicy
+	}
+	retryExecutor := retry.NewExecutor(credentialPolicy{retryPolicy}, s.cfg.Logger)
+	s.httpClient = client.New(s.cfg.BaseURL, s.cfg.Timeout, s.cfg.Logger, nil, retryExecutor)
+	if s.cfg.Debug {
+		s.httpClient.SetDebug(true)
+	}
+	if s.cfg.Transport != nil {
+		s.httpClient.SetTransport(s.cfg.Transport)
+	}
+	if s.cfg.Observer != nil {
+		s.httpClient.SetObserver(s.cfg.Observer)
+	}
+	if s.cfg.Redactor != nil {
+		s.httpClient.SetRedactor(s.cfg.Redactor)
+	}
+	for _, interceptor := range s.cfg.RequestInterceptors {
+		s.httpClient.AddRequestInterceptor(interceptor)
+	}
+	for _, interceptor := range s.cfg.ResponseInterceptors {
+		s.httpClient.AddResponseInterceptor(interceptor)
+	}
+	for _, interceptor := range s.cfg.AfterResponseInterceptors {
+		s.httpClient.AddAfterResponseInterceptor(interceptor)
+	}
+
+	s.suiteTokens = s.newTokenManager("suite:"+suiteID, s.fetchSuiteToken)
+	s.providerTokens = s.newTokenManager("provider:"+s.providerCorpID, s.fetchProviderToken)
+
+	return s, nil
+}
+
+// newTokenManager 创建使用自定义获取函数的 token 管理器，复用SDK的缓存与刷新锁
+func (s *Suite) newTokenManager(key string, fetcher auth.TokenFetcher) *auth.TokenManager {
+	tm := auth.NewTokenManager(key, "", s.cfg.BaseURL, s.cfg.Cache, s.cfg.Logger)
+	if s.cfg.Locker != nil {
+		tm.SetLocker(s.cfg.Locker)
+	}
+	if s.cfg.Observer != nil {
+		tm.SetObserver(s.cfg.Observer)
+	}
+	tm.SetTokenFetcher(fetcher)
+	return tm
+}
+
+// SuiteID 返回第三方应用的 SuiteID
+func (s *Suite) SuiteID() string {
+	return s.suiteID
+}
+
+// Corp 返回已授权企业的客户端
+// 企业客户端的 access_token 使用保存的永久授权码通过 get_corp_token 获取，并按企业缓存、自动刷新
+func (s *Suite) Corp(ctx context.Context, corpID string) (*wecom.Client, error) {
+	for {
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return nil, ErrSuiteClosed
+		}
+		if c, ok := s.corps[corpID]; ok {
+			s.mu.Unlock()
+			return c, nil
+		}
+		version := s.versions[corpID]
+		s.mu.Unlock()
+
+		// 在锁外读取永久授权码并创建客户端，避免阻塞其他企业
+		if _, err := s.codes.GetPermanentCode(ctx, s.suiteID, corpID); err != nil {
+			return nil, err
+		}
+		c, err := s.buildCorp(corpID)
+		if err != nil {
+			return nil, err
+		}
+
+		s.mu.Lock()
+		existing, exists := s.corps[corpID]
+		switch {
+		case s.closed:
+			s.mu.Unlock()
+			_ = c.Close()
+			return nil, ErrSuiteClosed
+		case exists:
+			// 其他 goroutine 已创建
+			s.mu.Unlock()
+			_ = c.Close()
+			return existing, nil
+		case s.versions[corpID] != version:
+			// 创建期间企业取消授权，重新读取永久授权码
+			s.mu.Unlock()
+			_ = c.Close()
+			continue
+		}
+		s.corps[corpID] = c
+		s.mu.Unlock()
+		return c, nil
+	}
+}
+
+// buildCorp 创建企业客户端
+func (s *Suite) buildCorp(corpID string) (*wecom.Client, error) {
+	opts := append(append([]config.Option(nil), s.opts...),
+		config.WithCorpID(corpID),
+		config.WithTokenFetcher(func(ctx context.Context, agentKey string) (string, int, error) {
+			code, err := s.codes.GetPermanentCode(ctx, s.suiteID, corpID)
+			if err != nil {
+				return "", 0, err
+			}
+			resp, err := s.GetCorpToken(ctx, corpID, code)
+			if err != nil {
+				return "", 0, err
+			}
+			return resp.AccessToken, resp.ExpiresIn, nil
+		}),
+	)
+	return wecom.New(opts...)
+}
+
+// removeCorp 移除企业客户端（取消授权时调用）
+func (s *Suite) removeCorp(corpID string) {
+	s.mu.Lock()
+	s.versions[corpID]++
+	c, ok := s.corps[corpID]
+	delete(s.corps, corpID)
+	s.mu.Unlock()
+
+	if ok {
+		_ = c.Close()
+	}
+}
+
+// Close 关闭所有企业客户端，关闭后 Corp 返回 ErrSuiteClosed
+func (s *Suite) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	corps := s.corps
+	s.corps = make(map[string]*wecom.Client)
+	s.mu.Unlock()
+
+	for _, c := range corps {
+		_ = c.Close()
+	}
+	return nil
+}
+
+// SuiteAccessToken 获取 suite_access_token
+func (s *Suite) SuiteAccessToken(ctx context.Context) (string, error) {
+	return s.suiteTokens.GetToken(ctx)
+}
+
+// ProviderAccessToken 获取 provider_access_token
+func (s *Suite) ProviderAccessToken(ctx context.Context) (string, error) {
+	return s.providerTokens.GetToken(ctx)
+}
+
+// fetchSuiteToken 使用最新的 suite_ticket 获取 suite_access_token
+func (s *Suite) fetchSuiteToken(ctx context.Context, _ string) (string, int, error) {
+	ticket, err := s.tickets.GetTicket(ctx, s.suiteID)
+	if err != nil {
+		return "", 0, err
+	}
+	resp, err := client.PostAndUnmarshal[suite.GetSuiteTokenResponse](s.httpClient, ctx, "/cgi-bin/service/get_suite_token", &suite.GetSuiteTokenRequest{
+		SuiteID:     s.suiteID,
+		SuiteSecret: s.suiteSecret,
+		SuiteTicket: ticket,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get suite_access_token: %w", err)
+	}
+	return resp.SuiteAccessToken, resp.ExpiresIn, nil
+}
+
+// fetchProviderToken 获取 provider_access_token
+func (s *Suite) fetchProviderToken(ctx context.Context, _ string) (string, int, error) {
+	if s.providerCorpID == "" || s.providerSecret == "" {
+		return "", 0, ErrMissingProvider
+	}
+	resp, err := client.PostAndUnmarshal[suite.GetProviderTokenResponse](s.httpClient, ctx, "/cgi-bin/service/get_provider_token", &suite.GetProviderTokenRequest{
+		CorpID:         s.providerCorpID,
+		ProviderSecret: s.providerSecret,
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to get provider_access_token: %w", err)
+	}
+	return resp.ProviderAccessToken, resp.ExpiresIn, nil
+}
+
+// SuitePostAndUnmarshal 使用 suite_access_token 发送 POST 请求并解析响应
+// 用于SDK尚未封装的第三方应用接口
+func SuitePostAndUnmarshal[T any](s *Suite, ctx context.Context, path string, body any) (*T, error) {
+	req := client.NewRequest(client.MethodPost, path).SetBody(body)
+	return doWithToken[T](s, ctx, s.suiteTokens, "suite_access_token", req)
+}
+
+// SuiteGetAndUnmarshal 使用 suite_access_token 发送 GET 请求并解析响应
+func SuiteGetAndUnmarshal[T any](s *Suite, ctx context.Context, path string, query url.Values) (*T, error) {
+	req := client.NewRequest(client.MethodGet, path)
+	for k, vs := range query {
+		for _, v := range vs {
+			req.AddQuery(k, v)
+		}
+	}
+	return doWithToken[T](s, ctx, s.suiteTokens, "suite_access_token", req)
+}
+
+// ProviderPostAndUnmarshal 使用 provider_access_token 发送 POST 请求并解析响应
+// 用于服务商级别的接口，如 get_login_info、get_register_code
+func ProviderPostAndUnmarshal[T any](s *Suite, ctx context.Context, path string, body any) (*T, error) {
+	req := client.NewRequest(client.MethodPost, path).SetBody(body)
+	return doWithToken[T](s, ctx, s.providerTokens, "provider_access_token", req)
+}
+
+// doWithToken 携带指定凭证发送请求，凭证失效时刷新后重试一次
+func doWithToken[T any](s *Suite, ctx context.Context, tm *auth.TokenManager, param string, req *client.Request) (*T, error) {
+	token, err := tm.GetToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	req.SetQuery(param, token)
+	resp, err := client.DoAndUnmarshal[T](s.httpClient, ctx, req)
+	if err == nil || !isCredentialExpired(err) {
+		return resp, err
+	}
+
+	if err := tm.RefreshTokenIfStale(ctx, "", token); err != nil {
+		return nil, err
+	}
+	if token, err = tm.GetToken(ctx); err != nil {
+		return nil, err
+	}
+	req.SetQuery(param, token)
+	return client.DoAndUnmarshal[T](s.httpClient, ctx, req)
+}
+
+// credentialPolicy 不重试凭证失效错误的重试策略
+// suite 客户端不持有 TokenSource，重试执行器只能使用同一个失效的凭证重试，
+// 因此凭证失效时立即返回，由 doWithToken 刷新凭证后重试
+type credentialPolicy struct {
+	wecomretry.Policy
+}
+
+// Retry 实现 wecomretry.Policy
+func (p credentialPolicy) Retry(ctx context.Context, a *wecomretry.Attempt) (time.Duration, bool) {
+	if isCredentialExpired(a.Err) {
+		return 0, false
+	}
+	return p.Policy.Retry(ctx, a)
+}
+
+// isCredentialExpired 判断是否为凭证失效错误
+func isCredentialExpired(err error) bool {
+	code := wecomerrors.GetErrorCode(err)
+	return wecomerrors.IsTokenExpired(err) || code == errCodeInvalidSuiteToken || code == errCodeSuiteTokenExpired
+}
@@ -0,0 +1,303 @@
+package suite
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/pkg/callback"
+)
+
+const (
+	testSuiteID        = "wwsuite"
+	testSuiteSecret    = "suite-secret"
+	testToken          = "QDG6eK"
+	testEncodingAESKey = "jWmYm7qr5nMoAUwZRjGtBxmz3KA1tkAj3ykkR6q2B2C"
+)
+
+// fakeServer 模拟第三方应用相关接口
+type fakeServer struct {
+	*httptest.Server
+
+	mu    sync.Mutex
+	calls map[string]int
+	// suiteTokens 已签发的 suite_access_token
+	suiteTokens int
+	// expiredErrCode suite_access_token 失效时返回的错误码，默认 42009
+	expiredErrCode int
+}
+
+func newFakeServer(t *testing.T) *fakeServer {
+	t.Helper()
+	s := &fakeServer{calls: make(map[string]int), expiredErrCode: 42009}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeServer) Calls(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[path]
+}
+
+func (s *fakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[r.URL.Path]++
+
+	var body map[string]string
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	q := r.URL.Query()
+	suiteToken := fmt.Sprintf("suite-token-%d", s.suiteTokens)
+
+	var resp any
+	switch r.URL.Path {
+	case "/cgi-bin/service/get_suite_token":
+		if body["suite_ticket"] != "ticket-1" || body["suite_secret"] != testSuiteSecret {
+			resp = map[string]any{"errcode": 40085, "errmsg": "invalid suite_ticket"}
+			break
+		}
+		s.suiteTokens++
+		resp = map[string]any{"suite_access_token": fmt.Sprintf("suite-token-%d", s.suiteTokens), "expires_in": 7200}
+	case "/cgi-bin/service/get_permanent_code":
+		if q.Get("suite_access_token") != suiteToken {
+			resp = map[string]any{"errcode": s.expiredErrCode, "errmsg": "suite_access_token expired"}
+			break
+		}
+		resp = map[string]any{
+			"access_token":   "corp-token",
+			"expires_in":     7200,
+			"permanent_code": "code-" + body["auth_code"],
+			"auth_corp_info": map[string]any{"corpid": "wwcorp", "corp_name": "测试企业"},
+		}
+	case "/cgi-bin/service/get_corp_token":
+		if q.Get("suite_access_token") != suiteToken || body["permanent_code"] != "code-auth-1" {
+			resp = map[string]any{"errcode": 40084, "errmsg": "invalid permanent_code"}
+			break
+		}
+		resp = map[string]any{"access_token": "corp-token-" + body["auth_corpid"], "expires_in": 7200}
+	case "/cgi-bin/user/get":
+		if q.Get("access_token") != "corp-token-wwcorp" {
+			resp = map[string]any{"errcode": 40014, "errmsg": "invalid access_token"}
+			break
+		}
+		resp = map[string]any{"userid": q.Get("userid"), "name": "张三"}
+	default:
+		resp = map[string]any{"errcode": 404, "errmsg": "not found"}
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// expireSuiteToken 使已签发的 suite_access_token 失效
+func (s *fakeServer) expireSuiteToken() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suiteTokens++
+}
+
+func newTestSuite(t *testing.T, srv *fakeServer) *Suite {
+	t.Helper()
+	s, err := New(testSuiteID, testSuiteSecret,
+		WithCallback(testToken, testEncodingAESKey),
+		WithConfig(config.WithBaseURL(srv.URL), config.WithBackoff(time.Millisecond, time.Millisecond)),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = s.Close() })
+	return s
+}
+
+// postEvent 加密指令并推送到回调处理器
+func postEvent(t *testing.T, h http.Handler, plain string) *httptest.ResponseRecorder {
+	t.Helper()
+	crypto, err := callback.NewCrypto(testToken, testEncodingAESKey, testSuiteID)
+	require.NoError(t, err)
+	body, err := crypto.EncryptMsg([]byte(plain), "1409659589", "263014780")
+	require.NoError(t, err)
+
+	var envelope struct {
+		MsgSignature string `xml:"MsgSignature"`
+	}
+	require.NoError(t, xml.Unmarshal(body, &envelope))
+
+	q := url.Values{}
+	q.Set("msg_signature", envelope.MsgSignature)
+	q.Set("timestamp", "1409659589")
+	q.Set("nonce", "263014780")
+	req := httptest.NewRequest(http.MethodPost, "/callback?"+q.Encode(), strings.NewReader(string(body)))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNew_MissingSuite(t *testing.T) {
+	_, err := New("", "secret")
+	assert.ErrorIs(t, err, ErrMissingSuite)
+}
+
+func TestSuite_AuthorizationFlow(t *testing.T) {
+	srv := newFakeServer(t)
+	s := newTestSuite(t, srv)
+	ctx := context.Background()
+
+	// 尚未收到 suite_ticket
+	_, err := s.SuiteAccessToken(ctx)
+	assert.ErrorIs(t, err, ErrTicketNotFound)
+
+	h, err := s.NewCallbackHandler()
+	require.NoError(t, err)
+
+	var created []*Event
+	h.Handle(InfoTypeCreateAuth, func(ctx context.Context, event *Event) error {
+		created = append(created, event)
+		return nil
+	})
+
+	rec := postEvent(t, h, `<xml><SuiteId><![CDATA[wwsuite]]></SuiteId><InfoType><![CDATA[suite_ticket]]></InfoType><TimeStamp>1403610513</TimeStamp><SuiteTicket><![CDATA[ticket-1]]></SuiteTicket></xml>`)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "success", rec.Body.String())
+
+	token, err := s.SuiteAccessToken(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "suite-token-1", token)
+
+	// 授权成功：自动换取并保存永久授权码
+	rec = postEvent(t, h, `<xml><SuiteId><![CDATA[wwsuite]]></SuiteId><AuthCode><![CDATA[auth-1]]></AuthCode><InfoType><![CDATA[create_auth]]></InfoType><TimeStamp>1403610513</TimeStamp><State><![CDATA[state]]></State></xml>`)
+	assert.Equal(t, "success", rec.Body.String())
+	require.Len(t, created, 1)
+	assert.Equal(t, "wwcorp", created[0].AuthCorpID)
+	assert.Equal(t, "state", created[0].State)
+
+	code, err := s.codes.GetPermanentCode(ctx, testSuiteID, "wwcorp")
+	require.NoError(t, err)
+	assert.Equal(t, "code-auth-1", code)
+
+	// 企业客户端使用 get_corp_token 获取的 access_token
+	corp, err := s.Corp(ctx, "wwcorp")
+	require.NoError(t, err)
+	user, err := corp.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	assert.Equal(t, "张三", user.Name)
+
+	same, err := s.Corp(ctx, "wwcorp")
+	require.NoError(t, err)
+	assert.Same(t, corp, same)
+
+	// 取消授权：删除永久授权码
+	rec = postEvent(t, h, `<xml><SuiteId><![CDATA[wwsuite]]></SuiteId><InfoType><![CDATA[cancel_auth]]></InfoType><TimeStamp>1403610513</TimeStamp><AuthCorpId><![CDATA[wwcorp]]></AuthCorpId></xml>`)
+	assert.Equal(t, "success", rec.Body.String())
+	_, err = s.Corp(ctx, "wwcorp")
+	assert.ErrorIs(t, err, ErrPermanentCodeNotFound)
+}
+
+func TestSuite_RefreshExpiredSuiteToken(t *testing.T) {
+	// 40014/42001 会被重试执行器归类为 token 失效，同样不应使用失效的凭证重试
+	for _, code := range []int{42009, 40082, 40014, 42001} {
+		t.Run(fmt.Sprint(code), func(t *testing.T) {
+			srv := newFakeServer(t)
+			srv.expiredErrCode = code
+			s := newTestSuite(t, srv)
+			ctx := context.Background()
+			require.NoError(t, s.tickets.SetTicket(ctx, testSuiteID, "ticket-1"))
+
+			_, err := s.GetPermanentCode(ctx, "auth-1")
+			require.NoError(t, err)
+
+			// suite_access_token 失效后刷新并重试一次
+			srv.expireSuiteToken()
+			_, err = s.GetPermanentCode(ctx, "auth-1")
+			require.NoError(t, err)
+			assert.Equal(t, 2, srv.Calls("/cgi-bin/service/get_suite_token"))
+			assert.Equal(t, 3, srv.Calls("/cgi-bin/service/get_permanent_code"))
+		})
+	}
+}
+
+func TestSuite_CorpWithoutAuthorization(t *testing.T) {
+	srv := newFakeServer(t)
+	s := newTestSuite(t, srv)
+
+	_, err := s.Corp(context.Background(), "wwunknown")
+	assert.ErrorIs(t, err, ErrPermanentCodeNotFound)
+}
+
+// blockingCodeStore 读取指定企业的永久授权码时阻塞，直到 release 关闭
+type blockingCodeStore struct {
+	*MemoryStore
+	corpID  string
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingCodeStore) GetPermanentCode(ctx context.Context, suiteID, corpID string) (string, error) {
+	if corpID == s.corpID {
+		select {
+		case s.entered <- struct{}{}:
+		default:
+		}
+		<-s.release
+	}
+	return s.MemoryStore.GetPermanentCode(ctx, suiteID, corpID)
+}
+
+func TestSuite_CorpBuildsOutsideLock(t *testing.T) {
+	srv := newFakeServer(t)
+	s := newTestSuite(t, srv)
+	ctx := context.Background()
+
+	store := &blockingCodeStore{MemoryStore: NewMemoryStore(), corpID: "wwslow", entered: make(chan struct{}, 1), release: make(chan struct{})}
+	require.NoError(t, store.SetPermanentCode(ctx, testSuiteID, "wwslow", "code-slow"))
+	require.NoError(t, store.SetPermanentCode(ctx, testSuiteID, "wwcorp", "code-auth-1"))
+	s.codes = store
+
+	slow := make(chan error, 1)
+	go func() {
+		_, err := s.Corp(ctx, "wwslow")
+		slow <- err
+	}()
+	<-store.entered
+
+	// 其他企业不受读取缓慢的企业阻塞
+	corp, err := s.Corp(ctx, "wwcorp")
+	require.NoError(t, err)
+	assert.NotNil(t, corp)
+
+	// 创建期间关闭，新建的客户端被丢弃
+	require.NoError(t, s.Close())
+	close(store.release)
+	assert.ErrorIs(t, <-slow, ErrSuiteClosed)
+	_, err = s.Corp(ctx, "wwcorp")
+	assert.ErrorIs(t, err, ErrSuiteClosed)
+}
+
+func TestSuite_ProviderTokenRequiresConfig(t *testing.T) {
+	srv := newFakeServer(t)
+	s := newTestSuite(t, srv)
+
+	_, err := s.ProviderAccessToken(context.Background())
+	assert.ErrorIs(t, err, ErrMissingProvider)
+}
+
+func TestSuite_InstallURL(t *testing.T) {
+	srv := newFakeServer(t)
+	s := newTestSuite(t, srv)
+
+	u, err := url.Parse(s.InstallURL("pre-code", "https://example.com/auth", "s1"))
+	require.NoError(t, err)
+	assert.Equal(t, "open.work.weixin.qq.com", u.Host)
+	assert.Equal(t, testSuiteID, u.Query().Get("suite_id"))
+	assert.Equal(t, "pre-code", u.Query().Get("pre_auth_code"))
+	assert.Equal(t, "https://example.com/auth", u.Query().Get("redirect_uri"))
+}
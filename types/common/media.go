@@ -0,0 +1,11 @@
+package common
+
+// MediaInfo 流式下载的媒体文件信息
+type MediaInfo struct {
+	// Filename 文件名，取自 Content-Disposition，服务端未返回时为空
+	Filename string
+	// ContentType 文件类型
+	ContentType string
+	// Size 文件总大小（字节），取自 Content-Range 或 Content-Length，未知时为 -1
+	Size int64
+}
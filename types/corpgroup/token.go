@@ -2,6 +2,14 @@ package corpgroup
 
 import "github.com/shuaidd/wecom-core/types/common"
 
+// 业务类型（business_type）
+const (
+	// BusinessTypeCorpGroup 企业互联/局校互联
+	BusinessTypeCorpGroup = 0
+	// BusinessTypeChain 上下游企业
+	BusinessTypeChain = 1
+)
+
 // GetTokenRequest 获取下级/下游企业的access_token请求
 type GetTokenRequest struct {
 	CorpID       string `json:"corpid"`                  // 已授权的下级/下游企业corpid
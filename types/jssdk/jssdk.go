@@ -0,0 +1,20 @@
+package jssdk
+
+// ConfigSignature wx.config 注入配置
+// 可直接序列化为JSON返回给前端使用
+type ConfigSignature struct {
+	AppID     string `json:"appId"`     // 企业微信的corpID
+	Timestamp int64  `json:"timestamp"` // 生成签名的时间戳
+	NonceStr  string `json:"nonceStr"`  // 生成签名的随机串
+	Signature string `json:"signature"` // 签名
+}
+
+// AgentConfigSignature wx.agentConfig 注入配置
+// 可直接序列化为JSON返回给前端使用
+type AgentConfigSignature struct {
+	CorpID    string `json:"corpid"`    // 企业微信的corpid,必须与当前登录的企业一致
+	AgentID   int64  `json:"agentid"`   // 企业微信的应用id
+	Timestamp int64  `json:"timestamp"` // 生成签名的时间戳
+	NonceStr  string `json:"nonceStr"`  // 生成签名的随机串
+	Signature string `json:"signature"` // 签名
+}
@@ -0,0 +1,205 @@
+package kf
+
+import "github.com/shuaidd/wecom-core/types/common"
+
+// SyncMsgRequest 读取消息请求
+type SyncMsgRequest struct {
+	Cursor      string `json:"cursor,omitempty"`       // 上一次调用时返回的next_cursor,第一次拉取可以不填
+	Token       string `json:"token,omitempty"`        // 回调事件返回的token字段,10分钟内有效;可不填,如果不填接口有严格的频率限制
+	Limit       int    `json:"limit,omitempty"`        // 期望请求的数据量,默认值和最大值都为1000
+	VoiceFormat int    `json:"voice_format,omitempty"` // 语音消息类型,0-Amr 1-Silk,默认0
+	OpenKfID    string `json:"open_kfid,omitempty"`    // 指定拉取某个客服账号的消息
+}
+
+// SyncMsgResponse 读取消息响应
+type SyncMsgResponse struct {
+	common.Response
+	NextCursor string        `json:"next_cursor"` // 下次调用带上该值,则从当前的位置继续往后拉
+	HasMore    int           `json:"has_more"`    // 是否还有更多数据,0-否 1-是
+	MsgList    []SyncMessage `json:"msg_list"`    // 消息列表
+}
+
+// 消息来源
+const (
+	MsgOriginCustomer = 3 // 微信客户发送的消息
+	MsgOriginEvent    = 4 // 系统推送的事件消息
+	MsgOriginServicer = 5 // 接待人员在企业微信客户端发送的消息
+)
+
+// 消息类型
+const (
+	SyncMsgTypeText                = "text"                  // 文本消息
+	SyncMsgTypeImage               = "image"                 // 图片消息
+	SyncMsgTypeVoice               = "voice"                 // 语音消息
+	SyncMsgTypeVideo               = "video"                 // 视频消息
+	SyncMsgTypeFile                = "file"                  // 文件消息
+	SyncMsgTypeLocation            = "location"              // 位置消息
+	SyncMsgTypeLink                = "link"                  // 链接消息
+	SyncMsgTypeBusinessCard        = "business_card"         // 名片消息
+	SyncMsgTypeMiniProgram         = "miniprogram"           // 小程序消息
+	SyncMsgTypeMsgMenu             = "msgmenu"               // 菜单消息
+	SyncMsgTypeChannelsShopProduct = "channels_shop_product" // 视频号商品消息
+	SyncMsgTypeChannelsShopOrder   = "channels_shop_order"   // 视频号订单消息
+	SyncMsgTypeMergedMsg           = "merged_msg"            // 聊天记录消息
+	SyncMsgTypeChannels            = "channels"              // 视频号消息
+	SyncMsgTypeNote                = "note"                  // 笔记消息
+	SyncMsgTypeEvent               = "event"                 // 事件消息
+)
+
+// 事件类型
+const (
+	EventTypeEnterSession                  = "enter_session"                     // 用户进入会话事件
+	EventTypeMsgSendFail                   = "msg_send_fail"                     // 消息发送失败事件
+	EventTypeServicerStatusChange          = "servicer_status_change"            // 接待人员接待状态变更事件
+	EventTypeSessionStatusChange           = "session_status_change"             // 会话状态变更事件
+	EventTypeUserRecallMsg                 = "user_recall_msg"                   // 用户撤回消息事件
+	EventTypeServicerRecallMsg             = "servicer_recall_msg"               // 接待人员撤回消息事件
+	EventTypeRejectCustomerMsgSwitchChange = "reject_customer_msg_switch_change" // 拒收客户消息变更事件
+)
+
+// 会话状态变更类型
+const (
+	SessionChangeTypeFromPool = 1 // 从接待池接入会话
+	SessionChangeTypeTransfer = 2 // 转接会话
+	SessionChangeTypeEnd      = 3 // 结束会话
+	SessionChangeTypeRejoin   = 4 // 重新接入已结束/已转接会话
+)
+
+// SyncMessage 读取到的消息
+// 根据msgtype读取对应的字段,msgtype为event时读取Event字段
+type SyncMessage struct {
+	MsgID          string `json:"msgid"`                     // 消息ID
+	OpenKfID       string `json:"open_kfid"`                 // 客服账号ID
+	ExternalUserID string `json:"external_userid,omitempty"` // 客户UserID(事件消息时可能为空)
+	SendTime       int64  `json:"send_time"`                 // 消息发送时间
+	Origin         int    `json:"origin"`                    // 消息来源,3-微信客户发送 4-系统推送的事件 5-接待人员在企业微信客户端发送
+	ServicerUserID string `json:"servicer_userid,omitempty"` // 从企业微信给客户发消息的接待人员userid
+	MsgType        string `json:"msgtype"`                   // 消息类型
+
+	Text                *SyncTextContent            `json:"text,omitempty"`                  // 文本消息
+	Image               *MediaContent               `json:"image,omitempty"`                 // 图片消息
+	Voice               *MediaContent               `json:"voice,omitempty"`                 // 语音消息
+	Video               *MediaContent               `json:"video,omitempty"`                 // 视频消息
+	File                *MediaContent               `json:"file,omitempty"`                  // 文件消息
+	Location            *LocationContent            `json:"location,omitempty"`              // 位置消息
+	Link                *SyncLinkContent            `json:"link,omitempty"`                  // 链接消息
+	BusinessCard        *BusinessCardContent        `json:"business_card,omitempty"`         // 名片消息
+	MiniProgram         *MiniProgramContent         `json:"miniprogram,omitempty"`           // 小程序消息
+	MsgMenu             *MsgMenuContent             `json:"msgmenu,omitempty"`               // 菜单消息
+	ChannelsShopProduct *ChannelsShopProductContent `json:"channels_shop_product,omitempty"` // 视频号商品消息
+	ChannelsShopOrder   *ChannelsShopOrderContent   `json:"channels_shop_order,omitempty"`   // 视频号订单消息
+	MergedMsg           *MergedMsgContent           `json:"merged_msg,omitempty"`            // 聊天记录消息
+	Channels            *ChannelsContent            `json:"channels,omitempty"`              // 视频号消息
+	Note                *NoteContent                `json:"note,omitempty"`                  // 笔记消息
+	Event               *SyncEvent                  `json:"event,omitempty"`                 // 事件消息
+}
+
+// IsEvent 是否为事件消息
+func (m *SyncMessage) IsEvent() bool {
+	return m.MsgType == SyncMsgTypeEvent && m.Event != nil
+}
+
+// SyncTextContent 读取到的文本消息内容
+type SyncTextContent struct {
+	Content string `json:"content"`           // 文本内容
+	MenuID  string `json:"menu_id,omitempty"` // 客户点击菜单消息触发的回复消息中附带的菜单ID
+}
+
+// SyncLinkContent 读取到的链接消息内容
+type SyncLinkContent struct {
+	Title  string `json:"title"`   // 标题
+	Desc   string `json:"desc"`    // 描述
+	URL    string `json:"url"`     // 点击后跳转的链接
+	PicURL string `json:"pic_url"` // 缩略图链接
+}
+
+// BusinessCardContent 名片消息内容
+type BusinessCardContent struct {
+	UserID string `json:"userid"` // 名片userid
+}
+
+// ChannelsShopProductContent 视频号商品消息内容
+type ChannelsShopProductContent struct {
+	ProductID     string `json:"product_id"`      // 商品ID
+	HeadImage     string `json:"head_image"`      // 商品图片
+	Title         string `json:"title"`           // 商品标题
+	SalesPrice    string `json:"sales_price"`     // 商品价格,以分为单位
+	ShopNickname  string `json:"shop_nickname"`   // 店铺名称
+	ShopHeadImage string `json:"shop_head_image"` // 店铺头像
+}
+
+// ChannelsShopOrderContent 视频号订单消息内容
+type ChannelsShopOrderContent struct {
+	OrderID       string `json:"order_id"`       // 订单号
+	ProductTitles string `json:"product_titles"` // 商品标题
+	PriceWording  string `json:"price_wording"`  // 订单价格描述
+	State         string `json:"state"`          // 订单状态
+	ImageURL      string `json:"image_url"`      // 订单缩略图
+	ShopNickname  string `json:"shop_nickname"`  // 店铺名称
+}
+
+// MergedMsgContent 聊天记录消息内容
+type MergedMsgContent struct {
+	Title string          `json:"title"` // 聊天记录标题
+	Item  []MergedMsgItem `json:"item"`  // 消息记录内的消息内容
+}
+
+// MergedMsgItem 聊天记录中的单条消息
+type MergedMsgItem struct {
+	SendTime   int64  `json:"send_time"`   // 消息发送时间
+	MsgType    string `json:"msgtype"`     // 消息类型
+	SenderName string `json:"sender_name"` // 消息发送者名称
+	MsgContent string `json:"msg_content"` // 消息内容,json字符串,结构与对应msgtype的消息一致
+}
+
+// ChannelsContent 视频号消息内容
+type ChannelsContent struct {
+	SubType  int    `json:"sub_type"` // 视频号消息类型,1-视频号动态 2-视频号直播 3-视频号名片
+	Nickname string `json:"nickname"` // 视频号名称
+	Title    string `json:"title"`    // 视频号动态标题
+}
+
+// NoteContent 笔记消息内容(目前不支持获取具体内容)
+type NoteContent struct{}
+
+// SyncEvent 事件消息内容
+// 不同事件类型返回的字段不同,请根据EventType读取
+type SyncEvent struct {
+	EventType      string `json:"event_type"`                // 事件类型
+	OpenKfID       string `json:"open_kfid,omitempty"`       // 客服账号ID
+	ExternalUserID string `json:"external_userid,omitempty"` // 客户UserID
+
+	// enter_session 用户进入会话事件
+	Scene          string                `json:"scene,omitempty"`           // 进入会话的场景值,获取客服账号链接时指定
+	SceneParam     string                `json:"scene_param,omitempty"`     // 进入会话的自定义参数
+	WelcomeCode    string                `json:"welcome_code,omitempty"`    // 可用于发送欢迎语的code,20秒内有效
+	WechatChannels *WechatChannelsSource `json:"wechat_channels,omitempty"` // 从视频号进入会话时的视频号信息
+
+	// msg_send_fail 消息发送失败事件
+	FailMsgID string `json:"fail_msgid,omitempty"` // 发送失败的消息msgid
+	FailType  int    `json:"fail_type,omitempty"`  // 失败类型,0-未知原因 1-客服账号已删除 2-应用已关闭 4-会话已过期 5-会话已关闭 6-超过5条限制 8-主体未验证 10-用户拒收 11-企业未有成员登录企业微信App 12-发送的消息为客服组件禁发的消息类型
+
+	// servicer_status_change 接待人员接待状态变更事件
+	ServicerUserID string `json:"servicer_userid,omitempty"` // 状态变更的接待人员userid
+	Status         int    `json:"status,omitempty"`          // 状态类型,1-接待中 2-停止接待
+	StopType       int    `json:"stop_type,omitempty"`       // 接待人员的停止接待状态,0-停止接待 1-暂时挂起
+
+	// session_status_change 会话状态变更事件
+	ChangeType        int    `json:"change_type,omitempty"`         // 变更类型,1-从接待池接入会话 2-转接会话 3-结束会话 4-重新接入已结束/已转接会话
+	OldServicerUserID string `json:"old_servicer_userid,omitempty"` // 老的接待人员userid
+	NewServicerUserID string `json:"new_servicer_userid,omitempty"` // 新的接待人员userid
+	MsgCode           string `json:"msg_code,omitempty"`            // 用于发送事件响应消息的code
+
+	// user_recall_msg / servicer_recall_msg 撤回消息事件
+	RecallMsgID string `json:"recall_msgid,omitempty"` // 撤回的消息msgid
+
+	// reject_customer_msg_switch_change 拒收客户消息变更事件
+	RejectSwitch int `json:"reject_switch,omitempty"` // 拒收客户消息,1-拒收 0-取消拒收
+}
+
+// WechatChannelsSource 进入会话的视频号信息
+type WechatChannelsSource struct {
+	Nickname     string `json:"nickname,omitempty"`      // 视频号名称
+	ShopNickname string `json:"shop_nickname,omitempty"` // 视频号小店名称
+	Scene        int    `json:"scene"`                   // 视频号场景值,1-视频号主页 2-视频号直播间商品列表页 3-视频号商品橱窗页 4-视频号小店商品详情页 5-视频号小店订单页
+}
@@ -14,6 +14,38 @@ const (
 	MediaTypeFile MediaType = "file"
 )
 
+// 上传临时素材的大小限制
+// 文档: https://developer.work.weixin.qq.com/document/path/90253
+const (
+	// MaxImageBytes 图片最大字节数
+	MaxImageBytes = 10 << 20
+	// MaxVoiceBytes 语音最大字节数
+	MaxVoiceBytes = 2 << 20
+	// MaxVideoBytes 视频最大字节数
+	MaxVideoBytes = 10 << 20
+	// MaxFileBytes 普通文件最大字节数
+	MaxFileBytes = 20 << 20
+	// MinMediaBytes 文件最小字节数
+	MinMediaBytes = 5
+	// MaxUploadByURLBytes 异步上传（upload_by_url）最大字节数，仅支持视频与普通文件
+	MaxUploadByURLBytes = 200 << 20
+)
+
+// MaxUploadBytes 返回直接上传该类型素材的最大字节数，未知类型返回 0
+func MaxUploadBytes(t MediaType) int64 {
+	switch t {
+	case MediaTypeImage:
+		return MaxImageBytes
+	case MediaTypeVoice:
+		return MaxVoiceBytes
+	case MediaTypeVideo:
+		return MaxVideoBytes
+	case MediaTypeFile:
+		return MaxFileBytes
+	}
+	return 0
+}
+
 // UploadImageResponse 上传图片响应
 type UploadImageResponse struct {
 	// URL 图片URL,永久有效
@@ -30,6 +62,9 @@ type UploadMediaResponse struct {
 	CreatedAt string `json:"created_at"`
 }
 
+// UploadSceneWelcomeMsg 异步上传场景：客户联系入群欢迎语素材，得到的 media_id 仅可用于入群欢迎语
+const UploadSceneWelcomeMsg = 1
+
 // UploadByURLRequest 异步上传临时素材请求
 type UploadByURLRequest struct {
 	// Scene 场景值。1-客户联系入群欢迎语素材
@@ -0,0 +1,97 @@
+package message
+
+import "strings"
+
+// BroadcastRequest 大批量发送应用消息请求
+// 接收者按接口限制（成员 1000、部门 100、标签 100）分批发送，每批使用相同的消息内容与发送选项
+type BroadcastRequest struct {
+	// ID 广播ID，用于保存与恢复发送进度；为空时不保存进度
+	ID string `json:"id,omitempty"`
+	// Message 消息内容与发送选项，接收者通过 UserIDs/PartyIDs/TagIDs 指定，不能设置 ToUser/ToParty/ToTag
+	Message *SendMessageRequest `json:"message"`
+	// UserIDs 接收成员ID列表，重复的成员只发送一次
+	UserIDs []string `json:"userids,omitempty"`
+	// PartyIDs 接收部门ID列表
+	PartyIDs []int `json:"partyids,omitempty"`
+	// TagIDs 接收标签ID列表
+	TagIDs []int `json:"tagids,omitempty"`
+}
+
+// BroadcastChunk 一批消息的发送结果
+type BroadcastChunk struct {
+	// Index 批次序号，从 0 开始
+	Index int `json:"index"`
+	// MsgID 消息id，用于撤回应用消息
+	MsgID string `json:"msgid"`
+	// ResponseCode 模板卡片消息的response_code
+	ResponseCode string `json:"response_code,omitempty"`
+	// InvalidUser 不合法的userid，'|'分隔
+	InvalidUser string `json:"invaliduser,omitempty"`
+	// InvalidParty 不合法的partyid，'|'分隔
+	InvalidParty string `json:"invalidparty,omitempty"`
+	// InvalidTag 不合法的标签id，'|'分隔
+	InvalidTag string `json:"invalidtag,omitempty"`
+	// UnlicensedUser 没有基础接口许可的userid，'|'分隔
+	UnlicensedUser string `json:"unlicenseduser,omitempty"`
+}
+
+// BroadcastResult 大批量发送结果，同时作为断点续发的进度保存
+type BroadcastResult struct {
+	// ID 广播ID
+	ID string `json:"id,omitempty"`
+	// Fingerprint 接收者与分批方式的摘要，恢复进度时用于确认接收者未变化
+	Fingerprint string `json:"fingerprint"`
+	// Total 批次总数
+	Total int `json:"total"`
+	// Chunks 已发送成功的批次，按序号排列
+	Chunks []BroadcastChunk `json:"chunks"`
+	// Failed 发送失败的批次序号，再次调用 Broadcast 时会重新发送
+	Failed []int `json:"failed,omitempty"`
+}
+
+// Done 是否所有批次均已发送成功
+func (r *BroadcastResult) Done() bool {
+	return len(r.Chunks) == r.Total
+}
+
+// MsgIDs 返回所有已发送批次的消息id
+func (r *BroadcastResult) MsgIDs() []string {
+	ids := make([]string, 0, len(r.Chunks))
+	for _, c := range r.Chunks {
+		if c.MsgID != "" {
+			ids = append(ids, c.MsgID)
+		}
+	}
+	return ids
+}
+
+// InvalidUsers 返回所有批次中不合法的userid
+func (r *BroadcastResult) InvalidUsers() []string {
+	return r.collect(func(c *BroadcastChunk) string { return c.InvalidUser })
+}
+
+// InvalidParties 返回所有批次中不合法的partyid
+func (r *BroadcastResult) InvalidParties() []string {
+	return r.collect(func(c *BroadcastChunk) string { return c.InvalidParty })
+}
+
+// InvalidTags 返回所有批次中不合法的标签id
+func (r *BroadcastResult) InvalidTags() []string {
+	return r.collect(func(c *BroadcastChunk) string { return c.InvalidTag })
+}
+
+// UnlicensedUsers 返回所有批次中没有基础接口许可的userid
+func (r *BroadcastResult) UnlicensedUsers() []string {
+	return r.collect(func(c *BroadcastChunk) string { return c.UnlicensedUser })
+}
+
+// collect 拆分并合并各批次中'|'分隔的ID
+func (r *BroadcastResult) collect(field func(*BroadcastChunk) string) []string {
+	var ids []string
+	for i := range r.Chunks {
+		if v := field(&r.Chunks[i]); v != "" {
+			ids = append(ids, strings.Split(v, "|")...)
+		}
+	}
+	return ids
+}
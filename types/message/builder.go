@@ -0,0 +1,272 @@
+package message
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Builder 应用消息构建器，链式设置接收者与发送选项，Build 时在本地校验消息后返回 SendMessageRequest
+//
+// 示例：
+//
+//	req, err := message.NewText("服务器告警").
+//	    ToUsers("zhangsan", "lisi").
+//	    ToParties(2).
+//	    AgentID(1000002).
+//	    Safe().
+//	    DedupWithin(10 * time.Minute).
+//	    Build()
+//	if err != nil {
+//	    return err // errors.Is(err, message.ErrInvalidMessage)
+//	}
+//	resp, err := client.Message.Send(ctx, req)
+type Builder struct {
+	req     SendMessageRequest
+	users   []string
+	parties []int
+	tags    []int
+	toAll   bool
+	err     error
+}
+
+// newBuilder 创建指定类型的构建器
+func newBuilder(msgType MessageType) *Builder {
+	return &Builder{req: SendMessageRequest{MsgType: msgType}}
+}
+
+// NewText 创建文本消息
+func NewText(content string) *Builder {
+	b := newBuilder(MessageTypeText)
+	b.req.Text = &TextMessage{Content: content}
+	return b
+}
+
+// NewImage 创建图片消息
+func NewImage(mediaID string) *Builder {
+	b := newBuilder(MessageTypeImage)
+	b.req.Image = &MediaMessage{MediaID: mediaID}
+	return b
+}
+
+// NewVoice 创建语音消息
+func NewVoice(mediaID string) *Builder {
+	b := newBuilder(MessageTypeVoice)
+	b.req.Voice = &MediaMessage{MediaID: mediaID}
+	return b
+}
+
+// NewVideo 创建视频消息，title 与 description 可以为空
+func NewVideo(mediaID, title, description string) *Builder {
+	b := newBuilder(MessageTypeVideo)
+	b.req.Video = &VideoMessage{MediaID: mediaID, Title: title, Description: description}
+	return b
+}
+
+// NewFile 创建文件消息
+func NewFile(mediaID string) *Builder {
+	b := newBuilder(MessageTypeFile)
+	b.req.File = &MediaMessage{MediaID: mediaID}
+	return b
+}
+
+// NewTextCard 创建文本卡片消息，按钮文字通过 BtnText 设置
+func NewTextCard(title, description, url string) *Builder {
+	b := newBuilder(MessageTypeTextCard)
+	b.req.TextCard = &TextCardMessage{Title: title, Description: description, URL: url}
+	return b
+}
+
+// NewNews 创建图文消息
+func NewNews(articles ...NewsArticle) *Builder {
+	b := newBuilder(MessageTypeNews)
+	b.req.News = &NewsMessage{Articles: articles}
+	return b
+}
+
+// NewMPNews 创建图文消息（mpnews）
+func NewMPNews(articles ...MPNewsArticle) *Builder {
+	b := newBuilder(MessageTypeMPNews)
+	b.req.MPNews = &MPNewsMessage{Articles: articles}
+	return b
+}
+
+// NewMarkdown 创建markdown消息
+func NewMarkdown(content string) *Builder {
+	b := newBuilder(MessageTypeMarkdown)
+	b.req.Markdown = &MarkdownMessage{Content: content}
+	return b
+}
+
+// NewMiniProgramNotice 创建小程序通知消息
+func NewMiniProgramNotice(notice *MiniProgramNoticeMessage) *Builder {
+	b := newBuilder(MessageTypeMiniProgramNotice)
+	b.req.MiniProgramNotice = notice
+	return b
+}
+
+// NewTemplateCard 创建模板卡片消息，支持所有 TemplateCardType
+func NewTemplateCard(card *TemplateCardMessage) *Builder {
+	b := newBuilder(MessageTypeTemplateCard)
+	b.req.TemplateCard = card
+	return b
+}
+
+// ToUsers 添加接收成员，重复的成员只发送一次
+func (b *Builder) ToUsers(userIDs ...string) *Builder {
+	for _, id := range userIDs {
+		if id == "" || strings.Contains(id, "|") {
+			b.fail(invalid("invalid userid %q", id))
+			continue
+		}
+		b.users = append(b.users, id)
+	}
+	return b
+}
+
+// ToParties 添加接收部门，重复的部门只发送一次
+func (b *Builder) ToParties(partyIDs ...int) *Builder {
+	b.parties = append(b.parties, partyIDs...)
+	return b
+}
+
+// ToTags 添加接收标签，重复的标签只发送一次
+func (b *Builder) ToTags(tagIDs ...int) *Builder {
+	b.tags = append(b.tags, tagIDs...)
+	return b
+}
+
+// ToAll 发送给应用可见范围内的全部成员，不能与 ToUsers 同时使用
+func (b *Builder) ToAll() *Builder {
+	b.toAll = true
+	return b
+}
+
+// Recipients 发送应用与接收者，用于 SendFile 等便捷发送方法
+type Recipients struct {
+	// AgentID 发送消息的应用ID
+	AgentID int
+	// UserIDs 接收成员ID列表
+	UserIDs []string
+	// PartyIDs 接收部门ID列表
+	PartyIDs []int
+	// TagIDs 接收标签ID列表
+	TagIDs []int
+	// ToAll 发送给应用可见范围内的全部成员
+	ToAll bool
+}
+
+// To 设置发送应用与接收者
+func (b *Builder) To(r Recipients) *Builder {
+	b.AgentID(r.AgentID).ToUsers(r.UserIDs...).ToParties(r.PartyIDs...).ToTags(r.TagIDs...)
+	if r.ToAll {
+		b.ToAll()
+	}
+	return b
+}
+
+// AgentID 设置应用ID
+func (b *Builder) AgentID(agentID int) *Builder {
+	b.req.AgentID = agentID
+	return b
+}
+
+// Safe 设置为保密消息
+func (b *Builder) Safe() *Builder {
+	b.req.Safe = intPtr(1)
+	return b
+}
+
+// SafeWatermark 设置为仅限在企业内分享的保密消息（仅 mpnews 支持）
+func (b *Builder) SafeWatermark() *Builder {
+	b.req.Safe = intPtr(2)
+	return b
+}
+
+// EnableIDTrans 开启id转译
+func (b *Builder) EnableIDTrans() *Builder {
+	b.req.EnableIDTrans = intPtr(1)
+	return b
+}
+
+// DedupWithin 开启重复消息检查，d 时间内相同内容的消息不会重复发送
+// 企业微信按秒计算，不足一秒的部分向上取整，最长 4 小时
+func (b *Builder) DedupWithin(d time.Duration) *Builder {
+	if d <= 0 {
+		b.fail(invalid("duplicate check interval must be positive, got %s", d))
+		return b
+	}
+	seconds := int((d + time.Second - 1) / time.Second)
+	b.req.EnableDuplicateCheck = intPtr(1)
+	b.req.DuplicateCheckInterval = &seconds
+	return b
+}
+
+// BtnText 设置文本卡片的按钮文字，默认为“详情”
+func (b *Builder) BtnText(text string) *Builder {
+	if b.req.TextCard == nil {
+		b.fail(invalid("btntxt is only supported by textcard, got %s", b.req.MsgType))
+		return b
+	}
+	b.req.TextCard.BtnTxt = text
+	return b
+}
+
+// Build 校验消息并返回发送请求，校验失败时返回包装 ErrInvalidMessage 的错误
+func (b *Builder) Build() (*SendMessageRequest, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.toAll && len(b.users) > 0 {
+		return nil, invalid("ToAll cannot be combined with ToUsers")
+	}
+
+	req := b.req
+	if b.toAll {
+		req.ToUser = "@all"
+	} else {
+		req.ToUser = strings.Join(dedup(b.users), "|")
+	}
+	req.ToParty = joinIDs(dedup(b.parties))
+	req.ToTag = joinIDs(dedup(b.tags))
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// fail 记录第一个构建错误
+func (b *Builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// dedup 按出现顺序去重
+func dedup[T comparable](ids []T) []T {
+	seen := make(map[T]struct{}, len(ids))
+	out := make([]T, 0, len(ids))
+	for _, id := range ids {
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
+
+// joinIDs 以'|'拼接ID
+func joinIDs(ids []int) string {
+	s := make([]string, len(ids))
+	for i, id := range ids {
+		s[i] = strconv.Itoa(id)
+	}
+	return strings.Join(s, "|")
+}
+
+// intPtr 返回 int 指针
+func intPtr(v int) *int {
+	return &v
+}
@@ -0,0 +1,157 @@
+package message
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuilder_Recipients(t *testing.T) {
+	req, err := NewText("hello").
+		ToUsers("zhangsan", "lisi", "zhangsan").
+		ToParties(2, 3, 2).
+		ToTags(1).
+		AgentID(1000002).
+		Safe().
+		EnableIDTrans().
+		DedupWithin(90*time.Second + time.Millisecond).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "zhangsan|lisi", req.ToUser)
+	assert.Equal(t, "2|3", req.ToParty)
+	assert.Equal(t, "1", req.ToTag)
+	assert.Equal(t, MessageTypeText, req.MsgType)
+	assert.Equal(t, 1, *req.Safe)
+	assert.Equal(t, 1, *req.EnableIDTrans)
+	assert.Equal(t, 1, *req.EnableDuplicateCheck)
+	assert.Equal(t, 91, *req.DuplicateCheckInterval)
+
+	req, err = NewMarkdown("**hello**").ToAll().AgentID(1000002).Build()
+	require.NoError(t, err)
+	assert.Equal(t, "@all", req.ToUser)
+
+	req, err = NewFile("media-1").To(Recipients{AgentID: 1000002, UserIDs: []string{"zhangsan"}, PartyIDs: []int{2}}).Build()
+	require.NoError(t, err)
+	assert.Equal(t, 1000002, req.AgentID)
+	assert.Equal(t, "zhangsan", req.ToUser)
+	assert.Equal(t, "2", req.ToParty)
+}
+
+func TestBuilder_AllMessageTypes(t *testing.T) {
+	builders := map[MessageType]*Builder{
+		MessageTypeText:     NewText("hello"),
+		MessageTypeImage:    NewImage("media-1"),
+		MessageTypeVoice:    NewVoice("media-1"),
+		MessageTypeVideo:    NewVideo("media-1", "title", ""),
+		MessageTypeFile:     NewFile("media-1"),
+		MessageTypeTextCard: NewTextCard("title", "description", "https://example.com").BtnText("更多"),
+		MessageTypeNews:     NewNews(NewsArticle{Title: "title", URL: "https://example.com"}),
+		MessageTypeMPNews:   NewMPNews(MPNewsArticle{Title: "title", ThumbMediaID: "media-1", Content: "content"}),
+		MessageTypeMarkdown: NewMarkdown("**hello**"),
+		MessageTypeMiniProgramNotice: NewMiniProgramNotice(&MiniProgramNoticeMessage{
+			AppID: "wx123",
+			Title: "会议室预订成功",
+		}),
+		MessageTypeTemplateCard: NewTemplateCard(&TemplateCardMessage{
+			CardType:   TemplateCardTypeTextNotice,
+			MainTitle:  &CardMainTitle{Title: "title"},
+			CardAction: &CardAction{Type: 1, URL: "https://example.com"},
+		}),
+	}
+
+	for msgType, b := range builders {
+		t.Run(string(msgType), func(t *testing.T) {
+			req, err := b.ToUsers("zhangsan").AgentID(1000002).Build()
+			require.NoError(t, err)
+			assert.Equal(t, msgType, req.MsgType)
+		})
+	}
+}
+
+func TestBuilder_TemplateCardTypes(t *testing.T) {
+	title := &CardMainTitle{Title: "title"}
+	action := &CardAction{Type: 1, URL: "https://example.com"}
+	submit := &CardSubmitButton{Text: "提交", Key: "submit"}
+	options := []CardSelectOption{{ID: "1", Text: "A"}}
+
+	cards := []*TemplateCardMessage{
+		{CardType: TemplateCardTypeTextNotice, SubTitleText: "sub", CardAction: action},
+		{CardType: TemplateCardTypeNewsNotice, MainTitle: title, CardImage: &CardImage{URL: "https://example.com/a.png"}, CardAction: action},
+		{CardType: TemplateCardTypeButtonInteraction, MainTitle: title, TaskID: "task-1", ButtonList: []CardButton{{Text: "同意", Key: "agree"}}},
+		{CardType: TemplateCardTypeVoteInteraction, MainTitle: title, TaskID: "task-1", Checkbox: &CardCheckbox{QuestionKey: "q", OptionList: []CardCheckboxOption{{ID: "1", Text: "A"}}}, SubmitButton: submit},
+		{CardType: TemplateCardTypeMultipleInteraction, MainTitle: title, TaskID: "task-1", SelectList: []CardSelect{{QuestionKey: "q", OptionList: options}}, SubmitButton: submit},
+	}
+
+	for _, card := range cards {
+		t.Run(string(card.CardType), func(t *testing.T) {
+			_, err := NewTemplateCard(card).ToUsers("zhangsan").AgentID(1000002).Build()
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestBuilder_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *Builder
+		want    string
+	}{
+		{"no recipients", NewText("hello").AgentID(1), "at least one of touser"},
+		{"no agent", NewText("hello").ToUsers("zhangsan"), "agentid is required"},
+		{"invalid userid", NewText("hello").ToUsers("a|b").AgentID(1), "invalid userid"},
+		{"too many users", NewText("hello").ToUsers(manyUsers(MaxToUsers + 1)...).AgentID(1), "touser has 1001 recipients"},
+		{"all with users", NewText("hello").ToAll().ToUsers("zhangsan").AgentID(1), "ToAll cannot be combined"},
+		{"text too long", NewText(strings.Repeat("a", MaxTextBytes+1)).ToUsers("zhangsan").AgentID(1), "text content is 2049 bytes"},
+		{"empty markdown", NewMarkdown("").ToUsers("zhangsan").AgentID(1), "markdown content is required"},
+		{"textcard btntxt", NewTextCard("t", "d", "https://example.com").BtnText("查看详情内容").ToUsers("zhangsan").AgentID(1), "btntxt is 6 characters"},
+		{"btntxt on text", NewText("hello").BtnText("详情"), "btntxt is only supported by textcard"},
+		{"too many articles", NewNews(make([]NewsArticle, MaxNewsArticles+1)...).ToUsers("zhangsan").AgentID(1), "news must have 1 to 8 articles"},
+		{"mpnews required", NewMPNews(MPNewsArticle{Title: "title"}).ToUsers("zhangsan").AgentID(1), "thumb_media_id and content are required"},
+		{"safe watermark", NewText("hello").SafeWatermark().ToUsers("zhangsan").AgentID(1), "safe=2 is only supported by mpnews"},
+		{"dedup too long", NewText("hello").DedupWithin(5 * time.Hour).ToUsers("zhangsan").AgentID(1), "duplicate_check_interval must be between"},
+		{"dedup negative", NewText("hello").DedupWithin(-time.Second), "must be positive"},
+		{"miniprogram title", NewMiniProgramNotice(&MiniProgramNoticeMessage{AppID: "wx123", Title: "短"}).ToUsers("zhangsan").AgentID(1), "title must be 4 to 12 characters"},
+		{"nil card", NewTemplateCard(nil).ToUsers("zhangsan").AgentID(1), "template_card content is not set"},
+		{"unknown card", NewTemplateCard(&TemplateCardMessage{CardType: "unknown"}).ToUsers("zhangsan").AgentID(1), `unsupported template_card card_type "unknown"`},
+		{"text_notice action", NewTemplateCard(&TemplateCardMessage{CardType: TemplateCardTypeTextNotice, SubTitleText: "sub"}).ToUsers("zhangsan").AgentID(1), "text_notice card_action is required"},
+		{"button task_id", NewTemplateCard(&TemplateCardMessage{CardType: TemplateCardTypeButtonInteraction, MainTitle: &CardMainTitle{Title: "t"}}).ToUsers("zhangsan").AgentID(1), "button_interaction task_id is required"},
+		{"unsupported field", NewTemplateCard(&TemplateCardMessage{CardType: TemplateCardTypeTextNotice, Checkbox: &CardCheckbox{}}).ToUsers("zhangsan").AgentID(1), "checkbox is not supported by text_notice"},
+		{"task_id chars", NewTemplateCard(&TemplateCardMessage{CardType: TemplateCardTypeVoteInteraction, TaskID: "task 1"}).ToUsers("zhangsan").AgentID(1), "task_id may only contain"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := tt.builder.Build()
+			assert.Nil(t, req)
+			assert.ErrorIs(t, err, ErrInvalidMessage)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestSendMessageRequest_ValidateContentMismatch(t *testing.T) {
+	req := &SendMessageRequest{
+		ToUser:  "zhangsan",
+		MsgType: MessageTypeText,
+		AgentID: 1,
+		Text:    &TextMessage{Content: "hello"},
+		Image:   &MediaMessage{MediaID: "media-1"},
+	}
+	assert.ErrorContains(t, req.Validate(), "msgtype is text but image content is set")
+
+	req = &SendMessageRequest{ToUser: "zhangsan", MsgType: "unknown", AgentID: 1}
+	assert.ErrorContains(t, req.Validate(), `unsupported msgtype "unknown"`)
+}
+
+func manyUsers(n int) []string {
+	users := make([]string, n)
+	for i := range users {
+		users[i] = fmt.Sprintf("user%d", i)
+	}
+	return users
+}
@@ -0,0 +1,325 @@
+package message
+
+import "fmt"
+
+// Validate 校验模板卡片：卡片类型、各类型的必填字段、列表条数以及只有特定类型支持的字段
+// 文档: https://developer.work.weixin.qq.com/document/path/90236#模板卡片消息
+func (c *TemplateCardMessage) Validate() error {
+	if c == nil {
+		return invalid("template_card is nil")
+	}
+
+	switch c.CardType {
+	case TemplateCardTypeTextNotice, TemplateCardTypeNewsNotice, TemplateCardTypeButtonInteraction,
+		TemplateCardTypeVoteInteraction, TemplateCardTypeMultipleInteraction:
+	default:
+		return invalid("unsupported template_card card_type %q", c.CardType)
+	}
+
+	if err := c.checkFieldSupport(); err != nil {
+		return err
+	}
+	if err := c.validateCommon(); err != nil {
+		return err
+	}
+
+	switch c.CardType {
+	case TemplateCardTypeTextNotice:
+		return c.validateTextNotice()
+	case TemplateCardTypeNewsNotice:
+		return c.validateNewsNotice()
+	case TemplateCardTypeButtonInteraction:
+		return c.validateButtonInteraction()
+	case TemplateCardTypeVoteInteraction:
+		return c.validateVoteInteraction()
+	default:
+		return c.validateMultipleInteraction()
+	}
+}
+
+// checkFieldSupport 校验卡片只设置了该类型支持的字段
+func (c *TemplateCardMessage) checkFieldSupport() error {
+	fields := []struct {
+		name      string
+		set       bool
+		supported []TemplateCardType
+	}{
+		{"emphasis_content", c.EmphasisContent != nil, []TemplateCardType{TemplateCardTypeTextNotice}},
+		{"sub_title_text", c.SubTitleText != "", []TemplateCardType{TemplateCardTypeTextNotice, TemplateCardTypeButtonInteraction}},
+		{"jump_list", len(c.JumpList) > 0, []TemplateCardType{TemplateCardTypeTextNotice, TemplateCardTypeNewsNotice}},
+		{"image_text_area", c.ImageTextArea != nil, []TemplateCardType{TemplateCardTypeNewsNotice}},
+		{"card_image", c.CardImage != nil, []TemplateCardType{TemplateCardTypeNewsNotice}},
+		{"vertical_content_list", len(c.VerticalContentList) > 0, []TemplateCardType{TemplateCardTypeNewsNotice}},
+		{"button_selection", c.ButtonSelection != nil, []TemplateCardType{TemplateCardTypeButtonInteraction}},
+		{"button_list", len(c.ButtonList) > 0, []TemplateCardType{TemplateCardTypeButtonInteraction}},
+		{"checkbox", c.Checkbox != nil, []TemplateCardType{TemplateCardTypeVoteInteraction}},
+		{"select_list", len(c.SelectList) > 0, []TemplateCardType{TemplateCardTypeMultipleInteraction}},
+		{"submit_button", c.SubmitButton != nil, []TemplateCardType{TemplateCardTypeVoteInteraction, TemplateCardTypeMultipleInteraction}},
+	}
+
+	for _, f := range fields {
+		if !f.set {
+			continue
+		}
+		supported := false
+		for _, t := range f.supported {
+			if t == c.CardType {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return invalid("template_card %s is not supported by %s", f.name, c.CardType)
+		}
+	}
+	return nil
+}
+
+// validateCommon 校验所有卡片类型共有的字段
+func (c *TemplateCardMessage) validateCommon() error {
+	if c.TaskID != "" {
+		if err := checkBytes("template_card task_id", c.TaskID, 128); err != nil {
+			return err
+		}
+		for _, r := range c.TaskID {
+			if !isTaskIDRune(r) {
+				return invalid("template_card task_id may only contain digits, letters and _-@, got %q", r)
+			}
+		}
+	}
+
+	if c.ActionMenu != nil {
+		if c.TaskID == "" {
+			return invalid("template_card task_id is required with action_menu")
+		}
+		if n := len(c.ActionMenu.ActionList); n == 0 || n > 3 {
+			return invalid("template_card action_menu must have 1 to 3 actions, got %d", n)
+		}
+		for i, a := range c.ActionMenu.ActionList {
+			if a.Text == "" || a.Key == "" {
+				return invalid("template_card action_menu action %d text and key are required", i)
+			}
+		}
+	}
+
+	if c.QuoteArea != nil {
+		if err := checkJump("template_card quote_area", c.QuoteArea.Type, c.QuoteArea.URL, c.QuoteArea.AppID); err != nil {
+			return err
+		}
+	}
+
+	if n := len(c.HorizontalContentList); n > 6 {
+		return invalid("template_card has %d horizontal_content_list items, exceeds 6", n)
+	}
+	for i, h := range c.HorizontalContentList {
+		if h.KeyName == "" {
+			return invalid("template_card horizontal_content_list %d keyname is required", i)
+		}
+		switch {
+		case h.Type == 1 && h.URL == "":
+			return invalid("template_card horizontal_content_list %d url is required for type 1", i)
+		case h.Type == 2 && h.MediaID == "":
+			return invalid("template_card horizontal_content_list %d media_id is required for type 2", i)
+		case h.Type == 3 && h.UserID == "":
+			return invalid("template_card horizontal_content_list %d userid is required for type 3", i)
+		}
+	}
+
+	if n := len(c.JumpList); n > 3 {
+		return invalid("template_card has %d jump_list items, exceeds 3", n)
+	}
+	for i, j := range c.JumpList {
+		if j.Title == "" {
+			return invalid("template_card jump_list %d title is required", i)
+		}
+		if err := checkJump(fmt.Sprintf("template_card jump_list %d", i), j.Type, j.URL, j.AppID); err != nil {
+			return err
+		}
+	}
+
+	if c.CardAction != nil {
+		if err := checkJump("template_card card_action", c.CardAction.Type, c.CardAction.URL, c.CardAction.AppID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateTextNotice 校验文本通知型卡片
+func (c *TemplateCardMessage) validateTextNotice() error {
+	if (c.MainTitle == nil || c.MainTitle.Title == "") && c.SubTitleText == "" {
+		return invalid("text_notice requires main_title.title or sub_title_text")
+	}
+	return c.requireCardAction()
+}
+
+// validateNewsNotice 校验图文展示型卡片
+func (c *TemplateCardMessage) validateNewsNotice() error {
+	if err := c.requireMainTitle(); err != nil {
+		return err
+	}
+	if c.CardImage == nil && c.ImageTextArea == nil {
+		return invalid("news_notice requires card_image or image_text_area")
+	}
+	if c.CardImage != nil && c.CardImage.URL == "" {
+		return invalid("news_notice card_image url is required")
+	}
+	if a := c.ImageTextArea; a != nil {
+		if a.ImageURL == "" {
+			return invalid("news_notice image_text_area image_url is required")
+		}
+		if err := checkJump("news_notice image_text_area", a.Type, a.URL, a.AppID); err != nil {
+			return err
+		}
+	}
+	if n := len(c.VerticalContentList); n > 4 {
+		return invalid("news_notice has %d vertical_content_list items, exceeds 4", n)
+	}
+	for i, v := range c.VerticalContentList {
+		if v.Title == "" {
+			return invalid("news_notice vertical_content_list %d title is required", i)
+		}
+	}
+	return c.requireCardAction()
+}
+
+// validateButtonInteraction 校验按钮交互型卡片
+func (c *TemplateCardMessage) validateButtonInteraction() error {
+	if err := c.requireMainTitle(); err != nil {
+		return err
+	}
+	if err := c.requireTaskID(); err != nil {
+		return err
+	}
+	if s := c.ButtonSelection; s != nil {
+		if err := checkSelect("button_interaction button_selection", s.QuestionKey, s.OptionList, 10); err != nil {
+			return err
+		}
+	}
+	if n := len(c.ButtonList); n == 0 || n > 6 {
+		return invalid("button_interaction must have 1 to 6 buttons, got %d", n)
+	}
+	for i, b := range c.ButtonList {
+		if b.Text == "" {
+			return invalid("button_interaction button %d text is required", i)
+		}
+		if b.Type == 1 && b.URL == "" {
+			return invalid("button_interaction button %d url is required for type 1", i)
+		}
+		if b.Type != 1 && b.Key == "" {
+			return invalid("button_interaction button %d key is required", i)
+		}
+	}
+	return nil
+}
+
+// validateVoteInteraction 校验投票选择型卡片
+func (c *TemplateCardMessage) validateVoteInteraction() error {
+	if err := c.requireMainTitle(); err != nil {
+		return err
+	}
+	if err := c.requireTaskID(); err != nil {
+		return err
+	}
+	if c.Checkbox == nil {
+		return invalid("vote_interaction checkbox is required")
+	}
+	if c.Checkbox.QuestionKey == "" {
+		return invalid("vote_interaction checkbox question_key is required")
+	}
+	if n := len(c.Checkbox.OptionList); n == 0 || n > 20 {
+		return invalid("vote_interaction checkbox must have 1 to 20 options, got %d", n)
+	}
+	for i, o := range c.Checkbox.OptionList {
+		if o.ID == "" || o.Text == "" {
+			return invalid("vote_interaction checkbox option %d id and text are required", i)
+		}
+	}
+	return c.requireSubmitButton()
+}
+
+// validateMultipleInteraction 校验多项选择型卡片
+func (c *TemplateCardMessage) validateMultipleInteraction() error {
+	if err := c.requireMainTitle(); err != nil {
+		return err
+	}
+	if err := c.requireTaskID(); err != nil {
+		return err
+	}
+	if n := len(c.SelectList); n == 0 || n > 3 {
+		return invalid("multiple_interaction must have 1 to 3 select_list items, got %d", n)
+	}
+	for i, s := range c.SelectList {
+		if err := checkSelect(fmt.Sprintf("multiple_interaction select_list %d", i), s.QuestionKey, s.OptionList, 10); err != nil {
+			return err
+		}
+	}
+	return c.requireSubmitButton()
+}
+
+// requireMainTitle 校验 main_title.title 必填
+func (c *TemplateCardMessage) requireMainTitle() error {
+	if c.MainTitle == nil || c.MainTitle.Title == "" {
+		return invalid("%s main_title.title is required", c.CardType)
+	}
+	return nil
+}
+
+// requireTaskID 校验 task_id 必填
+func (c *TemplateCardMessage) requireTaskID() error {
+	if c.TaskID == "" {
+		return invalid("%s task_id is required", c.CardType)
+	}
+	return nil
+}
+
+// requireCardAction 校验 card_action 必填
+func (c *TemplateCardMessage) requireCardAction() error {
+	if c.CardAction == nil {
+		return invalid("%s card_action is required", c.CardType)
+	}
+	if c.CardAction.Type != 1 && c.CardAction.Type != 2 {
+		return invalid("%s card_action type must be 1 (url) or 2 (miniprogram), got %d", c.CardType, c.CardAction.Type)
+	}
+	return nil
+}
+
+// requireSubmitButton 校验 submit_button 必填
+func (c *TemplateCardMessage) requireSubmitButton() error {
+	if c.SubmitButton == nil || c.SubmitButton.Text == "" || c.SubmitButton.Key == "" {
+		return invalid("%s submit_button text and key are required", c.CardType)
+	}
+	return nil
+}
+
+// checkJump 校验跳转类型对应的字段：type 1 需要 url，type 2 需要 appid
+func checkJump(field string, jumpType int, url, appID string) error {
+	switch {
+	case jumpType == 1 && url == "":
+		return invalid("%s url is required for type 1", field)
+	case jumpType == 2 && appID == "":
+		return invalid("%s appid is required for type 2", field)
+	}
+	return nil
+}
+
+// checkSelect 校验下拉选择
+func checkSelect(field, questionKey string, options []CardSelectOption, max int) error {
+	if questionKey == "" {
+		return invalid("%s question_key is required", field)
+	}
+	if n := len(options); n == 0 || n > max {
+		return invalid("%s must have 1 to %d options, got %d", field, max, n)
+	}
+	for i, o := range options {
+		if o.ID == "" || o.Text == "" {
+			return invalid("%s option %d id and text are required", field, i)
+		}
+	}
+	return nil
+}
+
+// isTaskIDRune 判断 task_id 字符是否合法（数字、字母与_-@）
+func isTaskIDRune(r rune) bool {
+	return r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_' || r == '-' || r == '@'
+}
@@ -0,0 +1,302 @@
+package message
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidMessage 消息不符合应用消息接口的限制
+var ErrInvalidMessage = errors.New("message: invalid message")
+
+// 应用消息的限制
+// 文档: https://developer.work.weixin.qq.com/document/path/90236
+const (
+	// MaxToUsers 接收成员最多个数
+	MaxToUsers = 1000
+	// MaxToParties 接收部门最多个数
+	MaxToParties = 100
+	// MaxToTags 接收标签最多个数
+	MaxToTags = 100
+	// MaxTextBytes 文本内容最长字节数
+	MaxTextBytes = 2048
+	// MaxMarkdownBytes markdown内容最长字节数
+	MaxMarkdownBytes = 2048
+	// MaxNewsArticles 图文消息最多图文条数
+	MaxNewsArticles = 8
+	// MaxDuplicateCheckInterval 重复消息检查的最长时间间隔（秒）
+	MaxDuplicateCheckInterval = 4 * 60 * 60
+)
+
+// invalid 返回包装 ErrInvalidMessage 的错误
+func invalid(format string, args ...any) error {
+	return fmt.Errorf("%w: "+format, append([]any{ErrInvalidMessage}, args...)...)
+}
+
+// checkBytes 校验字段字节数
+func checkBytes(field, value string, max int) error {
+	if n := len(value); n > max {
+		return invalid("%s is %d bytes, exceeds %d", field, n, max)
+	}
+	return nil
+}
+
+// checkChars 校验字段字符数
+func checkChars(field, value string, max int) error {
+	if n := utf8.RuneCountInString(value); n > max {
+		return invalid("%s is %d characters, exceeds %d", field, n, max)
+	}
+	return nil
+}
+
+// checkRecipients 校验'|'分隔的接收者列表
+func checkRecipients(field, list string, max int) error {
+	if list == "" {
+		return nil
+	}
+	ids := strings.Split(list, "|")
+	if n := len(ids); n > max {
+		return invalid("%s has %d recipients, exceeds %d", field, n, max)
+	}
+	for i, id := range ids {
+		if id == "" {
+			return invalid("%s has an empty recipient at position %d", field, i)
+		}
+	}
+	return nil
+}
+
+// Validate 按文档校验应用消息：接收者、消息类型与内容、长度限制以及模板卡片的必填字段
+func (r *SendMessageRequest) Validate() error {
+	if r == nil {
+		return invalid("request is nil")
+	}
+	if r.AgentID <= 0 {
+		return invalid("agentid is required")
+	}
+
+	// 接收者
+	if r.ToUser == "" && r.ToParty == "" && r.ToTag == "" {
+		return invalid("at least one of touser, toparty and totag is required")
+	}
+	if r.ToUser != "@all" {
+		if err := checkRecipients("touser", r.ToUser, MaxToUsers); err != nil {
+			return err
+		}
+		if strings.Contains(r.ToUser, "@all") {
+			return invalid("touser @all cannot be combined with other users")
+		}
+	}
+	if err := checkRecipients("toparty", r.ToParty, MaxToParties); err != nil {
+		return err
+	}
+	if err := checkRecipients("totag", r.ToTag, MaxToTags); err != nil {
+		return err
+	}
+
+	// 发送选项
+	if r.Safe != nil {
+		switch *r.Safe {
+		case 0, 1:
+		case 2:
+			if r.MsgType != MessageTypeMPNews {
+				return invalid("safe=2 is only supported by mpnews")
+			}
+		default:
+			return invalid("safe must be 0, 1 or 2, got %d", *r.Safe)
+		}
+	}
+	if r.DuplicateCheckInterval != nil {
+		if d := *r.DuplicateCheckInterval; d <= 0 || d > MaxDuplicateCheckInterval {
+			return invalid("duplicate_check_interval must be between 1 and %d seconds, got %d", MaxDuplicateCheckInterval, d)
+		}
+	}
+
+	if err := r.checkContentFields(); err != nil {
+		return err
+	}
+	return r.validateContent()
+}
+
+// checkContentFields 校验只设置了与消息类型对应的内容字段
+func (r *SendMessageRequest) checkContentFields() error {
+	fields := []struct {
+		msgType MessageType
+		set     bool
+	}{
+		{MessageTypeText, r.Text != nil},
+		{MessageTypeImage, r.Image != nil},
+		{MessageTypeVoice, r.Voice != nil},
+		{MessageTypeVideo, r.Video != nil},
+		{MessageTypeFile, r.File != nil},
+		{MessageTypeTextCard, r.TextCard != nil},
+		{MessageTypeNews, r.News != nil},
+		{MessageTypeMPNews, r.MPNews != nil},
+		{MessageTypeMarkdown, r.Markdown != nil},
+		{MessageTypeMiniProgramNotice, r.MiniProgramNotice != nil},
+		{MessageTypeTemplateCard, r.TemplateCard != nil},
+	}
+
+	known := false
+	for _, f := range fields {
+		if f.msgType == r.MsgType {
+			known = true
+			if !f.set {
+				return invalid("msgtype is %s but %s content is not set", r.MsgType, f.msgType)
+			}
+		} else if f.set {
+			return invalid("msgtype is %s but %s content is set", r.MsgType, f.msgType)
+		}
+	}
+	if !known {
+		return invalid("unsupported msgtype %q", r.MsgType)
+	}
+	return nil
+}
+
+// validateContent 校验消息内容
+func (r *SendMessageRequest) validateContent() error {
+	switch r.MsgType {
+	case MessageTypeText:
+		if r.Text.Content == "" {
+			return invalid("text content is required")
+		}
+		return checkBytes("text content", r.Text.Content, MaxTextBytes)
+	case MessageTypeMarkdown:
+		if r.Markdown.Content == "" {
+			return invalid("markdown content is required")
+		}
+		return checkBytes("markdown content", r.Markdown.Content, MaxMarkdownBytes)
+	case MessageTypeImage:
+		return checkMediaID("image", r.Image)
+	case MessageTypeVoice:
+		return checkMediaID("voice", r.Voice)
+	case MessageTypeFile:
+		return checkMediaID("file", r.File)
+	case MessageTypeVideo:
+		if r.Video.MediaID == "" {
+			return invalid("video media_id is required")
+		}
+		if err := checkBytes("video title", r.Video.Title, 128); err != nil {
+			return err
+		}
+		return checkBytes("video description", r.Video.Description, 512)
+	case MessageTypeTextCard:
+		return r.TextCard.validate()
+	case MessageTypeNews:
+		return r.News.validate()
+	case MessageTypeMPNews:
+		return r.MPNews.validate()
+	case MessageTypeMiniProgramNotice:
+		return r.MiniProgramNotice.validate()
+	case MessageTypeTemplateCard:
+		return r.TemplateCard.Validate()
+	}
+	return nil
+}
+
+// checkMediaID 校验媒体消息
+func checkMediaID(msgType string, m *MediaMessage) error {
+	if m.MediaID == "" {
+		return invalid("%s media_id is required", msgType)
+	}
+	return nil
+}
+
+// validate 校验文本卡片消息
+func (m *TextCardMessage) validate() error {
+	if m.Title == "" || m.Description == "" || m.URL == "" {
+		return invalid("textcard title, description and url are required")
+	}
+	if err := checkChars("textcard title", m.Title, 128); err != nil {
+		return err
+	}
+	if err := checkChars("textcard description", m.Description, 512); err != nil {
+		return err
+	}
+	if err := checkBytes("textcard url", m.URL, 2048); err != nil {
+		return err
+	}
+	return checkChars("textcard btntxt", m.BtnTxt, 4)
+}
+
+// validate 校验图文消息
+func (m *NewsMessage) validate() error {
+	if n := len(m.Articles); n == 0 || n > MaxNewsArticles {
+		return invalid("news must have 1 to %d articles, got %d", MaxNewsArticles, n)
+	}
+	for i, a := range m.Articles {
+		if a.Title == "" {
+			return invalid("news article %d title is required", i)
+		}
+		if a.URL == "" && a.AppID == "" {
+			return invalid("news article %d requires url or appid", i)
+		}
+		if a.AppID != "" && a.PagePath == "" {
+			return invalid("news article %d pagepath is required with appid", i)
+		}
+		if err := checkBytes(fmt.Sprintf("news article %d title", i), a.Title, 128); err != nil {
+			return err
+		}
+		if err := checkBytes(fmt.Sprintf("news article %d description", i), a.Description, 512); err != nil {
+			return err
+		}
+		if err := checkBytes(fmt.Sprintf("news article %d url", i), a.URL, 2048); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 校验图文消息（mpnews）
+func (m *MPNewsMessage) validate() error {
+	if n := len(m.Articles); n == 0 || n > MaxNewsArticles {
+		return invalid("mpnews must have 1 to %d articles, got %d", MaxNewsArticles, n)
+	}
+	for i, a := range m.Articles {
+		if a.Title == "" || a.ThumbMediaID == "" || a.Content == "" {
+			return invalid("mpnews article %d title, thumb_media_id and content are required", i)
+		}
+		if err := checkBytes(fmt.Sprintf("mpnews article %d title", i), a.Title, 128); err != nil {
+			return err
+		}
+		if err := checkBytes(fmt.Sprintf("mpnews article %d author", i), a.Author, 64); err != nil {
+			return err
+		}
+		if err := checkBytes(fmt.Sprintf("mpnews article %d content", i), a.Content, 666*1024); err != nil {
+			return err
+		}
+		if err := checkBytes(fmt.Sprintf("mpnews article %d digest", i), a.Digest, 512); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validate 校验小程序通知消息
+func (m *MiniProgramNoticeMessage) validate() error {
+	if m.AppID == "" {
+		return invalid("miniprogram_notice appid is required")
+	}
+	if n := utf8.RuneCountInString(m.Title); n < 4 || n > 12 {
+		return invalid("miniprogram_notice title must be 4 to 12 characters, got %d", n)
+	}
+	if m.Description != "" {
+		if n := utf8.RuneCountInString(m.Description); n < 4 || n > 12 {
+			return invalid("miniprogram_notice description must be 4 to 12 characters, got %d", n)
+		}
+	}
+	if n := len(m.ContentItem); n > 10 {
+		return invalid("miniprogram_notice has %d content items, exceeds 10", n)
+	}
+	for i, item := range m.ContentItem {
+		if err := checkChars(fmt.Sprintf("miniprogram_notice content_item %d key", i), item.Key, 10); err != nil {
+			return err
+		}
+		if err := checkChars(fmt.Sprintf("miniprogram_notice content_item %d value", i), item.Value, 30); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,552 @@
+package msgaudit
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/shuaidd/wecom-core/types/common"
+)
+
+// ChatData 会话存档SDK拉取到的一条加密会话记录
+type ChatData struct {
+	// Seq 消息的seq值，标识消息的序号，再次拉取需要带上上次回包中最大的seq
+	Seq uint64 `json:"seq"`
+	// MsgID 消息id，消息的唯一标识，企业可以使用此字段进行消息去重
+	MsgID string `json:"msgid"`
+	// PublicKeyVer 加密此条消息使用的公钥版本号
+	PublicKeyVer int `json:"publickey_ver"`
+	// EncryptRandomKey 使用公钥加密后的随机密钥（base64）
+	EncryptRandomKey string `json:"encrypt_random_key"`
+	// EncryptChatMsg 加密后的消息内容
+	EncryptChatMsg string `json:"encrypt_chat_msg"`
+}
+
+// ChatDataResponse 会话存档SDK GetChatData 的返回内容
+type ChatDataResponse struct {
+	common.Response
+	// ChatData 加密会话记录列表
+	ChatData []ChatData `json:"chatdata"`
+}
+
+// ChatAction 消息动作
+type ChatAction string
+
+const (
+	// ChatActionSend 发送消息
+	ChatActionSend ChatAction = "send"
+	// ChatActionRecall 撤回消息
+	ChatActionRecall ChatAction = "recall"
+	// ChatActionSwitch 切换企业日志
+	ChatActionSwitch ChatAction = "switch"
+)
+
+// ChatMsgType 会话消息类型
+type ChatMsgType string
+
+const (
+	// ChatMsgTypeText 文本
+	ChatMsgTypeText ChatMsgType = "text"
+	// ChatMsgTypeImage 图片
+	ChatMsgTypeImage ChatMsgType = "image"
+	// ChatMsgTypeRevoke 撤回消息
+	ChatMsgTypeRevoke ChatMsgType = "revoke"
+	// ChatMsgTypeAgree 同意会话聊天内容
+	ChatMsgTypeAgree ChatMsgType = "agree"
+	// ChatMsgTypeDisagree 不同意会话聊天内容
+	ChatMsgTypeDisagree ChatMsgType = "disagree"
+	// ChatMsgTypeVoice 语音
+	ChatMsgTypeVoice ChatMsgType = "voice"
+	// ChatMsgTypeVideo 视频
+	ChatMsgTypeVideo ChatMsgType = "video"
+	// ChatMsgTypeCard 名片
+	ChatMsgTypeCard ChatMsgType = "card"
+	// ChatMsgTypeLocation 位置
+	ChatMsgTypeLocation ChatMsgType = "location"
+	// ChatMsgTypeEmotion 表情
+	ChatMsgTypeEmotion ChatMsgType = "emotion"
+	// ChatMsgTypeFile 文件
+	ChatMsgTypeFile ChatMsgType = "file"
+	// ChatMsgTypeLink 链接
+	ChatMsgTypeLink ChatMsgType = "link"
+	// ChatMsgTypeWeApp 小程序
+	ChatMsgTypeWeApp ChatMsgType = "weapp"
+	// ChatMsgTypeChatRecord 会话记录
+	ChatMsgTypeChatRecord ChatMsgType = "chatrecord"
+	// ChatMsgTypeTodo 待办
+	ChatMsgTypeTodo ChatMsgType = "todo"
+	// ChatMsgTypeVote 投票
+	ChatMsgTypeVote ChatMsgType = "vote"
+	// ChatMsgTypeCollect 填表
+	ChatMsgTypeCollect ChatMsgType = "collect"
+	// ChatMsgTypeRedPacket 红包
+	ChatMsgTypeRedPacket ChatMsgType = "redpacket"
+	// ChatMsgTypeMeeting 会议邀请
+	ChatMsgTypeMeeting ChatMsgType = "meeting"
+	// ChatMsgTypeDocMsg 在线文档
+	ChatMsgTypeDocMsg ChatMsgType = "docmsg"
+	// ChatMsgTypeMarkdown MarkDown格式消息
+	ChatMsgTypeMarkdown ChatMsgType = "markdown"
+	// ChatMsgTypeNews 图文
+	ChatMsgTypeNews ChatMsgType = "news"
+	// ChatMsgTypeCalendar 日程
+	ChatMsgTypeCalendar ChatMsgType = "calendar"
+	// ChatMsgTypeMixed 混合消息
+	ChatMsgTypeMixed ChatMsgType = "mixed"
+	// ChatMsgTypeMeetingVoiceCall 音频存档
+	ChatMsgTypeMeetingVoiceCall ChatMsgType = "meeting_voice_call"
+	// ChatMsgTypeVoipDocShare 音频共享文档
+	ChatMsgTypeVoipDocShare ChatMsgType = "voip_doc_share"
+	// ChatMsgTypeExternalRedPacket 互通红包
+	ChatMsgTypeExternalRedPacket ChatMsgType = "external_redpacket"
+	// ChatMsgTypeSphFeed 视频号
+	ChatMsgTypeSphFeed ChatMsgType = "sphfeed"
+)
+
+// ChatMessage 解密后的会话消息
+// 根据 MsgType 读取对应字段，未支持的类型可以从 Raw 中自行解析
+type ChatMessage struct {
+	// MsgID 消息id，消息的唯一标识
+	MsgID string `json:"msgid"`
+	// Action 消息动作，send/recall/switch
+	Action ChatAction `json:"action"`
+	// From 消息发送方id，同一企业内容为userid，非相同企业为external_userid，机器人为botid
+	From string `json:"from"`
+	// ToList 消息接收方列表
+	ToList []string `json:"tolist"`
+	// RoomID 群聊消息的群id，单聊消息为空
+	RoomID string `json:"roomid"`
+	// MsgTime 消息发送时间戳，utc时间，ms单位
+	MsgTime int64 `json:"msgtime"`
+	// MsgType 消息类型
+	MsgType ChatMsgType `json:"msgtype"`
+
+	// Time 切换企业日志的时间（仅 switch），ms单位
+	Time int64 `json:"time,omitempty"`
+	// User 切换企业的用户（仅 switch）
+	User string `json:"user,omitempty"`
+	// VoiceID 音频id（仅 meeting_voice_call）
+	VoiceID string `json:"voiceid,omitempty"`
+	// VoipID 音频id（仅 voip_doc_share）
+	VoipID string `json:"voipid,omitempty"`
+
+	// 各种消息类型的具体内容
+	Text              *ChatText             `json:"text,omitempty"`
+	Image             *ChatImage            `json:"image,omitempty"`
+	Revoke            *ChatRevoke           `json:"revoke,omitempty"`
+	Agree             *ChatAgree            `json:"agree,omitempty"`
+	Disagree          *ChatAgree            `json:"disagree,omitempty"`
+	Voice             *ChatVoice            `json:"voice,omitempty"`
+	Video             *ChatVideo            `json:"video,omitempty"`
+	Card              *ChatCard             `json:"card,omitempty"`
+	Location          *ChatLocation         `json:"location,omitempty"`
+	Emotion           *ChatEmotion          `json:"emotion,omitempty"`
+	File              *ChatFile             `json:"file,omitempty"`
+	Link              *ChatLink             `json:"link,omitempty"`
+	WeApp             *ChatWeApp            `json:"weapp,omitempty"`
+	ChatRecord        *ChatRecord           `json:"chatrecord,omitempty"`
+	Todo              *ChatTodo             `json:"todo,omitempty"`
+	Vote              *ChatVote             `json:"vote,omitempty"`
+	Collect           *ChatCollect          `json:"collect,omitempty"`
+	RedPacket         *ChatRedPacket        `json:"redpacket,omitempty"`
+	Meeting           *ChatMeeting          `json:"meeting,omitempty"`
+	Doc               *ChatDoc              `json:"doc,omitempty"`
+	Calendar          *ChatCalendar         `json:"calendar,omitempty"`
+	Mixed             *ChatMixed            `json:"mixed,omitempty"`
+	MeetingVoiceCall  *ChatMeetingVoiceCall `json:"meeting_voice_call,omitempty"`
+	VoipDocShare      *ChatVoipDocShare     `json:"voip_doc_share,omitempty"`
+	ExternalRedPacket *ChatRedPacket        `json:"external_redpacket,omitempty"`
+	SphFeed           *ChatSphFeed          `json:"sphfeed,omitempty"`
+	// Markdown markdown消息内容（接口字段为 info）
+	Markdown *ChatMarkdown `json:"-"`
+	// News 图文消息内容（接口字段为 info）
+	News *ChatNews `json:"-"`
+
+	// Raw 解密后的原始消息
+	Raw json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON 解析会话消息
+// markdown 与 news 消息的内容都位于 info 字段，按 msgtype 分别解析到 Markdown 与 News
+func (m *ChatMessage) UnmarshalJSON(data []byte) error {
+	type alias ChatMessage
+	aux := struct {
+		*alias
+		Info json.RawMessage `json:"info,omitempty"`
+	}{alias: (*alias)(m)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	if len(aux.Info) > 0 {
+		var err error
+		switch m.MsgType {
+		case ChatMsgTypeMarkdown:
+			m.Markdown = &ChatMarkdown{}
+			err = json.Unmarshal(aux.Info, m.Markdown)
+		case ChatMsgTypeNews:
+			m.News = &ChatNews{}
+			err = json.Unmarshal(aux.Info, m.News)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to unmarshal %s info: %w", m.MsgType, err)
+		}
+	}
+
+	m.Raw = append(json.RawMessage(nil), data...)
+	return nil
+}
+
+// ChatText 文本消息
+type ChatText struct {
+	// Content 消息内容
+	Content string `json:"content"`
+}
+
+// ChatImage 图片消息
+type ChatImage struct {
+	// MD5Sum 图片资源的md5值
+	MD5Sum string `json:"md5sum"`
+	// FileSize 图片资源的文件大小
+	FileSize int64 `json:"filesize"`
+	// SDKFileID 媒体资源的id信息，用于拉取媒体文件
+	SDKFileID string `json:"sdkfileid"`
+}
+
+// ChatRevoke 撤回消息
+type ChatRevoke struct {
+	// PreMsgID 被撤回的原消息的msgid
+	PreMsgID string `json:"pre_msgid"`
+}
+
+// ChatAgree 同意/不同意会话聊天内容
+type ChatAgree struct {
+	// UserID 同意/不同意协议者的userid，外部企业默认为external_userid
+	UserID string `json:"userid"`
+	// AgreeTime 同意协议的时间，utc时间，ms单位
+	AgreeTime int64 `json:"agree_time,omitempty"`
+	// DisagreeTime 不同意协议的时间，utc时间，ms单位
+	DisagreeTime int64 `json:"disagree_time,omitempty"`
+}
+
+// ChatVoice 语音消息
+type ChatVoice struct {
+	// MD5Sum 资源的md5值
+	MD5Sum string `json:"md5sum"`
+	// VoiceSize 语音消息大小
+	VoiceSize int64 `json:"voice_size"`
+	// PlayLength 播放长度，秒
+	PlayLength int `json:"play_length"`
+	// SDKFileID 媒体资源的id信息
+	SDKFileID string `json:"sdkfileid"`
+}
+
+// ChatVideo 视频消息
+type ChatVideo struct {
+	// MD5Sum 资源的md5值
+	MD5Sum string `json:"md5sum"`
+	// FileSize 资源的文件大小
+	FileSize int64 `json:"filesize"`
+	// PlayLength 视频播放长度，秒
+	PlayLength int `json:"play_length"`
+	// SDKFileID 媒体资源的id信息
+	SDKFileID string `json:"sdkfileid"`
+}
+
+// ChatCard 名片消息
+type ChatCard struct {
+	// CorpName 名片所有者所在的公司名称
+	CorpName string `json:"corpname"`
+	// UserID 名片所有者的id
+	UserID string `json:"userid"`
+}
+
+// ChatLocation 位置消息
+type ChatLocation struct {
+	// Longitude 经度
+	Longitude float64 `json:"longitude"`
+	// Latitude 纬度
+	Latitude float64 `json:"latitude"`
+	// Address 地址信息
+	Address string `json:"address"`
+	// Title 位置信息的title
+	Title string `json:"title"`
+	// Zoom 缩放比例
+	Zoom int `json:"zoom"`
+}
+
+// ChatEmotion 表情消息
+type ChatEmotion struct {
+	// Type 表情类型，1表示gif，2表示png
+	Type int `json:"type"`
+	// Width 表情图片宽度
+	Width int `json:"width"`
+	// Height 表情图片高度
+	Height int `json:"height"`
+	// ImageSize 资源的文件大小
+	ImageSize int64 `json:"imagesize"`
+	// MD5Sum 资源的md5值
+	MD5Sum string `json:"md5sum"`
+	// SDKFileID 媒体资源的id信息
+	SDKFileID string `json:"sdkfileid"`
+}
+
+// ChatFile 文件消息
+type ChatFile struct {
+	// MD5Sum 资源的md5值
+	MD5Sum string `json:"md5sum"`
+	// FileName 文件名称
+	FileName string `json:"filename"`
+	// FileExt 文件类型后缀
+	FileExt string `json:"fileext"`
+	// FileSize 文件大小
+	FileSize int64 `json:"filesize"`
+	// SDKFileID 媒体资源的id信息
+	SDKFileID string `json:"sdkfileid"`
+}
+
+// ChatLink 链接消息
+type ChatLink struct {
+	// Title 消息标题
+	Title string `json:"title"`
+	// Description 消息描述
+	Description string `json:"description"`
+	// LinkURL 链接url地址
+	LinkURL string `json:"link_url"`
+	// ImageURL 链接图片url
+	ImageURL string `json:"image_url"`
+}
+
+// ChatWeApp 小程序消息
+type ChatWeApp struct {
+	// Title 消息标题
+	Title string `json:"title"`
+	// Description 消息描述
+	Description string `json:"description"`
+	// UserName 用户名称
+	UserName string `json:"username"`
+	// DisplayName 小程序名称
+	DisplayName string `json:"displayname"`
+}
+
+// ChatRecord 会话记录消息
+type ChatRecord struct {
+	// Title 聊天记录标题
+	Title string `json:"title"`
+	// Item 消息记录内的消息内容
+	Item []ChatRecordItem `json:"item"`
+}
+
+// ChatRecordItem 会话记录中的消息
+type ChatRecordItem struct {
+	// Type 每条聊天记录的具体消息类型，如 ChatRecordText、ChatRecordImage
+	Type string `json:"type"`
+	// MsgTime 消息时间，utc时间，单位秒
+	MsgTime int64 `json:"msgtime"`
+	// Content 消息内容，json串，内容为对应类型的json
+	Content string `json:"content"`
+	// FromChatroom 是否来自群会话
+	FromChatroom bool `json:"from_chatroom"`
+}
+
+// ChatTodo 待办消息
+type ChatTodo struct {
+	// Title 待办的来源文本
+	Title string `json:"title"`
+	// Content 待办的具体内容
+	Content string `json:"content"`
+}
+
+// ChatVote 投票消息
+type ChatVote struct {
+	// VoteTitle 投票主题
+	VoteTitle string `json:"votetitle"`
+	// VoteItem 投票选项，可能多个内容
+	VoteItem []string `json:"voteitem"`
+	// VoteType 投票类型，101发起投票、102参与投票
+	VoteType int `json:"votetype"`
+	// VoteID 投票id，方便将参与投票消息与发起投票消息进行前后对照
+	VoteID string `json:"voteid"`
+}
+
+// ChatCollect 填表消息
+type ChatCollect struct {
+	// RoomName 填表消息所在的群名称
+	RoomName string `json:"room_name"`
+	// Creator 创建者在群中的名字
+	Creator string `json:"creator"`
+	// CreateTime 创建的时间
+	CreateTime string `json:"create_time"`
+	// Title 表名
+	Title string `json:"title"`
+	// Details 表内容
+	Details []ChatCollectDetail `json:"details"`
+}
+
+// ChatCollectDetail 填表的题目
+type ChatCollectDetail struct {
+	// ID 表项id
+	ID int64 `json:"id"`
+	// Ques 表项名称
+	Ques string `json:"ques"`
+	// Type 表项类型，Text/Number/Date/Time
+	Type string `json:"type"`
+}
+
+// ChatRedPacket 红包消息（含互通红包）
+type ChatRedPacket struct {
+	// Type 红包类型，1普通红包、2拼手气群红包、3激励群红包
+	Type int `json:"type"`
+	// Wish 红包祝福语
+	Wish string `json:"wish"`
+	// TotalCnt 红包总个数
+	TotalCnt int `json:"totalcnt"`
+	// TotalAmount 红包总金额，单位为分
+	TotalAmount int64 `json:"totalamount"`
+}
+
+// ChatMeeting 会议邀请消息
+type ChatMeeting struct {
+	// Topic 会议主题
+	Topic string `json:"topic"`
+	// StartTime 会议开始时间，utc时间
+	StartTime int64 `json:"starttime"`
+	// EndTime 会议结束时间，utc时间
+	EndTime int64 `json:"endtime"`
+	// Address 会议地址
+	Address string `json:"address"`
+	// Remarks 会议备注
+	Remarks string `json:"remarks"`
+	// MeetingType 会议消息类型，101发起会议邀请消息、102处理会议邀请消息
+	MeetingType int `json:"meetingtype"`
+	// MeetingID 会议id
+	MeetingID int64 `json:"meetingid"`
+	// Status 会议邀请处理状态，1参加会议、2拒绝会议、3待定、4未被邀请、5会议已取消、6会议已过期、7不在房间内
+	Status int `json:"status"`
+}
+
+// ChatDoc 在线文档消息
+type ChatDoc struct {
+	// Title 在线文档名称
+	Title string `json:"title"`
+	// DocCreator 在线文档创建者，本企业成员创建为userid，外部企业成员创建为external_userid
+	DocCreator string `json:"doc_creator"`
+	// LinkURL 在线文档链接
+	LinkURL string `json:"link_url"`
+}
+
+// ChatMarkdown MarkDown格式消息
+type ChatMarkdown struct {
+	// Content markdown消息内容，目前为机器人发出的消息
+	Content string `json:"content"`
+}
+
+// ChatNews 图文消息
+type ChatNews struct {
+	// Item 图文消息数组
+	Item []ChatNewsItem `json:"item"`
+}
+
+// ChatNewsItem 图文消息文章
+type ChatNewsItem struct {
+	// Title 图文消息标题
+	Title string `json:"title"`
+	// Description 图文消息描述
+	Description string `json:"description"`
+	// URL 图文消息点击跳转地址
+	URL string `json:"url"`
+	// PicURL 图文消息配图的url
+	PicURL string `json:"picurl"`
+}
+
+// ChatCalendar 日程消息
+type ChatCalendar struct {
+	// Title 日程主题
+	Title string `json:"title"`
+	// CreatorName 日程组织者
+	CreatorName string `json:"creatorname"`
+	// AttendeeName 日程参与人
+	AttendeeName []string `json:"attendeename"`
+	// StartTime 日程开始时间，utc时间，单位秒
+	StartTime int64 `json:"starttime"`
+	// EndTime 日程结束时间，utc时间，单位秒
+	EndTime int64 `json:"endtime"`
+	// Place 日程地点
+	Place string `json:"place"`
+	// Remarks 日程备注
+	Remarks string `json:"remarks"`
+}
+
+// ChatMixed 混合消息
+type ChatMixed struct {
+	// Item 消息内容
+	Item []ChatMixedItem `json:"item"`
+}
+
+// ChatMixedItem 混合消息中的一条消息
+type ChatMixedItem struct {
+	// Type 消息类型，如 text、image
+	Type ChatMsgType `json:"type"`
+	// Content 消息内容，json串，内容为对应类型的json
+	Content string `json:"content"`
+}
+
+// Decode 将消息内容解析到 v（如 *ChatText、*ChatImage）
+func (i *ChatMixedItem) Decode(v any) error {
+	return json.Unmarshal([]byte(i.Content), v)
+}
+
+// ChatMeetingVoiceCall 音频存档消息
+type ChatMeetingVoiceCall struct {
+	// EndTime 音频结束时间
+	EndTime int64 `json:"endtime"`
+	// SDKFileID 音频媒体下载的id
+	SDKFileID string `json:"sdkfileid"`
+	// DemoFileData 文档分享对象
+	DemoFileData []ChatDemoFileData `json:"demofiledata,omitempty"`
+	// ShareScreenData 屏幕共享对象
+	ShareScreenData []ChatShareScreenData `json:"sharescreendata,omitempty"`
+}
+
+// ChatDemoFileData 音频存档中的文档分享
+type ChatDemoFileData struct {
+	// FileName 文档共享名称
+	FileName string `json:"filename"`
+	// DemoOperator 文档共享者的userid
+	DemoOperator string `json:"demooperator"`
+	// StartTime 文档共享开始时间
+	StartTime int64 `json:"starttime"`
+	// EndTime 文档共享结束时间
+	EndTime int64 `json:"endtime"`
+}
+
+// ChatShareScreenData 音频存档中的屏幕共享
+type ChatShareScreenData struct {
+	// Share 屏幕共享者的userid
+	Share string `json:"share"`
+	// StartTime 屏幕共享开始时间
+	StartTime int64 `json:"starttime"`
+	// EndTime 屏幕共享结束时间
+	EndTime int64 `json:"endtime"`
+}
+
+// ChatVoipDocShare 音频共享文档消息
+type ChatVoipDocShare struct {
+	// FileName 文档共享文件名称
+	FileName string `json:"filename"`
+	// MD5Sum 共享文件的md5值
+	MD5Sum string `json:"md5sum"`
+	// FileSize 共享文件的大小
+	FileSize int64 `json:"filesize"`
+	// SDKFileID 共享文件的sdkfile
+	SDKFileID string `json:"sdkfileid"`
+}
+
+// ChatSphFeed 视频号消息
+type ChatSphFeed struct {
+	// FeedType 视频号消息类型，2图片、4视频、9直播
+	FeedType int `json:"feed_type"`
+	// SphName 视频号账号名称
+	SphName string `json:"sph_name"`
+	// FeedDesc 视频号消息描述
+	FeedDesc string `json:"feed_desc"`
+}
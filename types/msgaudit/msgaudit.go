@@ -0,0 +1,132 @@
+package msgaudit
+
+import "github.com/shuaidd/wecom-core/types/common"
+
+// PermitUserType 会话内容存档的版本类型
+type PermitUserType int
+
+const (
+	// PermitUserTypeOffice 办公版
+	PermitUserTypeOffice PermitUserType = 1
+	// PermitUserTypeService 服务版
+	PermitUserTypeService PermitUserType = 2
+	// PermitUserTypeEnterprise 企业版
+	PermitUserTypeEnterprise PermitUserType = 3
+)
+
+// AgreeStatus 同意状态
+type AgreeStatus string
+
+const (
+	// AgreeStatusAgree 同意
+	AgreeStatusAgree AgreeStatus = "Agree"
+	// AgreeStatusDisagree 不同意
+	AgreeStatusDisagree AgreeStatus = "Disagree"
+	// AgreeStatusDefaultAgree 默认同意
+	AgreeStatusDefaultAgree AgreeStatus = "Default_Agree"
+)
+
+// GetPermitUserListRequest 获取会话内容存档开启成员列表请求
+type GetPermitUserListRequest struct {
+	// Type 拉取对应版本的开启成员列表，不填时拉取全部版本
+	Type PermitUserType `json:"type,omitempty"`
+}
+
+// GetPermitUserListResponse 获取会话内容存档开启成员列表响应
+type GetPermitUserListResponse struct {
+	common.Response
+	// IDs 设置在开启范围内的成员的userid列表
+	IDs []string `json:"ids"`
+}
+
+// SingleAgreeInfo 单聊的成员与外部联系人
+type SingleAgreeInfo struct {
+	// UserID 内部成员的userid
+	UserID string `json:"userid"`
+	// ExternalOpenID 外部成员的externalopenid（接口字段名为 exteranalopenid）
+	ExternalOpenID string `json:"exteranalopenid"`
+}
+
+// CheckSingleAgreeRequest 获取单聊会话同意情况请求
+type CheckSingleAgreeRequest struct {
+	// Info 待查询的会话信息，数组
+	Info []SingleAgreeInfo `json:"info"`
+}
+
+// AgreeInfo 同意情况
+type AgreeInfo struct {
+	// StatusChangeTime 同意状态改变的具体时间，utc时间
+	StatusChangeTime int64 `json:"status_change_time"`
+	// UserID 内部成员的userid（仅单聊返回）
+	UserID string `json:"userid,omitempty"`
+	// ExternalOpenID 外部成员的externalopenid
+	ExternalOpenID string `json:"exteranalopenid"`
+	// AgreeStatus 同意Agree，不同意Disagree，默认同意Default_Agree
+	AgreeStatus AgreeStatus `json:"agree_status"`
+}
+
+// CheckSingleAgreeResponse 获取单聊会话同意情况响应
+type CheckSingleAgreeResponse struct {
+	common.Response
+	// AgreeInfo 同意情况
+	AgreeInfo []AgreeInfo `json:"agreeinfo"`
+}
+
+// CheckRoomAgreeRequest 获取群聊会话同意情况请求
+type CheckRoomAgreeRequest struct {
+	// RoomID 待查询的roomid
+	RoomID string `json:"roomid"`
+}
+
+// CheckRoomAgreeResponse 获取群聊会话同意情况响应
+type CheckRoomAgreeResponse struct {
+	common.Response
+	// AgreeInfo 群内外部联系人的同意情况
+	AgreeInfo []AgreeInfo `json:"agreeinfo"`
+}
+
+// GetGroupChatRequest 获取会话内容存档内部群信息请求
+type GetGroupChatRequest struct {
+	// RoomID 待查询的群id
+	RoomID string `json:"roomid"`
+}
+
+// GroupChatMember 内部群成员
+type GroupChatMember struct {
+	// MemberID roomid群成员的id，userid
+	MemberID string `json:"memberid"`
+	// JoinTime roomid群成员的入群时间
+	JoinTime int64 `json:"jointime"`
+}
+
+// GetGroupChatResponse 获取会话内容存档内部群信息响应
+type GetGroupChatResponse struct {
+	common.Response
+	// RoomName roomid对应的群名称
+	RoomName string `json:"roomname"`
+	// Creator roomid对应的群创建者，userid
+	Creator string `json:"creator"`
+	// RoomCreateTime roomid对应的群创建时间
+	RoomCreateTime int64 `json:"room_create_time"`
+	// Notice roomid对应的群公告
+	Notice string `json:"notice"`
+	// Members roomid对应的群成员列表
+	Members []GroupChatMember `json:"members"`
+}
+
+// RobotInfo 机器人信息
+type RobotInfo struct {
+	// RobotID 机器人ID
+	RobotID string `json:"robot_id"`
+	// Name 机器人名称
+	Name string `json:"name"`
+	// CreatorUserID 机器人创建者的userid
+	CreatorUserID string `json:"creator_userid"`
+}
+
+// GetRobotInfoResponse 获取机器人信息响应
+type GetRobotInfoResponse struct {
+	common.Response
+	// Data 机器人信息
+	Data RobotInfo `json:"data"`
+}
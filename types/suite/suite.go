@@ -0,0 +1,231 @@
+package suite
+
+import "github.com/shuaidd/wecom-core/types/common"
+
+// GetSuiteTokenRequest 获取第三方应用凭证请求
+type GetSuiteTokenRequest struct {
+	// SuiteID 以ww或wx开头应用id
+	SuiteID string `json:"suite_id"`
+	// SuiteSecret 应用secret
+	SuiteSecret string `json:"suite_secret"`
+	// SuiteTicket 企业微信后台推送的ticket
+	SuiteTicket string `json:"suite_ticket"`
+}
+
+// GetSuiteTokenResponse 获取第三方应用凭证响应
+type GetSuiteTokenResponse struct {
+	common.Response
+	// SuiteAccessToken 第三方应用access_token，最长为512字节
+	SuiteAccessToken string `json:"suite_access_token"`
+	// ExpiresIn 有效期（秒）
+	ExpiresIn int `json:"expires_in"`
+}
+
+// GetProviderTokenRequest 获取服务商凭证请求
+type GetProviderTokenRequest struct {
+	// CorpID 服务商的corpid
+	CorpID string `json:"corpid"`
+	// ProviderSecret 服务商的secret，在服务商管理后台可见
+	ProviderSecret string `json:"provider_secret"`
+}
+
+// GetProviderTokenResponse 获取服务商凭证响应
+type GetProviderTokenResponse struct {
+	common.Response
+	// ProviderAccessToken 服务商的access_token，最长为512字节
+	ProviderAccessToken string `json:"provider_access_token"`
+	// ExpiresIn 有效期（秒）
+	ExpiresIn int `json:"expires_in"`
+}
+
+// GetPreAuthCodeResponse 获取预授权码响应
+type GetPreAuthCodeResponse struct {
+	common.Response
+	// PreAuthCode 预授权码，最长为512字节
+	PreAuthCode string `json:"pre_auth_code"`
+	// ExpiresIn 有效期（秒）
+	ExpiresIn int `json:"expires_in"`
+}
+
+// SessionInfo 授权配置
+type SessionInfo struct {
+	// AppID 允许进行授权的应用id，如1、2、3，不填或者填空数组都表示允许授权套件内所有应用
+	AppID []int `json:"appid,omitempty"`
+	// AuthType 授权类型：0 正式授权，1 测试授权，默认值为0
+	AuthType int `json:"auth_type"`
+}
+
+// SetSessionInfoRequest 设置授权配置请求
+type SetSessionInfoRequest struct {
+	// PreAuthCode 预授权码
+	PreAuthCode string `json:"pre_auth_code"`
+	// SessionInfo 本次授权过程中需要用到的会话信息
+	SessionInfo SessionInfo `json:"session_info"`
+}
+
+// GetPermanentCodeRequest 获取企业永久授权码请求
+type GetPermanentCodeRequest struct {
+	// AuthCode 临时授权码，会在授权成功时附加在redirect_uri中跳转回第三方服务商网站，或通过授权成功通知回调推送给服务商，有效期10分钟
+	AuthCode string `json:"auth_code"`
+}
+
+// DealerCorpInfo 代理服务商企业信息
+type DealerCorpInfo struct {
+	// CorpID 代理服务商企业微信id
+	CorpID string `json:"corpid"`
+	// CorpName 代理服务商企业微信名称
+	CorpName string `json:"corp_name"`
+}
+
+// AuthCorpInfo 授权方企业信息
+type AuthCorpInfo struct {
+	// CorpID 授权方企业微信id
+	CorpID string `json:"corpid"`
+	// CorpName 授权方企业名称
+	CorpName string `json:"corp_name"`
+	// CorpType 授权方企业类型，认证号：verified，注册号：unverified
+	CorpType string `json:"corp_type"`
+	// CorpSquareLogoURL 授权方企业方形头像
+	CorpSquareLogoURL string `json:"corp_square_logo_url"`
+	// CorpUserMax 授权方企业用户规模
+	CorpUserMax int `json:"corp_user_max"`
+	// CorpFullName 授权方企业的主体名称（仅认证或验证过的企业有）
+	CorpFullName string `json:"corp_full_name"`
+	// VerifiedEndTime 认证到期时间
+	VerifiedEndTime int64 `json:"verified_end_time"`
+	// SubjectType 企业类型，1.企业; 2.政府以及事业单位; 3.其他组织, 4.团队号
+	SubjectType int `json:"subject_type"`
+	// CorpScale 企业规模
+	CorpScale string `json:"corp_scale"`
+	// CorpIndustry 企业所属行业
+	CorpIndustry string `json:"corp_industry"`
+	// CorpSubIndustry 企业所属子行业
+	CorpSubIndustry string `json:"corp_sub_industry"`
+}
+
+// AuthAgent 授权的应用信息
+type AuthAgent struct {
+	// AgentID 授权方应用id
+	AgentID int64 `json:"agentid"`
+	// Name 授权方应用名字
+	Name string `json:"name"`
+	// RoundLogoURL 授权方应用方形头像
+	RoundLogoURL string `json:"round_logo_url"`
+	// SquareLogoURL 授权方应用圆形头像
+	SquareLogoURL string `json:"square_logo_url"`
+	// AppID 旧的多应用套件中的对应应用id，新开发者请忽略
+	AppID int `json:"appid,omitempty"`
+	// AuthMode 授权模式，0为管理员授权；1为成员授权
+	AuthMode int `json:"auth_mode"`
+	// IsCustomizedApp 是否为代开发自建应用
+	IsCustomizedApp bool `json:"is_customized_app"`
+	// AuthFromThirdApp 来自第三方应用接口唤起，仅通过第三方应用添加自建应用授权链接授权代开发自建应用时，才返回该字段
+	AuthFromThirdApp bool `json:"auth_from_thirdapp,omitempty"`
+	// Privilege 应用对应的权限
+	Privilege *AgentPrivilege `json:"privilege,omitempty"`
+}
+
+// AgentPrivilege 应用权限
+type AgentPrivilege struct {
+	// Level 权限等级，1:通讯录基本信息只读 2:通讯录全部信息只读 3:通讯录全部信息读写 4:单个基本信息只读 5:通讯录全部信息只写
+	Level int `json:"level"`
+	// AllowParty 应用可见范围（部门）
+	AllowParty []int64 `json:"allow_party"`
+	// AllowUser 应用可见范围（成员）
+	AllowUser []string `json:"allow_user"`
+	// AllowTag 应用可见范围（标签）
+	AllowTag []int64 `json:"allow_tag"`
+	// ExtraParty 额外通讯录（部门）
+	ExtraParty []int64 `json:"extra_party"`
+	// ExtraUser 额外通讯录（成员）
+	ExtraUser []string `json:"extra_user"`
+	// ExtraTag 额外通讯录（标签）
+	ExtraTag []int64 `json:"extra_tag"`
+}
+
+// AuthInfo 授权信息
+type AuthInfo struct {
+	// Agent 授权的应用信息，注意是一个数组，但仅旧的多应用套件授权时会返回多个agent，对新的单应用授权，永远只返回一个agent
+	Agent []AuthAgent `json:"agent"`
+}
+
+// AuthUserInfo 授权管理员的信息
+type AuthUserInfo struct {
+	// UserID 授权管理员的userid，可能为空
+	UserID string `json:"userid"`
+	// OpenUserID 授权管理员的open_userid，可能为空
+	OpenUserID string `json:"open_userid"`
+	// Name 授权管理员的name，可能为空
+	Name string `json:"name"`
+	// Avatar 授权管理员的头像url，可能为空
+	Avatar string `json:"avatar"`
+}
+
+// RegisterCodeInfo 推广二维码安装相关信息
+type RegisterCodeInfo struct {
+	// RegisterCode 注册码
+	RegisterCode string `json:"register_code"`
+	// TemplateID 推广包ID
+	TemplateID string `json:"template_id"`
+	// State 仅当获取注册码指定该字段时才返回
+	State string `json:"state"`
+}
+
+// GetPermanentCodeResponse 获取企业永久授权码响应
+type GetPermanentCodeResponse struct {
+	common.Response
+	// AccessToken 授权方（企业）access_token，最长为512字节
+	AccessToken string `json:"access_token"`
+	// ExpiresIn 授权方（企业）access_token超时时间（秒）
+	ExpiresIn int `json:"expires_in"`
+	// PermanentCode 企业微信永久授权码，最长为512字节
+	PermanentCode string `json:"permanent_code"`
+	// DealerCorpInfo 代理服务商企业信息，应用被代理后才有该信息
+	DealerCorpInfo *DealerCorpInfo `json:"dealer_corp_info,omitempty"`
+	// AuthCorpInfo 授权方企业信息
+	AuthCorpInfo AuthCorpInfo `json:"auth_corp_info"`
+	// AuthInfo 授权信息
+	AuthInfo AuthInfo `json:"auth_info"`
+	// AuthUserInfo 授权管理员的信息，可能不返回
+	AuthUserInfo *AuthUserInfo `json:"auth_user_info,omitempty"`
+	// RegisterCodeInfo 推广二维码安装相关信息
+	RegisterCodeInfo *RegisterCodeInfo `json:"register_code_info,omitempty"`
+	// State 安装应用时，扫码或者授权链接中带的state值
+	State string `json:"state,omitempty"`
+}
+
+// GetAuthInfoRequest 获取企业授权信息请求
+type GetAuthInfoRequest struct {
+	// AuthCorpID 授权方corpid
+	AuthCorpID string `json:"auth_corpid"`
+	// PermanentCode 永久授权码
+	PermanentCode string `json:"permanent_code"`
+}
+
+// GetAuthInfoResponse 获取企业授权信息响应
+type GetAuthInfoResponse struct {
+	common.Response
+	// DealerCorpInfo 代理服务商企业信息
+	DealerCorpInfo *DealerCorpInfo `json:"dealer_corp_info,omitempty"`
+	// AuthCorpInfo 授权方企业信息
+	AuthCorpInfo AuthCorpInfo `json:"auth_corp_info"`
+	// AuthInfo 授权信息
+	AuthInfo AuthInfo `json:"auth_info"`
+}
+
+// GetCorpTokenRequest 获取企业凭证请求
+type GetCorpTokenRequest struct {
+	// AuthCorpID 授权方corpid
+	AuthCorpID string `json:"auth_corpid"`
+	// PermanentCode 永久授权码
+	PermanentCode string `json:"permanent_code"`
+}
+
+// GetCorpTokenResponse 获取企业凭证响应
+type GetCorpTokenResponse struct {
+	common.Response
+	// AccessToken 授权方（企业）access_token，最长为512字节
+	AccessToken string `json:"access_token"`
+	// ExpiresIn 有效期（秒）
+	ExpiresIn int `json:"expires_in"`
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"github.com/shuaidd/wecom-core/types/common"
+	"github.com/shuaidd/wecom-core/types/message"
+)
+
+// MessageType 群机器人消息类型
+type MessageType string
+
+const (
+	// MessageTypeText 文本消息
+	MessageTypeText MessageType = "text"
+	// MessageTypeMarkdown Markdown消息
+	MessageTypeMarkdown MessageType = "markdown"
+	// MessageTypeImage 图片消息
+	MessageTypeImage MessageType = "image"
+	// MessageTypeNews 图文消息
+	MessageTypeNews MessageType = "news"
+	// MessageTypeFile 文件消息
+	MessageTypeFile MessageType = "file"
+	// MessageTypeVoice 语音消息
+	MessageTypeVoice MessageType = "voice"
+	// MessageTypeTemplateCard 模板卡片消息
+	MessageTypeTemplateCard MessageType = "template_card"
+)
+
+// MediaType 群机器人上传的文件类型
+type MediaType string
+
+const (
+	// MediaTypeFile 普通文件
+	MediaTypeFile MediaType = "file"
+	// MediaTypeVoice 语音，仅支持AMR格式
+	MediaTypeVoice MediaType = "voice"
+)
+
+// 文档中的大小限制
+const (
+	// MaxTextBytes 文本内容最长字节数
+	MaxTextBytes = 2048
+	// MaxMarkdownBytes markdown内容最长字节数
+	MaxMarkdownBytes = 4096
+	// MaxImageBytes 图片（base64编码前）最大字节数
+	MaxImageBytes = 2 << 20
+	// MaxNewsArticles 图文消息最多图文条数
+	MaxNewsArticles = 8
+	// MaxFileBytes 上传普通文件最大字节数
+	MaxFileBytes = 20 << 20
+	// MaxVoiceBytes 上传语音文件最大字节数
+	MaxVoiceBytes = 2 << 20
+	// MinMediaBytes 上传文件最小字节数
+	MinMediaBytes = 5
+)
+
+// SendRequest 群机器人发送消息请求
+type SendRequest struct {
+	// MsgType 消息类型
+	MsgType MessageType `json:"msgtype"`
+
+	// 各种消息类型的具体内容
+	Text         *TextMessage                 `json:"text,omitempty"`
+	Markdown     *MarkdownMessage             `json:"markdown,omitempty"`
+	Image        *ImageMessage                `json:"image,omitempty"`
+	News         *NewsMessage                 `json:"news,omitempty"`
+	File         *MediaMessage                `json:"file,omitempty"`
+	Voice        *MediaMessage                `json:"voice,omitempty"`
+	TemplateCard *message.TemplateCardMessage `json:"template_card,omitempty"`
+}
+
+// TextMessage 文本消息
+type TextMessage struct {
+	// Content 文本内容，最长不超过2048个字节，必须是utf8编码
+	Content string `json:"content"`
+	// MentionedList userid的列表，提醒群中的指定成员(@某个成员)，@all表示提醒所有人
+	MentionedList []string `json:"mentioned_list,omitempty"`
+	// MentionedMobileList 手机号列表，提醒手机号对应的群成员(@某个成员)，@all表示提醒所有人
+	MentionedMobileList []string `json:"mentioned_mobile_list,omitempty"`
+}
+
+// MarkdownMessage Markdown消息
+type MarkdownMessage struct {
+	// Content markdown内容，最长不超过4096个字节，必须是utf8编码
+	Content string `json:"content"`
+}
+
+// ImageMessage 图片消息
+type ImageMessage struct {
+	// Base64 图片内容的base64编码，图片（base64编码前）最大不能超过2M，支持JPG,PNG格式
+	Base64 string `json:"base64"`
+	// MD5 图片内容（base64编码前）的md5值
+	MD5 string `json:"md5"`
+}
+
+// NewsMessage 图文消息
+type NewsMessage struct {
+	// Articles 图文消息，一个图文消息支持1到8条图文
+	Articles []NewsArticle `json:"articles"`
+}
+
+// NewsArticle 图文消息文章
+type NewsArticle struct {
+	// Title 标题，不超过128个字节，超过会自动截断
+	Title string `json:"title"`
+	// Description 描述，不超过512个字节，超过会自动截断
+	Description string `json:"description,omitempty"`
+	// URL 点击后跳转的链接
+	URL string `json:"url"`
+	// PicURL 图文消息的图片链接，支持JPG、PNG格式
+	PicURL string `json:"picurl,omitempty"`
+}
+
+// MediaMessage 文件、语音消息
+type MediaMessage struct {
+	// MediaID 通过上传文件接口获取的media_id
+	MediaID string `json:"media_id"`
+}
+
+// UploadMediaResponse 群机器人上传文件响应
+type UploadMediaResponse struct {
+	common.Response
+	// Type 文件类型，分别有语音(voice)和普通文件(file)
+	Type MediaType `json:"type"`
+	// MediaID 媒体文件上传后获取的唯一标识，3天内有效
+	MediaID string `json:"media_id"`
+	// CreatedAt 媒体文件上传时间戳
+	CreatedAt string `json:"created_at"`
+}
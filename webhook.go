@@ -0,0 +1,71 @@
+package wecom
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/internal/client"
+	"github.com/shuaidd/wecom-core/internal/retry"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
+	"github.com/shuaidd/wecom-core/services/webhook"
+)
+
+// NewWebhook 创建群机器人客户端
+// 群机器人通过 webhook key 鉴权，不需要 CorpID 和应用密钥；
+// key 可以是 webhook 地址中的 key，也可以是完整的 webhook 地址。
+// opts 中的 BaseURL、超时、重试、日志、Debug 与拦截器配置同样生效。
+//
+// 示例：
+//
+//	bot, err := wecom.NewWebhook("693a91f6-7xxx-4bc4-97a0-0ec2sifa5aaa", config.WithLogger(log))
+//	_, err = bot.SendText(ctx, "服务告警：磁盘使用率超过 90%", "@all")
+func NewWebhook(key string, opts ...config.Option) (*webhook.Service, error) {
+	if u, err := url.Parse(key); err == nil && u.Scheme != "" {
+		key = u.Query().Get("key")
+	}
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return nil, config.ErrMissingWebhookKey
+	}
+
+	cfg := config.New(opts...)
+	if cfg.Timeout <= 0 {
+		return nil, config.ErrInvalidTimeout
+	}
+	if cfg.MaxRetries < 0 {
+		return nil, config.ErrInvalidMaxRetries
+	}
+
+	var retryPolicy wecomretry.Policy = retry.NewPolicy(cfg.MaxRetries, cfg.InitialBackoff, cfg.MaxBackoff)
+	if cfg.RetryPolicy != nil {
+		retryPolicy = cfg.RetryPolicy
+	}
+	retryExecutor := retry.NewExecutor(retryPolicy, cfg.Logger)
+
+	// 不设置 TokenSource，请求不携带 access_token
+	httpClient := client.New(cfg.BaseURL, cfg.Timeout, cfg.Logger, nil, retryExecutor)
+	if cfg.Debug {
+		httpClient.SetDebug(true)
+	}
+	if cfg.Transport != nil {
+		httpClient.SetTransport(cfg.Transport)
+	}
+	if cfg.Observer != nil {
+		httpClient.SetObserver(cfg.Observer)
+	}
+	if cfg.Redactor != nil {
+		httpClient.SetRedactor(cfg.Redactor)
+	}
+	for _, interceptor := range cfg.RequestInterceptors {
+		httpClient.AddRequestInterceptor(interceptor)
+	}
+	for _, interceptor := range cfg.ResponseInterceptors {
+		httpClient.AddResponseInterceptor(interceptor)
+	}
+	for _, interceptor := range cfg.AfterResponseInterceptors {
+		httpClient.AddAfterResponseInterceptor(interceptor)
+	}
+
+	return webhook.NewService(httpClient, key), nil
+}
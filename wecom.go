@@ -2,12 +2,15 @@ package wecom
 
 import (
 	"context"
+	"sync"
 
 	"github.com/shuaidd/wecom-core/config"
 	"github.com/shuaidd/wecom-core/internal/auth"
 	"github.com/shuaidd/wecom-core/internal/client"
 	"github.com/shuaidd/wecom-core/internal/retry"
+	"github.com/shuaidd/wecom-core/pkg/callback"
 	"github.com/shuaidd/wecom-core/pkg/interceptor"
+	wecomretry "github.com/shuaidd/wecom-core/pkg/retry"
 	"github.com/shuaidd/wecom-core/services/agent"
 	"github.com/shuaidd/wecom-core/services/calendar"
 	"github.com/shuaidd/wecom-core/services/checkin"
@@ -17,10 +20,12 @@ import (
 	"github.com/shuaidd/wecom-core/services/externalcontact"
 	"github.com/shuaidd/wecom-core/services/invoice"
 	"github.com/shuaidd/wecom-core/services/ip"
+	"github.com/shuaidd/wecom-core/services/jssdk"
 	"github.com/shuaidd/wecom-core/services/kf"
 	"github.com/shuaidd/wecom-core/services/media"
 	"github.com/shuaidd/wecom-core/services/meeting"
 	"github.com/shuaidd/wecom-core/services/message"
+	"github.com/shuaidd/wecom-core/services/msgaudit"
 	"github.com/shuaidd/wecom-core/services/oauth"
 	"github.com/shuaidd/wecom-core/services/qrcode"
 	"github.com/shuaidd/wecom-core/services/reserve_meeting"
@@ -83,6 +88,8 @@ type Client struct {
 	ExternalContact *externalcontact.Service
 	// Media 素材管理服务
 	Media *media.Service
+	// MediaCache 按文件内容缓存 media_id 的素材上传器，Message.SendFile 等方法使用，只支持直接上传限制内的文件
+	MediaCache *media.MediaCache
 	// Checkin 打卡服务
 	Checkin *checkin.Service
 	// Invoice 电子发票服务
@@ -103,11 +110,21 @@ type Client struct {
 	Webinar *webinar.Service
 	// Approval 审批服务
 	Approval *approval.Service
+	// JSSDK JS-SDK服务（jsapi_ticket 与签名）
+	JSSDK *jssdk.Service
+	// MsgAudit 会话内容存档服务
+	MsgAudit *msgaudit.Service
 
 	// 内部组件(不对外暴露)
 	config       *config.Config
 	tokenManager *auth.TokenManager
 	httpClient   *client.Client
+	refresher    *auth.Refresher
+
+	// corps 通过 ForCorp 创建的下级/下游企业客户端
+	corps       map[corpKey]*Client
+	corpsMu     sync.Mutex
+	corpsClosed bool
 }
 
 // New 创建企业微信SDK客户端
@@ -136,12 +153,37 @@ func New(opts ...config.Option) (*Client, error) {
 		}
 	}
 
-	// 5. 创建重试策略
-	retryPolicy := retry.NewPolicy(
+	if cfg.Locker != nil {
+		tokenManager.SetLocker(cfg.Locker)
+	}
+
+	if cfg.TokenFetcher != nil {
+		tokenManager.SetTokenFetcher(auth.TokenFetcher(cfg.TokenFetcher))
+	}
+
+	if cfg.Observer != nil {
+		tokenManager.SetObserver(cfg.Observer)
+	}
+
+	if cfg.Transport != nil {
+		tokenManager.SetTransport(cfg.Transport)
+	}
+
+	// 4.1. 自定义 token 来源，未设置时使用 TokenManager
+	var tokenSource auth.TokenSource = tokenManager
+	if cfg.TokenSource != nil {
+		tokenSource = cfg.TokenSource
+	}
+
+	// 5. 创建重试策略，未自定义时使用指数退避
+	var retryPolicy wecomretry.Policy = retry.NewPolicy(
 		cfg.MaxRetries,
 		cfg.InitialBackoff,
 		cfg.MaxBackoff,
 	)
+	if cfg.RetryPolicy != nil {
+		retryPolicy = cfg.RetryPolicy
+	}
 	retryExecutor := retry.NewExecutor(retryPolicy, cfg.Logger)
 
 	// 6. 创建 HTTP 客户端
@@ -149,7 +191,7 @@ func New(opts ...config.Option) (*Client, error) {
 		cfg.BaseURL,
 		cfg.Timeout,
 		cfg.Logger,
-		tokenManager,
+		tokenSource,
 		retryExecutor,
 	)
 
@@ -157,6 +199,26 @@ func New(opts ...config.Option) (*Client, error) {
 		httpClient.SetDebug(true)
 	}
 
+	if cfg.Transport != nil {
+		httpClient.SetTransport(cfg.Transport)
+	}
+
+	if cfg.RateLimiter != nil {
+		httpClient.SetRateLimiter(cfg.RateLimiter)
+	}
+
+	if cfg.Observer != nil {
+		httpClient.SetObserver(cfg.Observer)
+	}
+
+	if cfg.Redactor != nil {
+		httpClient.SetRedactor(cfg.Redactor)
+	}
+
+	if cfg.CircuitBreaker != nil {
+		httpClient.SetCircuitBreaker(cfg.CircuitBreaker)
+	}
+
 	// 7. 注册拦截器
 	for _, interceptor := range cfg.RequestInterceptors {
 		httpClient.AddRequestInterceptor(interceptor)
@@ -168,6 +230,9 @@ func New(opts ...config.Option) (*Client, error) {
 		httpClient.AddAfterResponseInterceptor(interceptor)
 	}
 
+	ticketManager := auth.NewTicketManager(tokenManager)
+	ticketManager.SetTokenSource(tokenSource)
+
 	// 8. 创建服务客户端
 	c := &Client{
 		config:          cfg,
@@ -195,11 +260,42 @@ func New(opts ...config.Option) (*Client, error) {
 		ReserveMeeting:  reserve_meeting.NewService(httpClient),
 		Webinar:         webinar.NewService(httpClient),
 		Approval:        approval.New(httpClient),
+		JSSDK:           jssdk.NewService(ticketManager),
+		MsgAudit:        msgaudit.NewService(httpClient),
+	}
+
+	// 9. 素材缓存默认与 Token 共用缓存，按企业区分
+	c.MediaCache = media.NewMediaCache(c.Media, cfg.Cache, media.WithMediaKeyPrefix("wecom:media_id:"+cfg.CorpID+":"))
+	c.Message.SetMediaUploader(c.MediaCache)
+
+	// 10. 启动后台 token 刷新（自定义 TokenSource 自行负责刷新）
+	if cfg.BackgroundRefresh && cfg.TokenSource == nil {
+		c.refresher = auth.NewRefresher(tokenManager, cfg.RefreshInterval, cfg.RefreshAhead)
+		c.refresher.Start()
 	}
 
 	return c, nil
 }
 
+// Close 关闭客户端，停止后台 token 刷新等后台任务，同时关闭通过 ForCorp 创建的客户端
+func (c *Client) Close() error {
+	if c.refresher != nil {
+		c.refresher.Stop()
+	}
+	c.httpClient.Close()
+
+	c.corpsMu.Lock()
+	c.corpsClosed = true
+	corps := c.corps
+	c.corps = nil
+	c.corpsMu.Unlock()
+
+	for _, sub := range corps {
+		_ = sub.Close()
+	}
+	return nil
+}
+
 // WithTraceID 将 TraceId 添加到 context
 func WithTraceID(ctx context.Context, traceID string) context.Context {
 	return client.WithTraceID(ctx, traceID)
@@ -217,6 +313,32 @@ func WithAgentID(ctx context.Context, agentID int64) context.Context {
 	return client.WithAgentID(ctx, agentID)
 }
 
+// WithIdempotent 声明请求可以安全地重复发送
+// 请求已发出但未收到响应（超时、连接中断）时，默认只重试 GET 请求，避免 message/send 等接口重复执行；
+// 对于接口本身支持去重（如消息推送的 enable_duplicate_check）或重复执行无副作用的 POST 请求，可以通过此函数允许重试
+//
+// 示例：
+//
+//	ctx = wecom.WithIdempotent(ctx)
+//	user, err := client.Contact.UpdateUser(ctx, req)
+func WithIdempotent(ctx context.Context) context.Context {
+	return client.WithIdempotent(ctx)
+}
+
+// NewCallbackHandler 创建接收消息与事件的回调处理器
+// 使用 config.WithCallback 或 config.WithAgentCallback 中配置的 Token 与 EncodingAESKey
+//
+// 示例：
+//
+//	h, err := client.NewCallbackHandler()
+//	h.HandleEvent(callback.EventClick, callback.Typed(func(ctx context.Context, e *callback.ClickEvent) (callback.Reply, error) {
+//	    return callback.NewTextReply("已收到"), nil
+//	}))
+//	http.Handle("/wecom/callback", h)
+func (c *Client) NewCallbackHandler() (*callback.Handler, error) {
+	return callback.NewHandler(c.config)
+}
+
 // CustomGet 发送自定义 GET 请求
 // 自动处理 access_token 注入和重试逻辑
 //
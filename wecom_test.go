@@ -0,0 +1,443 @@
+package wecom_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/pkg/breaker"
+	"github.com/shuaidd/wecom-core/pkg/logger"
+	"github.com/shuaidd/wecom-core/pkg/observer"
+	"github.com/shuaidd/wecom-core/pkg/retry"
+	"github.com/shuaidd/wecom-core/types/message"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+// circuitObserver 记录熔断器状态变化
+type circuitObserver struct {
+	observer.NoopObserver
+	mu      sync.Mutex
+	changes []string
+}
+
+func (o *circuitObserver) CircuitStateChanged(key breaker.Key, from, to breaker.State) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.changes = append(o.changes, key.String()+":"+from.String()+"->"+to.String())
+}
+
+func TestClient_SharedCircuitBreaker(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubError("/cgi-bin/user/get", 10001, "system busy")
+	srv.StubError("/cgi-bin/department/list", 10001, "system busy")
+
+	cb := breaker.New(breaker.WithFailureThreshold(1))
+	obs := &circuitObserver{}
+	newClient := func(corpID string) *wecom.Client {
+		client, err := wecom.New(append(srv.Options(),
+			config.WithCorpID(corpID),
+			config.WithTokenFetcher(func(ctx context.Context, agentKey string) (string, int, error) {
+				return srv.IssueToken(corpID), 7200, nil
+			}),
+			config.WithCircuitBreaker(cb),
+			config.WithObserver(obs),
+		)...)
+		require.NoError(t, err)
+		return client
+	}
+
+	a := newClient("wwcorpa")
+	b := newClient("wwcorpb")
+	defer b.Close()
+
+	// 共享熔断器的客户端只处理本企业的状态变化
+	_, err := a.Contact.GetUser(context.Background(), "zhangsan")
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	assert.Equal(t, []string{"wwcorpa|/cgi-bin/user/get:closed->open"}, obs.changes)
+
+	// 其他企业的请求仍会发送，熔断状态各自独立
+	_, err = b.Contact.GetUser(context.Background(), "zhangsan")
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 2)
+
+	// 关闭后移除回调，同一企业重新创建的客户端不会重复通知
+	require.NoError(t, a.Close())
+	a = newClient("wwcorpa")
+	defer a.Close()
+	_, err = a.Contact.ListDepartments(context.Background(), 0)
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	assert.Equal(t, []string{
+		"wwcorpa|/cgi-bin/user/get:closed->open",
+		"wwcorpb|/cgi-bin/user/get:closed->open",
+		"wwcorpa|/cgi-bin/department/list:closed->open",
+	}, obs.changes)
+}
+
+// brokerSource 模拟 token 中控服务，从模拟服务获取 token
+type brokerSource struct {
+	srv     *wecomtest.Server
+	token   string
+	refresh []string
+}
+
+func (b *brokerSource) GetTokenByAgent(ctx context.Context, agentKey string) (string, error) {
+	if b.token != "" {
+		return b.token, nil
+	}
+	resp, err := http.Get(b.srv.URL + "/cgi-bin/gettoken?corpid=" + wecomtest.CorpID + "&corpsecret=" + wecomtest.CorpSecret)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	b.token = body.AccessToken
+	return b.token, nil
+}
+
+func (b *brokerSource) RefreshTokenIfStale(ctx context.Context, agentKey, stale string) error {
+	b.refresh = append(b.refresh, stale)
+	if b.token == stale {
+		b.token = ""
+	}
+	return nil
+}
+
+func TestClient_TokenSource(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+
+	source := &brokerSource{srv: srv}
+	client, err := wecom.New(
+		config.WithBaseURL(srv.URL),
+		config.WithCorpID(wecomtest.CorpID),
+		config.WithBackoff(time.Millisecond, 10*time.Millisecond),
+		config.WithTokenSource(source),
+	)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	first := srv.LastRequest("/cgi-bin/user/get").AccessToken
+	assert.Equal(t, source.token, first)
+
+	// token 失效时由 TokenSource 刷新
+	srv.ExpireTokens()
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	assert.Equal(t, []string{first}, source.refresh)
+	assert.NotEqual(t, first, srv.LastRequest("/cgi-bin/user/get").AccessToken)
+	assert.Equal(t, 2, srv.TokenCalls())
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubError("/cgi-bin/user/get", 10001, "system busy")
+	srv.Stub("/cgi-bin/department/list", map[string]any{"department": []any{}})
+
+	cb := breaker.New(breaker.WithFailureThreshold(2), breaker.WithOpenTimeout(time.Minute))
+	client, err := wecom.New(append(srv.Options(), config.WithCircuitBreaker(cb))...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// 连续两次系统繁忙后熔断，剩余的重试立即失败
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.Error(t, err)
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 2)
+	assert.Equal(t, breaker.StateOpen, cb.State(breaker.Key{CorpID: wecomtest.CorpID, Path: "/cgi-bin/user/get"}))
+
+	// 熔断期间不发送请求
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 2)
+
+	// 其他接口不受影响
+	_, err = client.Contact.ListDepartments(ctx, 0)
+	require.NoError(t, err)
+}
+
+// flakySource 可以模拟获取失败的 token 来源
+type flakySource struct {
+	srv  *wecomtest.Server
+	fail atomic.Bool
+}
+
+func (f *flakySource) GetTokenByAgent(ctx context.Context, agentKey string) (string, error) {
+	if f.fail.Load() {
+		return "", fmt.Errorf("token service unavailable")
+	}
+	return f.srv.IssueToken(wecomtest.CorpID), nil
+}
+
+func (f *flakySource) RefreshTokenIfStale(ctx context.Context, agentKey, stale string) error {
+	return nil
+}
+
+func (f *flakySource) CorpID() string {
+	return wecomtest.CorpID
+}
+
+func TestClient_CircuitBreakerHalfOpenTokenError(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubError("/cgi-bin/user/get", 10001, "system busy")
+
+	source := &flakySource{srv: srv}
+	cb := breaker.New(breaker.WithFailureThreshold(1), breaker.WithOpenTimeout(10*time.Millisecond))
+	client, err := wecom.New(append(srv.Options(),
+		config.WithTokenSource(source),
+		config.WithCircuitBreaker(cb),
+	)...)
+	require.NoError(t, err)
+	defer client.Close()
+	ctx := context.Background()
+	key := breaker.Key{CorpID: wecomtest.CorpID, Path: "/cgi-bin/user/get"}
+
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	assert.ErrorIs(t, err, breaker.ErrCircuitOpen)
+	require.Equal(t, breaker.StateOpen, cb.State(key))
+
+	// 半开状态下获取 token 失败，请求未发送，不关闭熔断器
+	time.Sleep(20 * time.Millisecond)
+	source.fail.Store(true)
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	assert.ErrorContains(t, err, "token service unavailable")
+	assert.Equal(t, breaker.StateHalfOpen, cb.State(key))
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 1)
+
+	// 释放的探测名额用于之后的请求，探测成功后关闭
+	source.fail.Store(false)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+	assert.Equal(t, breaker.StateClosed, cb.State(key))
+}
+
+// recordingObserver 记录请求与 token 事件
+type recordingObserver struct {
+	observer.NoopObserver
+	mu       sync.Mutex
+	requests []string
+	fetches  int
+	hits     int
+	misses   int
+}
+
+type observerKey struct{}
+
+func (o *recordingObserver) RequestStart(ctx context.Context, req *observer.Request) context.Context {
+	return context.WithValue(ctx, observerKey{}, req.TraceID)
+}
+
+func (o *recordingObserver) RequestFinish(ctx context.Context, req *observer.Request, result *observer.Result) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.requests = append(o.requests, fmt.Sprintf("%s %s attempt=%d status=%d errcode=%d trace=%v",
+		req.Method, req.Path, req.Attempt, result.StatusCode, result.ErrCode, ctx.Value(observerKey{})))
+}
+
+func (o *recordingObserver) TokenFetched(ctx context.Context, fetch *observer.TokenFetch) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.fetches++
+}
+
+func (o *recordingObserver) TokenCacheLookup(ctx context.Context, lookup *observer.CacheLookup) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if lookup.Hit {
+		o.hits++
+	} else {
+		o.misses++
+	}
+}
+
+func TestClient_Observer(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubSequence("/cgi-bin/user/get",
+		map[string]any{"errcode": 45009, "errmsg": "api freq out of limit"},
+		map[string]any{"errcode": 0, "userid": "zhangsan"},
+	)
+
+	obs := &recordingObserver{}
+	var interceptorTrace any
+	client, err := wecom.New(append(srv.Options(),
+		config.WithObserver(obs),
+		// 拦截器收到的是 RequestStart 返回的 context
+		config.WithRequestInterceptor(func(ctx context.Context, req *http.Request, body any) error {
+			interceptorTrace = req.Context().Value(observerKey{})
+			return nil
+		}),
+	)...)
+	require.NoError(t, err)
+
+	ctx := wecom.WithTraceID(context.Background(), "trace-1")
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"GET /cgi-bin/user/get attempt=1 status=200 errcode=45009 trace=trace-1",
+		"GET /cgi-bin/user/get attempt=2 status=200 errcode=0 trace=trace-1",
+	}, obs.requests)
+	assert.Equal(t, "trace-1", interceptorTrace)
+	assert.Equal(t, 1, obs.fetches)
+	assert.Equal(t, 1, obs.misses)
+	assert.Equal(t, 1, obs.hits)
+}
+
+// captureLogger 记录所有日志文本
+type captureLogger struct {
+	mu  sync.Mutex
+	buf strings.Builder
+}
+
+func (l *captureLogger) log(msg string, fields ...logger.Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.buf.WriteString(msg)
+	for _, f := range fields {
+		fmt.Fprintf(&l.buf, " %s=%v", f.Key, f.Value)
+	}
+	l.buf.WriteString("\n")
+}
+
+func (l *captureLogger) Debug(msg string, fields ...logger.Field) { l.log(msg, fields...) }
+func (l *captureLogger) Info(msg string, fields ...logger.Field)  { l.log(msg, fields...) }
+func (l *captureLogger) Warn(msg string, fields ...logger.Field)  { l.log(msg, fields...) }
+func (l *captureLogger) Error(msg string, fields ...logger.Field) { l.log(msg, fields...) }
+
+func (l *captureLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.buf.String()
+}
+
+func TestClient_RedactLogs(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{
+		"errcode": 0,
+		"userid":  "zhangsan",
+		"mobile":  "13800001234",
+		"email":   "zhangsan@example.com",
+	})
+
+	log := &captureLogger{}
+	var token string
+	client, err := wecom.New(append(srv.Options(),
+		config.WithLogger(log),
+		config.WithDebug(true),
+		config.WithRequestInterceptor(func(ctx context.Context, req *http.Request, body any) error {
+			token = req.URL.Query().Get("access_token")
+			return nil
+		}),
+	)...)
+	require.NoError(t, err)
+
+	user, err := client.Contact.GetUser(context.Background(), "zhangsan")
+	require.NoError(t, err)
+	// 脱敏只影响日志，不影响返回结果
+	assert.Equal(t, "13800001234", user.Mobile)
+
+	logs := log.String()
+	require.NotEmpty(t, token)
+	assert.NotContains(t, logs, token)
+	assert.Contains(t, logs, "access_token=***")
+	assert.NotContains(t, logs, "13800001234")
+	assert.Contains(t, logs, "138***1234")
+	assert.NotContains(t, logs, "zhangsan@example.com")
+}
+
+// hangOnce 第一次请求不响应直到客户端超时，之后返回 resp
+func hangOnce(resp string) http.HandlerFunc {
+	var calls int32
+	return func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-r.Context().Done()
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(resp))
+	}
+}
+
+func TestClient_RetryIdempotency(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	client, err := wecom.New(append(srv.Options(), config.WithTimeout(100*time.Millisecond))...)
+	require.NoError(t, err)
+
+	send := func(ctx context.Context) error {
+		_, err := client.Message.Send(ctx, &message.SendMessageRequest{
+			ToUser:  "zhangsan",
+			MsgType: message.MessageTypeText,
+			AgentID: 1000002,
+			Text:    &message.TextMessage{Content: "hello"},
+		})
+		return err
+	}
+
+	// 超时的 POST 请求可能已被处理，默认不重试
+	srv.Handle("/cgi-bin/message/send", hangOnce(`{"errcode":0,"msgid":"msg-1"}`))
+	err = send(context.Background())
+	require.Error(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/message/send", 1)
+
+	// 声明幂等后重试
+	srv.Reset()
+	srv.Handle("/cgi-bin/message/send", hangOnce(`{"errcode":0,"msgid":"msg-1"}`))
+	require.NoError(t, send(wecom.WithIdempotent(context.Background())))
+	srv.AssertCalledTimes(t, "/cgi-bin/message/send", 2)
+
+	// GET 请求总是可以重试
+	srv.Handle("/cgi-bin/user/get", hangOnce(`{"errcode":0,"userid":"zhangsan"}`))
+	_, err = client.Contact.GetUser(context.Background(), "zhangsan")
+	require.NoError(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 2)
+}
+
+// countingPolicy 记录调用次数且从不重试的重试策略
+type countingPolicy struct {
+	attempts []*retry.Attempt
+}
+
+func (p *countingPolicy) Retry(_ context.Context, a *retry.Attempt) (time.Duration, bool) {
+	p.attempts = append(p.attempts, a)
+	return 0, false
+}
+
+func TestClient_RetryPolicy(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubError("/cgi-bin/user/get", 10001, "system busy")
+
+	policy := &countingPolicy{}
+	client, err := wecom.New(append(srv.Options(), srv.Agent("notify", 1000002), config.WithRetryPolicy(policy))...)
+	require.NoError(t, err)
+
+	_, err = client.Contact.GetUser(wecom.WithAgentName(context.Background(), "notify"), "zhangsan")
+	require.Error(t, err)
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 1)
+
+	require.Len(t, policy.attempts, 1)
+	a := policy.attempts[0]
+	assert.Equal(t, http.MethodGet, a.Method)
+	assert.Equal(t, "/cgi-bin/user/get", a.Path)
+	assert.Equal(t, "notify", a.AgentKey)
+	assert.True(t, a.Idempotent)
+	assert.Equal(t, 1, a.Attempt)
+	assert.Equal(t, retry.ClassSystemBusy, retry.Classify(a.Err))
+}
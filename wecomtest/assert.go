@@ -0,0 +1,114 @@
+package wecomtest
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// AssertCalled 断言接口至少被调用过一次
+func (s *Server) AssertCalled(tb testing.TB, path string) bool {
+	tb.Helper()
+	if len(s.RequestsTo(path)) == 0 {
+		tb.Errorf("wecomtest: expected %s to be called, but it was not", path)
+		return false
+	}
+	return true
+}
+
+// AssertNotCalled 断言接口没有被调用
+func (s *Server) AssertNotCalled(tb testing.TB, path string) bool {
+	tb.Helper()
+	if n := len(s.RequestsTo(path)); n > 0 {
+		tb.Errorf("wecomtest: expected %s not to be called, but it was called %d time(s)", path, n)
+		return false
+	}
+	return true
+}
+
+// AssertCalledTimes 断言接口被调用的次数
+func (s *Server) AssertCalledTimes(tb testing.TB, path string, times int) bool {
+	tb.Helper()
+	if n := len(s.RequestsTo(path)); n != times {
+		tb.Errorf("wecomtest: expected %s to be called %d time(s), got %d", path, times, n)
+		return false
+	}
+	return true
+}
+
+// AssertBody 断言接口最后一次请求的 JSON 请求体与 expected 一致
+// expected 可以是请求结构体、map 或 JSON 字符串，按 JSON 语义比较
+func (s *Server) AssertBody(tb testing.TB, path string, expected any) bool {
+	tb.Helper()
+	req := s.LastRequest(path)
+	if req == nil {
+		tb.Errorf("wecomtest: expected %s to be called, but it was not", path)
+		return false
+	}
+
+	want, err := normalizeJSON(expected)
+	if err != nil {
+		tb.Errorf("wecomtest: failed to encode expected body: %v", err)
+		return false
+	}
+	var got any
+	if err := json.Unmarshal(req.Body, &got); err != nil {
+		tb.Errorf("wecomtest: request body of %s is not json: %s", path, req.Body)
+		return false
+	}
+	if !reflect.DeepEqual(want, got) {
+		wantJSON, _ := json.Marshal(want)
+		tb.Errorf("wecomtest: unexpected request body for %s\n  want: %s\n   got: %s", path, wantJSON, req.Body)
+		return false
+	}
+	return true
+}
+
+// AssertQuery 断言接口最后一次请求的查询参数
+func (s *Server) AssertQuery(tb testing.TB, path, key, value string) bool {
+	tb.Helper()
+	req := s.LastRequest(path)
+	if req == nil {
+		tb.Errorf("wecomtest: expected %s to be called, but it was not", path)
+		return false
+	}
+	if got := req.Query.Get(key); got != value {
+		tb.Errorf("wecomtest: expected query %s=%q for %s, got %q", key, value, path, got)
+		return false
+	}
+	return true
+}
+
+// AssertAgent 断言接口最后一次请求使用的是指定应用的 access_token
+func (s *Server) AssertAgent(tb testing.TB, path, agent string) bool {
+	tb.Helper()
+	req := s.LastRequest(path)
+	if req == nil {
+		tb.Errorf("wecomtest: expected %s to be called, but it was not", path)
+		return false
+	}
+	if req.Agent != agent {
+		tb.Errorf("wecomtest: expected %s to be called by agent %q, got %q", path, agent, req.Agent)
+		return false
+	}
+	return true
+}
+
+// normalizeJSON 将值转换为 JSON 解码后的通用结构，便于比较
+func normalizeJSON(v any) (any, error) {
+	var data []byte
+	switch b := v.(type) {
+	case string:
+		data = []byte(b)
+	case []byte:
+		data = b
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var out any
+	err := json.Unmarshal(data, &out)
+	return out, err
+}
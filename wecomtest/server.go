@@ -0,0 +1,398 @@
+// Package wecomtest 提供进程内的企业微信模拟服务，用于测试调用SDK的业务代码
+//
+// 模拟服务实现了 /cgi-bin/gettoken，其余接口通过 Stub/Handle 注册响应，
+// 所有请求都会被记录下来供断言使用。群机器人接口（/cgi-bin/webhook/）与不以 /cgi-bin/ 开头的路径
+// （模拟微盘、会议录制等下载地址）不校验 access_token。
+//
+// 示例：
+//
+//	srv := wecomtest.NewServer(t)
+//	srv.Stub("/cgi-bin/user/get", map[string]any{"errcode": 0, "userid": "zhangsan", "name": "张三"})
+//
+//	client, _ := wecom.New(srv.Options()...)
+//	user, err := client.Contact.GetUser(ctx, "zhangsan")
+//
+//	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 1)
+package wecomtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shuaidd/wecom-core/config"
+)
+
+const (
+	// CorpID 模拟服务使用的企业ID
+	CorpID = "wwtestcorp"
+	// CorpSecret 模拟服务默认应用的密钥
+	CorpSecret = "wecomtest-secret"
+
+	// tokenPath 获取 access_token 的接口路径
+	tokenPath = "/cgi-bin/gettoken"
+	// apiPrefix 需要校验 access_token 的接口路径前缀
+	apiPrefix = "/cgi-bin/"
+	// webhookPrefix 群机器人接口路径前缀，以 key 鉴权，不校验 access_token
+	webhookPrefix = "/cgi-bin/webhook/"
+)
+
+// 模拟服务返回的错误码
+const (
+	// ErrCodeNoStub 请求的接口没有注册响应
+	ErrCodeNoStub = 404
+	// ErrCodeInvalidSecret 无效的 corpsecret
+	ErrCodeInvalidSecret = 40001
+	// ErrCodeInvalidToken 无效的 access_token
+	ErrCodeInvalidToken = 40014
+	// ErrCodeTokenExpired access_token 已过期
+	ErrCodeTokenExpired = 42001
+)
+
+// Request 模拟服务收到的请求
+type Request struct {
+	// Method HTTP方法
+	Method string
+	// Path 接口路径
+	Path string
+	// Query 查询参数
+	Query url.Values
+	// Header 请求头
+	Header http.Header
+	// Body 原始请求体
+	Body []byte
+	// JSON 请求体解析后的 JSON 对象（非 JSON 请求体时为 nil）
+	JSON map[string]any
+	// AccessToken 请求使用的 access_token
+	AccessToken string
+	// Agent 签发 access_token 的应用名称，默认应用为空字符串
+	Agent string
+	// Time 收到请求的时间
+	Time time.Time
+}
+
+// Decode 将请求体解析到 v
+func (r *Request) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+// Option 模拟服务选项
+type Option func(*Server)
+
+// WithTokenExpiresIn 设置签发的 access_token 有效期（秒），默认 7200
+func WithTokenExpiresIn(seconds int) Option {
+	return func(s *Server) {
+		s.expiresIn = seconds
+	}
+}
+
+// tokenInfo 已签发的 access_token
+type tokenInfo struct {
+	agent   string
+	expired bool
+}
+
+// route 注册的接口响应，按顺序使用，最后一个重复使用
+type route struct {
+	handlers []http.HandlerFunc
+	next     int
+}
+
+// Server 企业微信模拟服务
+type Server struct {
+	*httptest.Server
+
+	mu sync.Mutex
+	// secrets corpsecret 到应用名称的映射
+	secrets map[string]string
+	// tokens 已签发的 access_token
+	tokens map[string]*tokenInfo
+	// tokenCalls gettoken 调用次数
+	tokenCalls int
+	// issued 通过 IssueToken 签发的 token 数量
+	issued int
+	// expiresIn access_token 有效期（秒）
+	expiresIn int
+	// routes 注册的接口响应
+	routes map[string]*route
+	// requests 收到的请求（不包括 gettoken）
+	requests []*Request
+	// agentOptions 通过 Agent 注册的应用配置
+	agentOptions []config.Option
+}
+
+// NewServer 启动模拟服务，测试结束时自动关闭
+func NewServer(tb testing.TB, opts ...Option) *Server {
+	tb.Helper()
+
+	s := &Server{
+		secrets:   map[string]string{CorpSecret: ""},
+		tokens:    make(map[string]*tokenInfo),
+		expiresIn: 7200,
+		routes:    make(map[string]*route),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	tb.Cleanup(s.Close)
+	return s
+}
+
+// Options 返回指向模拟服务的SDK配置
+// 重试退避时间被缩短为毫秒级，便于测试重试逻辑
+func (s *Server) Options() []config.Option {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	opts := []config.Option{
+		config.WithBaseURL(s.URL),
+		config.WithCorpID(CorpID),
+		config.WithBackoff(time.Millisecond, 10*time.Millisecond),
+	}
+	if len(s.agentOptions) == 0 {
+		opts = append(opts, config.WithCorpSecret(CorpSecret))
+	}
+	return append(opts, s.agentOptions...)
+}
+
+// Agent 注册一个应用并返回对应的SDK配置
+// 该应用签发的 access_token 发起的请求会在 Request.Agent 中记录应用名称
+// 注册的应用会包含在 Options 的返回值中
+func (s *Server) Agent(name string, agentID int64) config.Option {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secret := "wecomtest-secret-" + name
+	s.secrets[secret] = name
+	opt := config.WithAgent(name, agentID, secret)
+	s.agentOptions = append(s.agentOptions, opt)
+	return opt
+}
+
+// Stub 注册接口的固定 JSON 响应
+// resp 为可序列化为 JSON 的值；未包含 errcode 时按成功响应处理
+func (s *Server) Stub(path string, resp any) *Server {
+	return s.StubSequence(path, resp)
+}
+
+// StubSequence 注册接口的一组 JSON 响应，按调用顺序依次返回，用完后重复最后一个
+// 可用于模拟 “先失败后成功” 的重试场景
+func (s *Server) StubSequence(path string, resps ...any) *Server {
+	handlers := make([]http.HandlerFunc, 0, len(resps))
+	for _, resp := range resps {
+		handlers = append(handlers, jsonHandler(resp))
+	}
+	return s.handle(path, handlers...)
+}
+
+// StubError 注册接口的错误响应
+func (s *Server) StubError(path string, errCode int, errMsg string) *Server {
+	return s.Stub(path, map[string]any{"errcode": errCode, "errmsg": errMsg})
+}
+
+// StubRaw 注册接口的原始响应，用于模拟素材下载等非 JSON 接口
+func (s *Server) StubRaw(path, contentType string, body []byte) *Server {
+	return s.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(body)
+	})
+}
+
+// StubMedia 注册支持 Range 分块请求的文件下载响应，响应携带 Content-Disposition 文件名
+// 可用于素材下载接口，或微盘、会议录制等不以 /cgi-bin/ 开头、不校验 access_token 的下载地址
+func (s *Server) StubMedia(path, filename string, content []byte) *Server {
+	modTime := time.Now()
+	return s.handle(path, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+		http.ServeContent(w, r, filename, modTime, bytes.NewReader(content))
+	})
+}
+
+// Handle 注册接口的处理函数
+// 请求体可以通过 r.Body 重新读取，access_token 已在调用前校验
+func (s *Server) Handle(path string, fn http.HandlerFunc) *Server {
+	return s.handle(path, fn)
+}
+
+// handle 注册路由
+func (s *Server) handle(path string, handlers ...http.HandlerFunc) *Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.routes[path] = &route{handlers: handlers}
+	return s
+}
+
+// ExpireTokens 使所有已签发的 access_token 失效
+// 之后使用这些 token 的请求返回 42001，用于测试 token 刷新逻辑
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, info := range s.tokens {
+		info.expired = true
+	}
+}
+
+// IssueToken 签发一个有效的 access_token，请求记录中的 Request.Agent 为 agent
+// 用于在 Handle 中模拟 corpgroup/corp/gettoken、service/get_corp_token 等返回 token 的接口
+func (s *Server) IssueToken(agent string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.issued++
+	token := fmt.Sprintf("wecomtest-issued-token-%d", s.issued)
+	s.tokens[token] = &tokenInfo{agent: agent}
+	return token
+}
+
+// TokenCalls 返回 gettoken 的调用次数
+func (s *Server) TokenCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokenCalls
+}
+
+// Requests 返回收到的全部请求（不包括 gettoken）
+func (s *Server) Requests() []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*Request(nil), s.requests...)
+}
+
+// RequestsTo 返回指定接口收到的请求
+func (s *Server) RequestsTo(path string) []*Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reqs []*Request
+	for _, r := range s.requests {
+		if r.Path == path {
+			reqs = append(reqs, r)
+		}
+	}
+	return reqs
+}
+
+// LastRequest 返回指定接口收到的最后一个请求，未收到时返回 nil
+func (s *Server) LastRequest(path string) *Request {
+	reqs := s.RequestsTo(path)
+	if len(reqs) == 0 {
+		return nil
+	}
+	return reqs[len(reqs)-1]
+}
+
+// Reset 清空请求记录并重置接口响应的调用顺序，已注册的响应保留
+func (s *Server) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+	s.tokenCalls = 0
+	for _, rt := range s.routes {
+		rt.next = 0
+	}
+}
+
+// serveHTTP 处理请求
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == tokenPath {
+		s.serveToken(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	req := &Request{
+		Method:      r.Method,
+		Path:        r.URL.Path,
+		Query:       r.URL.Query(),
+		Header:      r.Header.Clone(),
+		Body:        body,
+		AccessToken: r.URL.Query().Get("access_token"),
+		Time:        time.Now(),
+	}
+	var obj map[string]any
+	if json.Unmarshal(body, &obj) == nil {
+		req.JSON = obj
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	info, ok := s.tokens[req.AccessToken]
+	if ok {
+		req.Agent = info.agent
+	}
+	handler := s.nextHandler(req.Path)
+	s.mu.Unlock()
+
+	checkToken := strings.HasPrefix(req.Path, apiPrefix) && !strings.HasPrefix(req.Path, webhookPrefix)
+	switch {
+	case checkToken && !ok:
+		writeJSON(w, map[string]any{"errcode": ErrCodeInvalidToken, "errmsg": "invalid access_token"})
+	case checkToken && info.expired:
+		writeJSON(w, map[string]any{"errcode": ErrCodeTokenExpired, "errmsg": "access_token expired"})
+	case handler == nil:
+		writeJSON(w, map[string]any{"errcode": ErrCodeNoStub, "errmsg": "wecomtest: no stub registered for " + req.Path})
+	default:
+		handler(w, r)
+	}
+}
+
+// nextHandler 取出接口的下一个处理函数，调用方需持有锁
+func (s *Server) nextHandler(path string) http.HandlerFunc {
+	rt, ok := s.routes[path]
+	if !ok || len(rt.handlers) == 0 {
+		return nil
+	}
+	h := rt.handlers[min(rt.next, len(rt.handlers)-1)]
+	rt.next++
+	return h
+}
+
+// serveToken 模拟 gettoken 接口，每次调用签发新的 access_token
+func (s *Server) serveToken(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokenCalls++
+	agent, ok := s.secrets[q.Get("corpsecret")]
+	if q.Get("corpid") != CorpID || !ok {
+		writeJSON(w, map[string]any{"errcode": ErrCodeInvalidSecret, "errmsg": "invalid credential"})
+		return
+	}
+
+	token := fmt.Sprintf("wecomtest-token-%d", s.tokenCalls)
+	s.tokens[token] = &tokenInfo{agent: agent}
+	writeJSON(w, map[string]any{
+		"errcode":      0,
+		"errmsg":       "ok",
+		"access_token": token,
+		"expires_in":   s.expiresIn,
+	})
+}
+
+// jsonHandler 返回固定 JSON 响应的处理函数
+func jsonHandler(resp any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, resp)
+	}
+}
+
+// writeJSON 写入 JSON 响应
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
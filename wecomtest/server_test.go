@@ -0,0 +1,152 @@
+package wecomtest_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/shuaidd/wecom-core"
+	"github.com/shuaidd/wecom-core/config"
+	"github.com/shuaidd/wecom-core/types/message"
+	"github.com/shuaidd/wecom-core/wecomtest"
+)
+
+func TestServer_StubAndRecord(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"errcode": 0, "userid": "zhangsan", "name": "张三"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	user, err := client.Contact.GetUser(context.Background(), "zhangsan")
+	require.NoError(t, err)
+	assert.Equal(t, "张三", user.Name)
+
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 1)
+	srv.AssertQuery(t, "/cgi-bin/user/get", "userid", "zhangsan")
+	srv.AssertNotCalled(t, "/cgi-bin/user/create")
+	assert.Equal(t, 1, srv.TokenCalls())
+}
+
+func TestServer_AssertBodyAndAgent(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/message/send", map[string]any{"errcode": 0, "msgid": "msg-1"})
+
+	client, err := wecom.New(append(srv.Options(), srv.Agent("notify", 1000002))...)
+	require.NoError(t, err)
+
+	ctx := wecom.WithAgentName(context.Background(), "notify")
+	resp, err := client.Message.Send(ctx, &message.SendMessageRequest{
+		ToUser:  "zhangsan",
+		MsgType: message.MessageTypeText,
+		AgentID: 1000002,
+		Text:    &message.TextMessage{Content: "hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "msg-1", resp.MsgID)
+
+	srv.AssertAgent(t, "/cgi-bin/message/send", "notify")
+	srv.AssertBody(t, "/cgi-bin/message/send", `{
+		"touser": "zhangsan",
+		"msgtype": "text",
+		"agentid": 1000002,
+		"text": {"content": "hello"}
+	}`)
+
+	var req message.SendMessageRequest
+	require.NoError(t, srv.LastRequest("/cgi-bin/message/send").Decode(&req))
+	assert.Equal(t, "hello", req.Text.Content)
+}
+
+func TestServer_TokenRefresh(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+
+	// token 失效后自动刷新并重试
+	srv.ExpireTokens()
+	_, err = client.Contact.GetUser(ctx, "zhangsan")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, srv.TokenCalls())
+	reqs := srv.RequestsTo("/cgi-bin/user/get")
+	require.Len(t, reqs, 3)
+	assert.Equal(t, reqs[0].AccessToken, reqs[1].AccessToken)
+	assert.NotEqual(t, reqs[1].AccessToken, reqs[2].AccessToken)
+}
+
+func TestServer_Retry(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.StubSequence("/cgi-bin/user/get",
+		map[string]any{"errcode": 45009, "errmsg": "api freq out of limit"},
+		map[string]any{"errcode": 10001, "errmsg": "system busy"},
+		map[string]any{"errcode": 0, "userid": "zhangsan"},
+	)
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	user, err := client.Contact.GetUser(context.Background(), "zhangsan")
+	require.NoError(t, err)
+	assert.Equal(t, "zhangsan", user.UserID)
+	srv.AssertCalledTimes(t, "/cgi-bin/user/get", 3)
+}
+
+func TestServer_Interceptor(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Stub("/cgi-bin/user/get", map[string]any{"userid": "zhangsan"})
+
+	var calls int32
+	opts := append(srv.Options(), config.WithRequestInterceptor(func(ctx context.Context, req *http.Request, body any) error {
+		atomic.AddInt32(&calls, 1)
+		req.Header.Set("X-Test", "wecomtest")
+		return nil
+	}))
+	client, err := wecom.New(opts...)
+	require.NoError(t, err)
+
+	_, err = client.Contact.GetUser(context.Background(), "zhangsan")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	assert.Equal(t, "wecomtest", srv.LastRequest("/cgi-bin/user/get").Header.Get("X-Test"))
+}
+
+func TestServer_NoStub(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	_, err = client.Contact.GetUser(context.Background(), "zhangsan")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no stub registered")
+}
+
+func TestServer_Handle(t *testing.T) {
+	srv := wecomtest.NewServer(t)
+	srv.Handle("/cgi-bin/user/get", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"errcode":0,"userid":"` + r.URL.Query().Get("userid") + `"}`))
+	})
+
+	client, err := wecom.New(srv.Options()...)
+	require.NoError(t, err)
+
+	user, err := client.Contact.GetUser(context.Background(), "lisi")
+	require.NoError(t, err)
+	assert.Equal(t, "lisi", user.UserID)
+
+	srv.Reset()
+	assert.Empty(t, srv.Requests())
+}